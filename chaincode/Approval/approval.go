@@ -0,0 +1,175 @@
+// Package approval holds the pending requests of the maker-checker workflow.
+//
+// A maker submits the arguments of a function (a loan sanction, a
+// transaction) as a pending request instead of calling it. A checker, who
+// must be a different identity from the maker, then approves the request,
+// upon which the chaincode runs the function with the stored arguments, or
+// rejects it with a reason. Requests are kept after the decision so that
+// the maker, the checker and the outcome can be audited.
+package approval
+
+import (
+	"encoding/json"
+	"time"
+
+	clock "github.com/chaincode/Clock"
+	errcode "github.com/chaincode/Errcode"
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// requestIndex is the composite key prefix of the requests, keyed by the
+// function they are for and the request ID
+const requestIndex = "PendingRequest~Function~RequestID"
+
+// Request statuses
+const (
+	Pending  = "pending"
+	Approved = "approved"
+	Rejected = "rejected"
+)
+
+// Request is a call waiting for, or decided by, a checker
+type Request struct {
+	RequestID   string    `json:"RequestID"`
+	Function    string    `json:"Function"`
+	Args        []string  `json:"Args"`
+	Status      string    `json:"Status"`
+	Maker       string    `json:"Maker"`
+	MakerMSP    string    `json:"MakerMSP"`
+	SubmittedAt time.Time `json:"SubmittedAt"`
+	Checker     string    `json:"Checker,omitempty"`
+	CheckerMSP  string    `json:"CheckerMSP,omitempty"`
+	DecidedAt   time.Time `json:"DecidedAt,omitempty"`
+	Reason      string    `json:"Reason,omitempty"`
+	Result      string    `json:"Result,omitempty"` // what the function returned on approval
+}
+
+// Submit stores a pending request for the function with the arguments. The
+// request ID is the ID of the submitting transaction.
+func Submit(stub shim.ChaincodeStubInterface, function string, args []string) (Request, error) {
+	req := Request{RequestID: stub.GetTxID(), Function: function, Args: args, Status: Pending}
+	var err error
+	req.MakerMSP, req.Maker, err = identity(stub)
+	if err != nil {
+		return req, err
+	}
+	req.SubmittedAt, err = clock.Now(stub)
+	if err != nil {
+		return req, err
+	}
+	return req, Put(stub, req)
+}
+
+// Get returns the request for the function
+func Get(stub shim.ChaincodeStubInterface, function string, requestID string) (Request, error) {
+	req := Request{}
+	key, err := stub.CreateCompositeKey(requestIndex, []string{function, requestID})
+	if err != nil {
+		return req, err
+	}
+	reqBytes, err := stub.GetState(key)
+	if err != nil {
+		return req, err
+	} else if reqBytes == nil {
+		return req, errcode.New(errcode.NotFound, "no "+function+" request "+requestID)
+	}
+	err = json.Unmarshal(reqBytes, &req)
+	return req, err
+}
+
+// Put writes the request to the ledger
+func Put(stub shim.ChaincodeStubInterface, req Request) error {
+	key, err := stub.CreateCompositeKey(requestIndex, []string{req.Function, req.RequestID})
+	if err != nil {
+		return err
+	}
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, reqBytes)
+}
+
+// Approve marks the pending request approved by the client of the
+// transaction. The request is returned without being written, so that the
+// caller can run the function and store its Result with Put.
+func Approve(stub shim.ChaincodeStubInterface, function string, requestID string) (Request, error) {
+	return decide(stub, function, requestID, Approved, "")
+}
+
+// Reject marks the pending request rejected by the client of the
+// transaction and writes it
+func Reject(stub shim.ChaincodeStubInterface, function string, requestID string, reason string) (Request, error) {
+	if reason == "" {
+		return Request{}, errcode.New(errcode.InvalidArgument, "a reason is required to reject "+requestID)
+	}
+	req, err := decide(stub, function, requestID, Rejected, reason)
+	if err != nil {
+		return req, err
+	}
+	return req, Put(stub, req)
+}
+
+// List returns the requests for the function, only those with the status
+// unless it is empty
+func List(stub shim.ChaincodeStubInterface, function string, status string) ([]Request, error) {
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(requestIndex, []string{function})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	reqs := []Request{}
+	for resultsIterator.HasNext() {
+		kv, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		req := Request{}
+		err = json.Unmarshal(kv.Value, &req)
+		if err != nil {
+			return nil, err
+		}
+		if status == "" || req.Status == status {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs, nil
+}
+
+func decide(stub shim.ChaincodeStubInterface, function string, requestID string, status string, reason string) (Request, error) {
+	req, err := Get(stub, function, requestID)
+	if err != nil {
+		return req, err
+	}
+	if req.Status != Pending {
+		return req, errcode.New(errcode.InvalidState, "request "+requestID+" is already "+req.Status)
+	}
+	mspID, id, err := identity(stub)
+	if err != nil {
+		return req, err
+	}
+	if mspID == req.MakerMSP && id == req.Maker {
+		return req, errcode.New(errcode.Unauthorized, "request "+requestID+" cannot be "+status+" by its maker")
+	}
+	req.DecidedAt, err = clock.Now(stub)
+	if err != nil {
+		return req, err
+	}
+	req.Status, req.CheckerMSP, req.Checker, req.Reason = status, mspID, id, reason
+	return req, nil
+}
+
+// identity returns the MSP ID and the unique ID of the client's certificate
+func identity(stub shim.ChaincodeStubInterface) (string, string, error) {
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return "", "", err
+	}
+	id, err := cid.GetID(stub)
+	if err != nil {
+		return "", "", err
+	}
+	return mspID, id, nil
+}
@@ -0,0 +1,156 @@
+// Package auth decides who may call each function of a chaincode, from the
+// client identity of the transaction (its MSP ID and the "role" attribute of
+// its certificate) or from the chaincode the transaction was sent to.
+//
+// Every chaincode keeps a Policy with a Rule per function and checks it at
+// the top of Invoke. A function without a rule cannot be called.
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	cb "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// RoleAttr is the certificate attribute holding the caller's roles. A user
+// with more than one role has them comma separated, e.g. "maker,ops".
+const RoleAttr = "role"
+
+// Roles
+const (
+	Maker     = "maker"
+	Checker   = "checker"
+	Ops       = "ops"
+	BankAdmin = "bank-admin"
+	Auditor   = "auditor"
+	// Anyone lets any member of the channel call the function
+	Anyone = "*"
+)
+
+// Rule says who may call a function. With Callers set the function can only
+// be reached through one of those chaincodes, which have checked the client
+// themselves; otherwise the client needs one of Roles.
+type Rule struct {
+	Roles   []string
+	Callers []string
+}
+
+// Policy holds the rule of every function of a chaincode
+type Policy map[string]Rule
+
+// Roles returns a rule for clients with any of the roles
+func Roles(roles ...string) Rule {
+	return Rule{Roles: roles}
+}
+
+// Callers returns a rule for functions that only the given chaincodes may
+// call on behalf of their clients. The chaincodes are given by their default
+// names and checked under the names of the common.Config.
+func Callers(chaincodes ...string) Rule {
+	return Rule{Callers: chaincodes}
+}
+
+// Check returns an errcode.Unauthorized error unless the client of the
+// transaction may call the function
+func (p Policy) Check(stub shim.ChaincodeStubInterface, function string) error {
+	rule, ok := p[function]
+	if !ok {
+		return errcode.New(errcode.Unauthorized, "access denied: "+function+" has no access rule")
+	}
+
+	if len(rule.Callers) > 0 {
+		invoked, err := InvokedChaincode(stub)
+		if err != nil {
+			return errcode.New(errcode.Unauthorized, "access denied: "+err.Error())
+		}
+		cfg, err := common.LoadConfig(stub)
+		if err != nil {
+			return errcode.New(errcode.Unauthorized, "access denied: "+err.Error())
+		}
+		callers := []string{}
+		for _, caller := range rule.Callers {
+			callers = append(callers, cfg.Name(caller))
+		}
+		if !contains(callers, invoked) {
+			return errcode.New(errcode.Unauthorized, "access denied: "+function+" can only be called through "+strings.Join(callers, ", ")+", not "+invoked)
+		}
+		return nil
+	}
+
+	if contains(rule.Roles, Anyone) {
+		return nil
+	}
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return errcode.New(errcode.Unauthorized, "access denied: "+err.Error())
+	}
+	roles, _, err := cid.GetAttributeValue(stub, RoleAttr)
+	if err != nil {
+		return errcode.New(errcode.Unauthorized, "access denied: "+err.Error())
+	}
+	for _, role := range strings.Split(roles, ",") {
+		if contains(rule.Roles, strings.TrimSpace(role)) {
+			return nil
+		}
+	}
+	return errcode.New(errcode.Unauthorized, "access denied: "+function+" needs role "+strings.Join(rule.Roles, " or ")+", "+mspID+" client has role "+roleList(roles))
+}
+
+// InvokedChaincode returns the name of the chaincode the client sent the
+// transaction to. Chaincodes called from it see the same proposal, so this
+// is how a chaincode tells that it is being called through another one.
+func InvokedChaincode(stub shim.ChaincodeStubInterface) (string, error) {
+	signedProp, err := stub.GetSignedProposal()
+	if err != nil {
+		return "", err
+	} else if signedProp == nil {
+		return "", errors.New("no signed proposal")
+	}
+	prop := &pb.Proposal{}
+	err = proto.Unmarshal(signedProp.ProposalBytes, prop)
+	if err != nil {
+		return "", err
+	}
+	hdr := &cb.Header{}
+	err = proto.Unmarshal(prop.Header, hdr)
+	if err != nil {
+		return "", err
+	}
+	chdr := &cb.ChannelHeader{}
+	err = proto.Unmarshal(hdr.ChannelHeader, chdr)
+	if err != nil {
+		return "", err
+	}
+	ext := &pb.ChaincodeHeaderExtension{}
+	err = proto.Unmarshal(chdr.Extension, ext)
+	if err != nil {
+		return "", err
+	}
+	if ext.ChaincodeId == nil {
+		return "", errors.New("proposal names no chaincode")
+	}
+	return ext.ChaincodeId.Name, nil
+}
+
+func roleList(roles string) string {
+	if roles == "" {
+		return "(none)"
+	}
+	return roles
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
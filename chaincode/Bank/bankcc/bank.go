@@ -1,4 +1,4 @@
-package main
+package bankcc
 
 import (
 	"crypto/sha256"
@@ -6,12 +6,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
+	auth "github.com/chaincode/Auth"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	money "github.com/chaincode/Money"
+	schema "github.com/chaincode/Schema"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
-type chainCode struct {
+// Chaincode is the bankcc chaincode
+type Chaincode struct {
 }
 
 type bankInfo struct {
@@ -23,32 +30,63 @@ type bankInfo struct {
 	BankChargesWalletID   string `json:"ChargesWallet"`   //will take the values for the respective wallet from the user
 	BankLiabilityWalletID string `json:"LiabilityWallet"` //will take the values for the respective wallet from the user
 	TDSreceivableWalletID string `json:"TDSWallet"`       //will take the values for the respective wallet from the user
+	Currency              string `json:"Currency"`        //currency of all the bank's wallets
 }
 
-// toChaincodeArgs returns byte arrau of string of arguments, so it can be passed to other chaincodes
-func toChaincodeArgs(args ...string) [][]byte {
-	bargs := make([][]byte, len(args))
-	for i, arg := range args {
-		bargs[i] = []byte(arg)
-	}
-	return bargs
-}
-
-func (c *chainCode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
 
 	bank := bankInfo{}
 	indexName := "Bankcode~BankBranch"
 	codeBranchKey, err := stub.CreateCompositeKey(indexName, []string{bank.Bankcode, bank.BankBranch})
 	if err != nil {
-		return shim.Error("Unable to create composite key Bankcode~BankBranch in bankcc")
+		return errcode.Error("bankcc", errcode.Internal, "Unable to create composite key Bankcode~BankBranch in bankcc")
 	}
 	value := []byte{0x00}
 	stub.PutState(codeBranchKey, value)
+	// the channel and the names of the other chaincodes, if instantiated with them
+	err = common.InitConfig(stub)
+	if err != nil {
+		return errcode.Error("bankcc", errcode.InvalidArgument, err.Error())
+	}
 	return shim.Success(nil)
 }
 
-func (c *chainCode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+// accessPolicy says who may call each function of bankcc
+var accessPolicy = auth.Policy{
+	"writeBankInfo": auth.Roles(auth.BankAdmin),
+	"getBankInfo":   auth.Roles(auth.Anyone),
+	"getWalletID":   auth.Roles(auth.Anyone),
+	"bankIDexists":  auth.Roles(auth.Anyone),
+	"getConfig":     auth.Roles(auth.Anyone),
+}
+
+// argSchemas names the arguments of the functions of bankcc that take a JSON
+// object
+var argSchemas = schema.Schemas{
+	"writeBankInfo": {
+		{Name: "bankID", Kind: schema.Text},
+		{Name: "bankName", Kind: schema.Text},
+		{Name: "bankBranch", Kind: schema.Text},
+		{Name: "bankCode", Kind: schema.Text},
+		{Name: "mainWalletAmt", Kind: schema.Number},
+		{Name: "assetWalletAmt", Kind: schema.Number},
+		{Name: "chargesWalletAmt", Kind: schema.Number},
+		{Name: "liabilityWalletAmt", Kind: schema.Number},
+		{Name: "tdsWalletAmt", Kind: schema.Number},
+		{Name: "currency", Kind: schema.Text, Optional: true},
+	},
+}
+
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	function, args := stub.GetFunctionAndParameters()
+	err := accessPolicy.Check(stub, function)
+	if err != nil {
+		return errcode.Error("bankcc", errcode.Unauthorized, err.Error())
+	}
+	args, err = argSchemas.Parse(function, args)
+	if err != nil {
+		return errcode.Error("bankcc", errcode.InvalidArgument, err.Error())
+	}
 
 	if function == "writeBankInfo" {
 		//Creates a new Bank Information
@@ -62,8 +100,15 @@ func (c *chainCode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	} else if function == "bankIDexists" {
 		//To check the BankId existence
 		return bankIDexists(stub, args[0])
+	} else if function == "getConfig" {
+		//Returns the channel and the chaincode names it was instantiated with
+		cfgBytes, err := common.GetConfig(stub)
+		if err != nil {
+			return errcode.Error("bankcc", errcode.Internal, err.Error())
+		}
+		return shim.Success(cfgBytes)
 	}
-	return shim.Error("No function named " + function + " in Banksssssssss")
+	return errcode.Error("bankcc", errcode.InvalidArgument, "No function named "+function+" in Banksssssssss")
 
 }
 
@@ -71,22 +116,32 @@ func (c *chainCode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 func writeBankInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 
 	//Checking argument length
-	if len(args) != 9 {
+	if len(args) != 9 && len(args) != 10 {
 		xLenStr := strconv.Itoa(len(args)) //needed?!
-		return shim.Error("Invalid number of arguments in writeBankInfo (required:9) given:" + xLenStr)
+		return errcode.Error("bankcc", errcode.InvalidArgument, "Invalid number of arguments in writeBankInfo (required:9 or 10) given:"+xLenStr)
+	}
+
+	//args[9] -> currency of the bank's wallets, INR when not given
+	currency := money.DefaultCurrency
+	if len(args) == 10 && args[9] != "" {
+		currency = strings.ToUpper(args[9])
+	}
+	_, err := money.Exponent(currency)
+	if err != nil {
+		return errcode.Error("bankcc", errcode.InvalidArgument, err.Error())
 	}
 
 	//Checking Bank ID existence
 	response := bankIDexists(stub, args[0])
 	if response.Status != shim.OK {
-		return shim.Error("bankcc: " + response.Message)
+		return errcode.Error("bankcc", errcode.AlreadyExists, response.Message)
 	}
 
 	//Checking existence of Bank code (!*!)
 	codeBranchIterator, err := stub.GetStateByPartialCompositeKey("Bankcode~BankBranch", []string{args[3]})
 	codeBranchData, err := codeBranchIterator.Next()
 	if codeBranchData != nil {
-		return shim.Error("bankcc : " + "Bank code already exist: " + args[3])
+		return errcode.Error("bankcc", errcode.AlreadyExists, "Bank code already exist: "+args[3])
 	}
 	defer codeBranchIterator.Close()
 
@@ -99,46 +154,46 @@ func writeBankInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response
 	hash.Write([]byte(BankWalletStr))
 	md := hash.Sum(nil)
 	BankWalletIDsha := hex.EncodeToString(md)
-	createWallet(stub, BankWalletIDsha, "1000")
+	createWallet(stub, BankWalletIDsha, "1000", currency)
 
 	// Hashing bankAssetWalletId
 	BankAssetWalletStr := args[3] + "BankAssetWallet"
 	hash.Write([]byte(BankAssetWalletStr))
 	md = hash.Sum(nil)
 	BankAssetWalletIDsha := hex.EncodeToString(md)
-	createWallet(stub, BankAssetWalletIDsha, "1000")
+	createWallet(stub, BankAssetWalletIDsha, "1000", currency)
 
 	// Hashing BankChargesWalletID
 	BankChargesWalletStr := args[3] + "BankChargesWallet"
 	hash.Write([]byte(BankChargesWalletStr))
 	md = hash.Sum(nil)
 	BankChargesWalletIDsha := hex.EncodeToString(md)
-	createWallet(stub, BankChargesWalletIDsha, "1000")
+	createWallet(stub, BankChargesWalletIDsha, "1000", currency)
 
 	// Hashing BankLiabilityWalletID
 	BankLiabilityWalletStr := args[3] + "BankLiabilityWallet"
 	hash.Write([]byte(BankLiabilityWalletStr))
 	md = hash.Sum(nil)
 	BankLiabilityWalletIDsha := hex.EncodeToString(md)
-	createWallet(stub, BankLiabilityWalletIDsha, "1000")
+	createWallet(stub, BankLiabilityWalletIDsha, "1000", currency)
 
 	// Hashing TDSreceivableWalletID
 	TDSreceivableWalletStr := args[3] + "TDSreceivableWallet"
 	hash.Write([]byte(TDSreceivableWalletStr))
 	md = hash.Sum(nil)
 	TDSreceivableWalletIDsha := hex.EncodeToString(md)
-	createWallet(stub, TDSreceivableWalletIDsha, "1000")
+	createWallet(stub, TDSreceivableWalletIDsha, "1000", currency)
 
 	//args[0] -> bankID | creating a bank struct obj and writing it to the ledger
-	bank := bankInfo{args[1], args[2], args[3], BankWalletIDsha, BankAssetWalletIDsha, BankChargesWalletIDsha, BankLiabilityWalletIDsha, TDSreceivableWalletIDsha}
+	bank := bankInfo{args[1], args[2], args[3], BankWalletIDsha, BankAssetWalletIDsha, BankChargesWalletIDsha, BankLiabilityWalletIDsha, TDSreceivableWalletIDsha, currency}
 	bankBytes, err := json.Marshal(bank)
 	if err != nil {
-		return shim.Error("Unable to Marshal the json file " + err.Error())
+		return errcode.Error("bankcc", errcode.Internal, "Unable to Marshal the json file "+err.Error())
 	}
 
 	err = stub.PutState(args[0], bankBytes)
 	if err != nil {
-		return shim.Error("bankcc : " + err.Error())
+		return errcode.Error("bankcc", errcode.Internal, err.Error())
 	}
 
 	return shim.Success([]byte("Succefully written into the ledger"))
@@ -148,17 +203,16 @@ func bankIDexists(stub shim.ChaincodeStubInterface, bankID string) pb.Response {
 	ifExists, _ := stub.GetState(bankID)
 	if ifExists != nil {
 		fmt.Println(ifExists) //needed!?
-		return shim.Error("bankcc : " + "BankId " + bankID + " exits. Cannot create new ID")
+		return errcode.Error("bankcc", errcode.AlreadyExists, "BankId "+bankID+" exits. Cannot create new ID")
 	}
 	return shim.Success(nil)
 }
 
-func createWallet(stub shim.ChaincodeStubInterface, walletID string, amt string) pb.Response {
+func createWallet(stub shim.ChaincodeStubInterface, walletID string, amt string, currency string) pb.Response {
 	//Calling wallet Chaincode to create new wallet
-	chaincodeArgs := toChaincodeArgs("newWallet", walletID, amt)
-	response := stub.InvokeChaincode("walletcc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("bankcc : " + "Unable to create new wallet from bank")
+	err := common.NewWalletClient(stub).NewWallet(walletID, amt, currency)
+	if err != nil {
+		return errcode.Error("bankcc", errcode.Internal, "Unable to create new wallet from bank")
 	}
 	return shim.Success([]byte("created new wallet from bank"))
 }
@@ -167,24 +221,24 @@ func getBankInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 
 	if len(args) != 1 {
 		xLenStr := strconv.Itoa(len(args))
-		return shim.Error("bankcc : " + "Invalid number of arguments in getBankInfo (required:1) given:" + xLenStr)
+		return errcode.Error("bankcc", errcode.InvalidArgument, "Invalid number of arguments in getBankInfo (required:1) given:"+xLenStr)
 	}
 
 	// getting bank info from the ledger, args[0] -> bankID
 	bankInfoBytes, err := stub.GetState(args[0])
 
 	if err != nil {
-		return shim.Error("bankcc : " + "Unable to fetch the state" + err.Error())
+		return errcode.Error("bankcc", errcode.Internal, "Unable to fetch the state"+err.Error())
 	}
 	if bankInfoBytes == nil {
-		return shim.Error("bankcc : " + "Data does not exist for " + args[0])
+		return errcode.Error("bankcc", errcode.NotFound, "Data does not exist for "+args[0])
 	}
 
 	// unmarshalling bankInfoBytes into bank structure
 	bank := bankInfo{}
 	err = json.Unmarshal(bankInfoBytes, &bank)
 	if err != nil {
-		return shim.Error("bankcc : " + "Uable to paser into the json format")
+		return errcode.Error("bankcc", errcode.Internal, "Uable to paser into the json format")
 	}
 	x := fmt.Sprintf("%+v", bank)
 	fmt.Printf("BankInfo : %s\n", x)
@@ -194,19 +248,19 @@ func getBankInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 func getWalletID(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	if len(args) != 2 {
 		xLenStr := strconv.Itoa(len(args))
-		return shim.Error("bankcc : " + "Invalid number of arguments in getWalletID(bank) (required:2) given:" + xLenStr)
+		return errcode.Error("bankcc", errcode.InvalidArgument, "Invalid number of arguments in getWalletID(bank) (required:2) given:"+xLenStr)
 	}
 	bankInfoBytes, err := stub.GetState(args[0])
 	if err != nil {
-		return shim.Error("bankcc : " + "Unable to fetch the state" + err.Error())
+		return errcode.Error("bankcc", errcode.Internal, "Unable to fetch the state"+err.Error())
 	}
 	if bankInfoBytes == nil {
-		return shim.Error("bankcc : " + "Data does not exist for " + args[0])
+		return errcode.Error("bankcc", errcode.NotFound, "Data does not exist for "+args[0])
 	}
 	bank := bankInfo{}
 	err = json.Unmarshal(bankInfoBytes, &bank)
 	if err != nil {
-		return shim.Error("bankcc : " + "Uable to paser into the json format")
+		return errcode.Error("bankcc", errcode.Internal, "Uable to paser into the json format")
 	}
 
 	walletID := ""
@@ -226,11 +280,3 @@ func getWalletID(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 
 	return shim.Success([]byte(walletID))
 }
-
-func main() {
-	err := shim.Start(new(chainCode))
-	if err != nil {
-		fmt.Printf("bankcc : "+"Error starting Bank chaincode: %s\n", err)
-	}
-
-}
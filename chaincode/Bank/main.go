@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+
+	bankcc "github.com/chaincode/Bank/bankcc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() {
+	err := shim.Start(new(bankcc.Chaincode))
+	if err != nil {
+		fmt.Printf("bankcc : "+"Error starting Bank chaincode: %s\n", err)
+	}
+
+}
@@ -0,0 +1,376 @@
+package businesscc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	auth "github.com/chaincode/Auth"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	history "github.com/chaincode/History"
+	money "github.com/chaincode/Money"
+	schema "github.com/chaincode/Schema"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Chaincode is the businesscc chaincode
+type Chaincode struct {
+}
+
+type businessInfo struct {
+	BusinessName                         string `json:"Name"`
+	BusinessAcNo                         string `json:"AcNo"`
+	BusinessLimit                        int64  `json:"Limit"`
+	BusinessWalletID                     string `json:"MainWallet"`      //will take the values for the respective wallet from the user
+	BusinessLoanWalletID                 string `json:"LoanWallet"`      //will take the values for the respective wallet from the user
+	BusinessLiabilityWalletID            string `json:"LiabilityWallet"` //will take the values for the respective wallet from the user
+	MaxROI                               int64  `json:"MaxROI"`
+	MinROI                               int64  `json:"MinROI"`
+	BusinessPrincipalOutstandingWalletID string `json:"POsWallet"` //will take the values for the respective wallet from the user
+	BusinessChargesOutstandingWalletID   string `json:"COsWallet"` //will take the values for the respective wallet from the user
+	Currency                             string `json:"Currency"`  //currency of all the business's wallets
+}
+
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	bis := businessInfo{}
+	indexName := "BusinessAcNo~BusinessName"
+	acntNoNameKey, err := stub.CreateCompositeKey(indexName, []string{bis.BusinessAcNo, bis.BusinessName})
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, "Unable to create composite key BusinessAcNo~BusinessName in businesscc")
+	}
+	value := []byte{0x00}
+	stub.PutState(acntNoNameKey, value)
+	// the channel and the names of the other chaincodes, if instantiated with them
+	err = common.InitConfig(stub)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.InvalidArgument, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// accessPolicy says who may call each function of businesscc
+var accessPolicy = auth.Policy{
+	"putNewBusinessInfo": auth.Roles(auth.Maker, auth.Ops),
+	"getBusinessInfo":    auth.Roles(auth.Anyone),
+	"getWalletID":        auth.Roles(auth.Anyone),
+	"bisIDexists":        auth.Roles(auth.Anyone),
+	"updateBusinessInfo": auth.Roles(auth.BankAdmin),
+	"getBusinessHistory": auth.Roles(auth.Auditor, auth.Ops, auth.BankAdmin),
+	"getConfig":          auth.Roles(auth.Anyone),
+}
+
+// argSchemas names the arguments of the functions of businesscc that take a JSON
+// object
+var argSchemas = schema.Schemas{
+	"putNewBusinessInfo": {
+		{Name: "businessID", Kind: schema.Text},
+		{Name: "name", Kind: schema.Text},
+		{Name: "acNo", Kind: schema.Text},
+		{Name: "limit", Kind: schema.Int},
+		{Name: "mainWalletAmt", Kind: schema.Number},
+		{Name: "loanWalletAmt", Kind: schema.Number},
+		{Name: "liabilityWalletAmt", Kind: schema.Number},
+		{Name: "maxROI", Kind: schema.Int},
+		{Name: "minROI", Kind: schema.Int},
+		{Name: "principalOutWalletAmt", Kind: schema.Number},
+		{Name: "chargesOutWalletAmt", Kind: schema.Number},
+		{Name: "currency", Kind: schema.Text, Optional: true},
+	},
+	"updateBusinessInfo": {
+		{Name: "businessID", Kind: schema.Text},
+		{Name: "field", Kind: schema.Text},
+		{Name: "value", Kind: schema.Int},
+	},
+}
+
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	err := accessPolicy.Check(stub, function)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Unauthorized, err.Error())
+	}
+	args, err = argSchemas.Parse(function, args)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.InvalidArgument, err.Error())
+	}
+
+	if function == "putNewBusinessInfo" {
+		//Creates a new Business Information
+		return putNewBusinessInfo(stub, args)
+	} else if function == "getBusinessInfo" {
+		//Retrieves the Business information
+		return getBusinessInfo(stub, args)
+	} else if function == "getWalletID" {
+		//Returns the walletID for the required wallet type
+		return getWalletID(stub, args)
+	} else if function == "bisIDexists" {
+		//To check the BusinessId existence
+		return bisIDexists(stub, args[0])
+	} else if function == "updateBusinessInfo" {
+		//Updates Business Limit / MAX ROI / MAX ROI if required
+		return updateBusinessInfo(stub, args)
+	} else if function == "getBusinessHistory" {
+		//Returns every version of the business, a page at a time
+		return getBusinessHistory(stub, args)
+	} else if function == "getConfig" {
+		//Returns the channel and the chaincode names it was instantiated with
+		cfgBytes, err := common.GetConfig(stub)
+		if err != nil {
+			return errcode.Error("businesscc", errcode.Internal, err.Error())
+		}
+		return shim.Success(cfgBytes)
+	}
+	return errcode.Error("businesscc", errcode.InvalidArgument, "No function named "+function+" in Businessssssss")
+}
+
+func putNewBusinessInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	if len(args) != 11 && len(args) != 12 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("businesscc", errcode.InvalidArgument, "Invalid number of arguments in putNewBusinessInfo (required:11 or 12) given:"+xLenStr)
+
+	}
+
+	//args[11] -> currency of the business's wallets, INR when not given
+	currency := money.DefaultCurrency
+	if len(args) == 12 && args[11] != "" {
+		currency = strings.ToUpper(args[11])
+	}
+	_, err := money.Exponent(currency)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.InvalidArgument, err.Error())
+	}
+
+	response := bisIDexists(stub, args[0])
+	if response.Status != shim.OK {
+		return errcode.Error("businesscc", errcode.AlreadyExists, response.Message)
+	}
+
+	businessLimitConv, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.InvalidArgument, err.Error())
+	}
+	if businessLimitConv <= 0 {
+		return errcode.Error("businesscc", errcode.InvalidArgument, "Invalid Business Limit value: "+args[3])
+	}
+
+	hash := sha256.New()
+
+	// Hashing BusinessWalletID
+	BusinessWalletStr := args[2] + "BusinessWallet"
+	hash.Write([]byte(BusinessWalletStr))
+	md := hash.Sum(nil)
+	BusinessWalletIDsha := hex.EncodeToString(md)
+	createWallet(stub, BusinessWalletIDsha, args[4], currency)
+
+	// Hashing BusinessLoanWalletID
+	BusinessLoanWalletStr := args[2] + "BusinessLoanWallet"
+	hash.Write([]byte(BusinessLoanWalletStr))
+	md = hash.Sum(nil)
+	BusinessLoanWalletIDsha := hex.EncodeToString(md)
+	createWallet(stub, BusinessLoanWalletIDsha, args[5], currency)
+
+	// Hashing BusinessLiabilityWalletID
+	BusinessLiabilityWalletStr := args[2] + "BusinessLiabilityWallet"
+	hash.Write([]byte(BusinessLiabilityWalletStr))
+	md = hash.Sum(nil)
+	BusinessLiabilityWalletIDsha := hex.EncodeToString(md)
+	createWallet(stub, BusinessLiabilityWalletIDsha, args[6], currency)
+
+	maxROIconvertion, err := strconv.ParseInt(args[7], 10, 64)
+	if err != nil {
+		fmt.Printf("Invalid Maximum ROI: %s\n", args[7])
+		return errcode.Error("businesscc", errcode.InvalidArgument, err.Error())
+	}
+	if maxROIconvertion <= 0 {
+		return errcode.Error("businesscc", errcode.InvalidArgument, "Invalid Max ROI value: "+args[7])
+	}
+
+	minROIconvertion, err := strconv.ParseInt(args[8], 10, 64)
+	if err != nil {
+		fmt.Printf("Invalid Minimum ROI: %s\n", args[8])
+		return errcode.Error("businesscc", errcode.InvalidArgument, err.Error())
+	}
+	if minROIconvertion <= 0 {
+		return errcode.Error("businesscc", errcode.InvalidArgument, "Invalid Min ROI value: "+args[8])
+	}
+
+	// Hashing BusinessPrincipalOutstandingWalletID
+	BusinessPrincipalOutstandingWalletStr := args[2] + "BusinessPrincipalOutstandingWallet"
+	hash.Write([]byte(BusinessPrincipalOutstandingWalletStr))
+	md = hash.Sum(nil)
+	BusinessPrincipalOutstandingWalletIDsha := hex.EncodeToString(md)
+	createWallet(stub, BusinessPrincipalOutstandingWalletIDsha, args[9], currency)
+
+	// Hashing BusinessChargesOutstandingWalletID
+	BusinessInterestOutstandingWalletStr := args[2] + "BusinessInterestOutstandingWallet"
+	hash.Write([]byte(BusinessInterestOutstandingWalletStr))
+	md = hash.Sum(nil)
+	BusinessChargesOutstandingWalletIDsha := hex.EncodeToString(md)
+	createWallet(stub, BusinessChargesOutstandingWalletIDsha, args[10], currency)
+
+	newInfo := &businessInfo{args[1], args[2], businessLimitConv, BusinessWalletIDsha, BusinessLoanWalletIDsha, BusinessLiabilityWalletIDsha, maxROIconvertion, minROIconvertion, BusinessPrincipalOutstandingWalletIDsha, BusinessChargesOutstandingWalletIDsha, currency}
+	newInfoBytes, _ := json.Marshal(newInfo)
+	err = history.Put(stub, args[0], newInfoBytes) // businessID = args[0]
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, err.Error())
+	}
+
+	fmt.Println("Successfully added buissness " + args[1] + " to the ledger")
+	return shim.Success([]byte("Successfully added buissness " + args[1] + " to the ledger"))
+}
+
+func createWallet(stub shim.ChaincodeStubInterface, walletID string, amt string, currency string) pb.Response {
+	//Calling the wallet Chaincode to create new wallet
+	err := common.NewWalletClient(stub).NewWallet(walletID, amt, currency)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, "Unable to create new wallet from business")
+	}
+	return shim.Success([]byte("businesscc: " + "created new wallet from business"))
+}
+
+func getBusinessInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("businesscc", errcode.InvalidArgument, "Invalid number of arguments in getBusinessInfo (required:1) given:"+xLenStr)
+	}
+
+	parsedBusinessInfo := businessInfo{}
+	businessIDvalue, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, "Failed to get the business information: "+err.Error())
+	} else if businessIDvalue == nil {
+		return errcode.Error("businesscc", errcode.NotFound, "No information is avalilable on this businessID "+args[0])
+	}
+
+	err = json.Unmarshal(businessIDvalue, &parsedBusinessInfo)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, "Unable to parse businessInfo into the structure "+err.Error())
+	}
+	jsonString := fmt.Sprintf("%+v", parsedBusinessInfo)
+	fmt.Printf("Business Info: %s\n", jsonString)
+	return shim.Success(nil)
+}
+
+func bisIDexists(stub shim.ChaincodeStubInterface, bisID string) pb.Response {
+	ifExists, _ := stub.GetState(bisID)
+	if ifExists != nil {
+		fmt.Println(ifExists)
+		return errcode.Error("businesscc", errcode.AlreadyExists, "BusinessId "+bisID+" exits. Cannot create new ID")
+	}
+	return shim.Success(nil)
+}
+
+func updateBusinessInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+		args[0] -> BusinessId
+		args[1] -> Business Limit / MAX ROI / MAX ROI
+		args[2] -> values
+	*/
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("businesscc", errcode.InvalidArgument, "Invalid number of arguments in updateBusinessInfo(business) (required:3) given:"+xLenStr)
+	}
+
+	parsedBusinessInfo := businessInfo{}
+	businessIDvalue, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, "Failed to get the business information(updateBusinessInfo): "+err.Error())
+	} else if businessIDvalue == nil {
+		return errcode.Error("businesscc", errcode.NotFound, "No information is avalilable on this (updateBusinessInfo) businessID "+args[0])
+	}
+
+	err = json.Unmarshal(businessIDvalue, &parsedBusinessInfo)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, "Unable to parse businessInfo into the structure(updateBusinessInfo) "+err.Error())
+	}
+
+	lowerStr := strings.ToLower(args[1])
+
+	value, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.InvalidArgument, "value (updateBusinessInfo):"+err.Error())
+	}
+
+	if lowerStr == "business limit" {
+		parsedBusinessInfo.BusinessLimit = value
+	} else if lowerStr == "max roi" {
+		parsedBusinessInfo.MaxROI = value
+	} else if lowerStr == "min roi" {
+		parsedBusinessInfo.MinROI = value
+	}
+
+	parsedBusinessInfoBytes, _ := json.Marshal(parsedBusinessInfo)
+	err = history.Put(stub, args[0], parsedBusinessInfoBytes)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, "Error in updating business: "+err.Error())
+	}
+
+	return shim.Success([]byte("businesscc: " + "Successfully updated Business " + args[0]))
+
+}
+
+func getWalletID(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("businesscc", errcode.InvalidArgument, "Invalid number of arguments in getWalletId(business) (required:2) given:"+xLenStr)
+	}
+
+	parsedBusinessInfo := businessInfo{}
+	businessIDvalue, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, "Failed to get the business information: "+err.Error())
+	} else if businessIDvalue == nil {
+		return errcode.Error("businesscc", errcode.NotFound, "No information is avalilable on this businessID "+args[0])
+	}
+
+	err = json.Unmarshal(businessIDvalue, &parsedBusinessInfo)
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, "Unable to parse into the structure "+err.Error())
+	}
+
+	walletID := ""
+
+	switch args[1] {
+	case "main":
+		walletID = parsedBusinessInfo.BusinessWalletID
+	case "loan":
+		walletID = parsedBusinessInfo.BusinessLoanWalletID
+	case "liability":
+		walletID = parsedBusinessInfo.BusinessLiabilityWalletID
+	case "principalOut":
+		walletID = parsedBusinessInfo.BusinessPrincipalOutstandingWalletID
+	case "chargesOut":
+		walletID = parsedBusinessInfo.BusinessChargesOutstandingWalletID
+	default:
+		return errcode.Error("businesscc", errcode.NotFound, "There is no wallet of this type in Business :"+args[1])
+	}
+
+	return shim.Success([]byte(walletID))
+}
+
+func getBusinessHistory(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> BusinessID
+	 *args[1] -> page size, 20 when not given (at most 100)
+	 *args[2] -> bookmark of the previous page, for the next one
+	 */
+	if len(args) < 1 || len(args) > 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("businesscc", errcode.InvalidArgument, "Invalid number of arguments in getBusinessHistory (required:1 to 3) given: "+xLenStr)
+	}
+	page, err := history.Get(stub, args[0], args[1:])
+	if err != nil {
+		return errcode.Error("businesscc", errcode.Internal, err.Error())
+	}
+	pageBytes, _ := json.Marshal(page)
+	return shim.Success(pageBytes)
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+
+	businesscc "github.com/chaincode/Business/businesscc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() {
+	err := shim.Start(new(businesscc.Chaincode))
+	if err != nil {
+		fmt.Printf("businesscc: "+"Error starting Business chaincode: %s\n", err)
+	}
+
+}
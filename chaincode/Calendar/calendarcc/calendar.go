@@ -0,0 +1,355 @@
+package calendarcc
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	auth "github.com/chaincode/Auth"
+	clock "github.com/chaincode/Clock"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	history "github.com/chaincode/History"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Chaincode is the calendarcc chaincode
+type Chaincode struct {
+}
+
+// A calendar holds the days a bank or a region does no business on: its
+// weekend, and its holidays, which are put one at a time. Programs name the
+// calendar their due dates are moved off those days on.
+
+// calendarIndex is the composite key prefix of the calendars, keyed by ID
+const calendarIndex = "Calendar~CalendarID"
+
+// holidayIndex is the composite key prefix of the holidays of each calendar,
+// keyed by the calendar ID and the date as yyyymmdd
+const holidayIndex = "Holiday~CalendarID~Date"
+
+type calendarInfo struct {
+	Name    string   `json:"Name"`    //[1] bank or region
+	Weekend []string `json:"Weekend"` //[2] weekdays, e.g. Sunday
+}
+
+type holidayInfo struct {
+	Date time.Time `json:"Date"` //[1]
+	Name string    `json:"Name"` //[2]
+}
+
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	// the channel and the names of the other chaincodes, if instantiated with them
+	err := common.InitConfig(stub)
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.InvalidArgument, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// accessPolicy says who may call each function of calendarcc
+var accessPolicy = auth.Policy{
+	"putCalendar":   auth.Roles(auth.BankAdmin),
+	"getCalendar":   auth.Roles(auth.Anyone),
+	"putHoliday":    auth.Roles(auth.BankAdmin),
+	"removeHoliday": auth.Roles(auth.BankAdmin),
+	"getHolidays":   auth.Roles(auth.Anyone),
+	"isBusinessDay": auth.Roles(auth.Anyone),
+	"adjustDate":    auth.Roles(auth.Anyone),
+	"getConfig":     auth.Roles(auth.Anyone),
+}
+
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	err := accessPolicy.Check(stub, function)
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Unauthorized, err.Error())
+	}
+
+	if function == "putCalendar" {
+		//Creates a calendar, or changes its name and weekend
+		return putCalendar(stub, args)
+	} else if function == "getCalendar" {
+		//Retrieves a calendar
+		return getCalendar(stub, args)
+	} else if function == "putHoliday" {
+		//Adds a holiday to a calendar
+		return putHoliday(stub, args)
+	} else if function == "removeHoliday" {
+		//Takes a holiday off a calendar
+		return removeHoliday(stub, args)
+	} else if function == "getHolidays" {
+		//Lists the holidays of a calendar, of a year if given
+		return getHolidays(stub, args)
+	} else if function == "isBusinessDay" {
+		//Tells whether a date is a business day on a calendar
+		return isBusinessDay(stub, args)
+	} else if function == "adjustDate" {
+		//Moves a date to a business day on a calendar by a business-day rule
+		return adjustDate(stub, args)
+	} else if function == "getConfig" {
+		//Returns the channel and the chaincode names it was instantiated with
+		cfgBytes, err := common.GetConfig(stub)
+		if err != nil {
+			return errcode.Error("calendarcc", errcode.Internal, err.Error())
+		}
+		return shim.Success(cfgBytes)
+	}
+	return errcode.Error("calendarcc", errcode.InvalidArgument, "No function named "+function+" in Calendar")
+}
+
+func putCalendar(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> CalendarID
+	 *args[1] -> Name of the bank or region
+	 *args[2] -> Weekend, weekdays separated by commas (e.g. Saturday,Sunday), empty for none
+	 */
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("calendarcc", errcode.InvalidArgument, "Invalid number of arguments in putCalendar (required:3) given: "+xLenStr)
+	}
+	cal := calendarInfo{Name: args[1], Weekend: []string{}}
+	if strings.TrimSpace(args[2]) != "" {
+		for _, day := range strings.Split(args[2], ",") {
+			weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(day))]
+			if !ok {
+				return errcode.Error("calendarcc", errcode.InvalidArgument, "Invalid weekday in the weekend: "+day)
+			}
+			cal.Weekend = append(cal.Weekend, weekday.String())
+		}
+	}
+	calendarKey, err := stub.CreateCompositeKey(calendarIndex, []string{args[0]})
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	}
+	calBytes, _ := json.Marshal(cal)
+	err = history.Put(stub, calendarKey, calBytes)
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, "cannot write calendar: "+err.Error())
+	}
+	return shim.Success(calBytes)
+}
+
+func getCalendar(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> CalendarID
+	 */
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("calendarcc", errcode.InvalidArgument, "Invalid number of arguments in getCalendar (required:1) given: "+xLenStr)
+	}
+	cal, err := loadCalendar(stub, args[0])
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	}
+	calBytes, _ := json.Marshal(cal)
+	return shim.Success(calBytes)
+}
+
+func putHoliday(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> CalendarID
+	 *args[1] -> Date of the holiday
+	 *args[2] -> Name of the holiday
+	 */
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("calendarcc", errcode.InvalidArgument, "Invalid number of arguments in putHoliday (required:3) given: "+xLenStr)
+	}
+	_, err := loadCalendar(stub, args[0])
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	}
+	date, err := time.Parse(clock.DateLayout, args[1])
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.InvalidArgument, err.Error())
+	}
+	holidayKey, err := stub.CreateCompositeKey(holidayIndex, []string{args[0], date.Format("20060102")})
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	}
+	holidayBytes, _ := json.Marshal(holidayInfo{date, args[2]})
+	err = history.Put(stub, holidayKey, holidayBytes)
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, "cannot write holiday: "+err.Error())
+	}
+	return shim.Success([]byte("Holiday " + args[1] + " added to " + args[0]))
+}
+
+func removeHoliday(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> CalendarID
+	 *args[1] -> Date of the holiday
+	 */
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("calendarcc", errcode.InvalidArgument, "Invalid number of arguments in removeHoliday (required:2) given: "+xLenStr)
+	}
+	date, err := time.Parse(clock.DateLayout, args[1])
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.InvalidArgument, err.Error())
+	}
+	holidayKey, err := stub.CreateCompositeKey(holidayIndex, []string{args[0], date.Format("20060102")})
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	}
+	holidayBytes, err := stub.GetState(holidayKey)
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	} else if holidayBytes == nil {
+		return errcode.Error("calendarcc", errcode.NotFound, args[1]+" is not a holiday on "+args[0])
+	}
+	err = stub.DelState(holidayKey)
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte("Holiday " + args[1] + " removed from " + args[0]))
+}
+
+func getHolidays(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> CalendarID
+	 *args[1] -> Year (optional)
+	 */
+	if len(args) != 1 && len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("calendarcc", errcode.InvalidArgument, "Invalid number of arguments in getHolidays (required:1 or 2) given: "+xLenStr)
+	}
+	year := 0
+	if len(args) == 2 {
+		var err error
+		year, err = strconv.Atoi(args[1])
+		if err != nil {
+			return errcode.Error("calendarcc", errcode.InvalidArgument, "Invalid year: "+args[1])
+		}
+	}
+	holidaysIterator, err := stub.GetStateByPartialCompositeKey(holidayIndex, []string{args[0]})
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	}
+	defer holidaysIterator.Close()
+	holidays := []holidayInfo{}
+	for holidaysIterator.HasNext() {
+		kv, err := holidaysIterator.Next()
+		if err != nil {
+			return errcode.Error("calendarcc", errcode.Internal, err.Error())
+		}
+		holiday := holidayInfo{}
+		err = json.Unmarshal(kv.Value, &holiday)
+		if err != nil {
+			return errcode.Error("calendarcc", errcode.Internal, err.Error())
+		}
+		if year == 0 || holiday.Date.Year() == year {
+			holidays = append(holidays, holiday)
+		}
+	}
+	holidaysBytes, _ := json.Marshal(holidays)
+	return shim.Success(holidaysBytes)
+}
+
+func isBusinessDay(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> CalendarID
+	 *args[1] -> Date
+	 */
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("calendarcc", errcode.InvalidArgument, "Invalid number of arguments in isBusinessDay (required:2) given: "+xLenStr)
+	}
+	date, err := time.Parse(clock.DateLayout, args[1])
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.InvalidArgument, err.Error())
+	}
+	cal, err := loadCalendar(stub, args[0])
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	}
+	holiday, err := holidayCheck(stub, args[0], cal)(date)
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte(strconv.FormatBool(!holiday)))
+}
+
+func adjustDate(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> CalendarID
+	 *args[1] -> Date
+	 *args[2] -> Business-day rule: none, following, modified_following or preceding
+	 */
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("calendarcc", errcode.InvalidArgument, "Invalid number of arguments in adjustDate (required:3) given: "+xLenStr)
+	}
+	date, err := time.Parse(clock.DateLayout, args[1])
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.InvalidArgument, err.Error())
+	}
+	rule, err := clock.ParseBusinessDayRule(args[2])
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.InvalidArgument, err.Error())
+	}
+	cal, err := loadCalendar(stub, args[0])
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.Internal, err.Error())
+	}
+	adjusted, err := rule.Adjust(date, holidayCheck(stub, args[0], cal))
+	if err != nil {
+		return errcode.Error("calendarcc", errcode.InvalidState, err.Error())
+	}
+	return shim.Success([]byte(adjusted.Format(clock.DateLayout)))
+}
+
+// weekdays are the weekdays by their lower case names
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+func loadCalendar(stub shim.ChaincodeStubInterface, calendarID string) (calendarInfo, error) {
+	cal := calendarInfo{}
+	calendarKey, err := stub.CreateCompositeKey(calendarIndex, []string{calendarID})
+	if err != nil {
+		return cal, err
+	}
+	calBytes, err := stub.GetState(calendarKey)
+	if err != nil {
+		return cal, err
+	} else if calBytes == nil {
+		return cal, errcode.New(errcode.NotFound, "No calendar exists on this calendarID: "+calendarID)
+	}
+	err = json.Unmarshal(calBytes, &cal)
+	return cal, err
+}
+
+// holidayCheck returns whether a day is on the weekend or a holiday of the
+// calendar
+func holidayCheck(stub shim.ChaincodeStubInterface, calendarID string, cal calendarInfo) func(time.Time) (bool, error) {
+	return func(date time.Time) (bool, error) {
+		for _, day := range cal.Weekend {
+			if date.Weekday().String() == day {
+				return true, nil
+			}
+		}
+		holidayKey, err := stub.CreateCompositeKey(holidayIndex, []string{calendarID, date.Format("20060102")})
+		if err != nil {
+			return false, err
+		}
+		holidayBytes, err := stub.GetState(holidayKey)
+		return holidayBytes != nil, err
+	}
+}
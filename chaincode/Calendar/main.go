@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+
+	calendarcc "github.com/chaincode/Calendar/calendarcc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() {
+	err := shim.Start(new(calendarcc.Chaincode))
+	if err != nil {
+		fmt.Printf("calendarcc: "+"Error starting Calendar chaincode: %s\n", err)
+	}
+}
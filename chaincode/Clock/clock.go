@@ -0,0 +1,112 @@
+// Package clock is the time of a transaction. The chaincodes take every
+// timestamp they record from the transaction proposal rather than from the
+// clock of the peer, so that every endorser writes the same value.
+//
+// A loan or a transaction has two business dates. The value date is the day
+// it takes effect, which the client gives. The posting date is the day it is
+// recorded on the ledger, the date of the transaction timestamp in Location.
+// Both are kept as midnight UTC of the day, as dates parsed with DateLayout
+// are.
+//
+// A due date that falls on a weekend or a holiday is moved to a business day
+// by the BusinessDayRule of the program, on the holidays of its calendar.
+package clock
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// DateLayout is how the chaincodes take a date as an argument
+const DateLayout = "02/01/2006"
+
+// Location is where business dates are counted: India Standard Time, which
+// has no daylight saving and needs no time zone database on the peer
+var Location = time.FixedZone("IST", 5*60*60+30*60)
+
+// Now returns the timestamp of the transaction proposal in UTC
+func Now(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	txTimestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).UTC(), nil
+}
+
+// PostingDate returns the business date of the transaction
+func PostingDate(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	now, err := Now(stub)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return Date(now), nil
+}
+
+// Date returns the business date of the time
+func Date(t time.Time) time.Time {
+	t = t.In(Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// BusinessDayRule says how a date that is not a business day is moved to one
+type BusinessDayRule string
+
+// Business-day rules
+const (
+	Unadjusted        BusinessDayRule = "none"               // the date is kept
+	Following         BusinessDayRule = "following"          // the next business day
+	ModifiedFollowing BusinessDayRule = "modified_following" // the next one, or the one before if that is in the next month
+	Preceding         BusinessDayRule = "preceding"          // the business day before
+)
+
+// maxNonBusinessDays bounds the days Adjust looks through, so that a
+// calendar without business days fails instead of looping
+const maxNonBusinessDays = 366
+
+// ParseBusinessDayRule reads a business-day rule by its name
+func ParseBusinessDayRule(s string) (BusinessDayRule, error) {
+	switch rule := BusinessDayRule(strings.ToLower(strings.TrimSpace(s))); rule {
+	case Unadjusted, Following, ModifiedFollowing, Preceding:
+		return rule, nil
+	}
+	return "", errors.New("clock: unknown business-day rule " + strconv.Quote(s) + ", use none, following, modified_following or preceding")
+}
+
+// Adjust moves the date to a business day by the rule. isHoliday says
+// whether a day is not a business day.
+func (rule BusinessDayRule) Adjust(date time.Time, isHoliday func(time.Time) (bool, error)) (time.Time, error) {
+	switch rule {
+	case Unadjusted:
+		return date, nil
+	case Following, Preceding:
+		step := 1
+		if rule == Preceding {
+			step = -1
+		}
+		return roll(date, step, isHoliday)
+	case ModifiedFollowing:
+		next, err := roll(date, 1, isHoliday)
+		if err != nil || next.Month() == date.Month() {
+			return next, err
+		}
+		return roll(date, -1, isHoliday)
+	}
+	return date, errors.New("clock: unknown business-day rule " + strconv.Quote(string(rule)))
+}
+
+// roll returns the first business day from the date on, a day at a time in
+// the direction of step
+func roll(date time.Time, step int, isHoliday func(time.Time) (bool, error)) (time.Time, error) {
+	for i := 0; i <= maxNonBusinessDays; i++ {
+		holiday, err := isHoliday(date)
+		if err != nil || !holiday {
+			return date, err
+		}
+		date = date.AddDate(0, 0, step)
+	}
+	return date, errors.New("clock: no business day within " + strconv.Itoa(maxNonBusinessDays) + " days")
+}
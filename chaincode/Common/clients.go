@@ -0,0 +1,489 @@
+package common
+
+import (
+	"encoding/json"
+	"time"
+
+	errcode "github.com/chaincode/Errcode"
+	money "github.com/chaincode/Money"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// CallError is the error response of a chaincode called through a client.
+// Its message is the called chaincode's, which already names it.
+type CallError struct {
+	Chaincode string
+	Function  string
+	Status    int32
+	Message   string
+}
+
+func (e *CallError) Error() string {
+	return e.Message
+}
+
+// Code returns the code of the called chaincode's error, Internal if its
+// message has no envelope
+func (e *CallError) Code() errcode.Code {
+	env, ok := errcode.Find(e.Message)
+	if !ok {
+		return errcode.Internal
+	}
+	return env.Code
+}
+
+// isCallError tells whether err is the error response of a called chaincode
+// with the code
+func isCallError(err error, code errcode.Code) bool {
+	callErr, ok := err.(*CallError)
+	return ok && callErr.Code() == code
+}
+
+// Call invokes the function of a chaincode, given by its default name
+// ("walletcc") and called by the name the Config gives it
+func Call(stub shim.ChaincodeStubInterface, chaincode string, function string, args ...string) ([]byte, error) {
+	cfg, err := LoadConfig(stub)
+	if err != nil {
+		return nil, err
+	}
+	name := cfg.Name(chaincode)
+	response := stub.InvokeChaincode(name, ToChaincodeArgs(append([]string{function}, args...)...), cfg.Channel)
+	if response.Status != shim.OK {
+		return nil, &CallError{name, function, response.Status, response.Message}
+	}
+	return response.Payload, nil
+}
+
+// ToChaincodeArgs encodes string arguments for InvokeChaincode
+func ToChaincodeArgs(args ...string) [][]byte {
+	bargs := make([][]byte, len(args))
+	for i, arg := range args {
+		bargs[i] = []byte(arg)
+	}
+	return bargs
+}
+
+// WalletLeg is a credit or a debit of a wallet under the txnID, one of the
+// legs walletcc moves the wallet by in one call. FxRateID is the rate the leg
+// was converted into the wallet's currency by, if it was.
+type WalletLeg struct {
+	TxnID    string       `json:"txnID"`
+	CAmt     money.Amount `json:"cAmt"`
+	DAmt     money.Amount `json:"dAmt"`
+	FxRateID string       `json:"fxRateID,omitempty"`
+}
+
+// FxRate is an FX rate walletcc holds: Rate units of ToCurrency for one unit
+// of FromCurrency
+type FxRate struct {
+	FromCurrency string     `json:"fromCurrency"`
+	ToCurrency   string     `json:"toCurrency"`
+	Rate         money.Rate `json:"rate"`
+}
+
+// WalletMovement is what walletcc returns for each leg it moves a wallet by
+type WalletMovement struct {
+	TxnID      string       `json:"txnID"`
+	WalletID   string       `json:"walletID"`
+	OpeningBal money.Amount `json:"openingBal"`
+	CAmt       money.Amount `json:"cAmt"`
+	DAmt       money.Amount `json:"dAmt"`
+	ClosingBal money.Amount `json:"closingBal"`
+	FxRateID   string       `json:"fxRateID,omitempty"`
+}
+
+// WalletClient calls walletcc
+type WalletClient struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewWalletClient returns a walletcc client for the transaction
+func NewWalletClient(stub shim.ChaincodeStubInterface) WalletClient {
+	return WalletClient{stub}
+}
+
+// NewWallet creates a wallet with an opening balance
+func (c WalletClient) NewWallet(walletID string, amt string, currency string) error {
+	_, err := Call(c.stub, "walletcc", "newWallet", walletID, amt, currency)
+	return err
+}
+
+// Currency returns the currency of the wallet
+func (c WalletClient) Currency(walletID string) (string, error) {
+	payload, err := Call(c.stub, "walletcc", "getWalletCurrency", walletID)
+	return string(payload), err
+}
+
+// Balance returns the balance of the wallet
+func (c WalletClient) Balance(walletID string) (money.Amount, error) {
+	currency, err := c.Currency(walletID)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	payload, err := Call(c.stub, "walletcc", "getWallet", walletID)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	return money.Parse(string(payload), currency)
+}
+
+// FxRate returns the FX rate
+func (c WalletClient) FxRate(fxRateID string) (FxRate, error) {
+	fx := FxRate{}
+	payload, err := Call(c.stub, "walletcc", "getFxRate", fxRateID)
+	if err != nil {
+		return fx, err
+	}
+	err = json.Unmarshal(payload, &fx)
+	return fx, err
+}
+
+// Move applies the legs to the wallet in order, each journalled under its
+// own txnID, and returns the movement of each
+func (c WalletClient) Move(walletID string, legs []WalletLeg) ([]WalletMovement, error) {
+	mvs := []WalletMovement{}
+	legsBytes, _ := json.Marshal(legs)
+	payload, err := Call(c.stub, "walletcc", "move", walletID, string(legsBytes))
+	if err != nil {
+		return mvs, err
+	}
+	err = json.Unmarshal(payload, &mvs)
+	return mvs, err
+}
+
+// LoanClient calls loancc
+type LoanClient struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewLoanClient returns a loancc client for the transaction
+func NewLoanClient(stub shim.ChaincodeStubInterface) LoanClient {
+	return LoanClient{stub}
+}
+
+// Status returns the status of the loan
+func (c LoanClient) Status(loanID string) (string, error) {
+	payload, err := Call(c.stub, "loancc", "getLoanStatus", loanID)
+	return string(payload), err
+}
+
+// SanctionAmt returns the sanctioned amount of the loan, as loancc prints it
+func (c LoanClient) SanctionAmt(loanID string) (string, error) {
+	payload, err := Call(c.stub, "loancc", "getLoanSancAmt", loanID)
+	return string(payload), err
+}
+
+// WalletID returns the ID of the loan's wallet of the type
+func (c LoanClient) WalletID(loanID string, walletType string) (string, error) {
+	payload, err := Call(c.stub, "loancc", "getWalletID", loanID, walletType)
+	return string(payload), err
+}
+
+// SellerID returns the seller business of the loan
+func (c LoanClient) SellerID(loanID string) (string, error) {
+	payload, err := Call(c.stub, "loancc", "getSellerID", loanID)
+	return string(payload), err
+}
+
+// ProgramID returns the program of the loan
+func (c LoanClient) ProgramID(loanID string) (string, error) {
+	payload, err := Call(c.stub, "loancc", "getProgramID", loanID)
+	return string(payload), err
+}
+
+// BuyerID returns the buyer business of the loan
+func (c LoanClient) BuyerID(loanID string) (string, error) {
+	payload, err := Call(c.stub, "loancc", "getBuyerID", loanID)
+	return string(payload), err
+}
+
+// Update moves the loan to the status for a posted txn of the type
+func (c LoanClient) Update(loanID string, status string, txnType string) error {
+	_, err := Call(c.stub, "loancc", "updateLoanInfo", loanID, status, txnType)
+	return err
+}
+
+// RestoreStatus sets the loan status back to status if it is expected
+func (c LoanClient) RestoreStatus(loanID string, expected string, status string) error {
+	_, err := Call(c.stub, "loancc", "restoreLoanStatus", loanID, expected, status)
+	return err
+}
+
+// MarkOverdue moves the loan, which is past its due date, to overdue
+func (c LoanClient) MarkOverdue(loanID string, reason string) error {
+	_, err := Call(c.stub, "loancc", "markLoanOverdue", loanID, reason)
+	return err
+}
+
+// AccruingLoan is a loan that earns interest, with what accruing it needs
+type AccruingLoan struct {
+	LoanID            string     `json:"-"`
+	InstrumentID      string     `json:"InstrumentNo"`
+	SellerID          string     `json:"SellerID"`
+	BuyerID           string     `json:"BuyerID"`
+	ROI               money.Rate `json:"ROI"`
+	ValueDate         time.Time  `json:"ValueDate"`
+	DueDate           time.Time  `json:"DueDate"`
+	Status            string     `json:"LoanStatus"`
+	DisbursedWalletID string     `json:"DisbursementWallet"`
+}
+
+// Accruing returns every loan that earns interest, in the order loancc lists
+// them, which is not the loan ID order
+func (c LoanClient) Accruing() ([]AccruingLoan, error) {
+	return c.listAccruing()
+}
+
+// Repayable returns the loans of the buyer that are disbursed and not yet
+// repaid, which are those that earn interest, in the order loancc lists them
+func (c LoanClient) Repayable(buyerID string) ([]AccruingLoan, error) {
+	return c.listAccruing(buyerID)
+}
+
+func (c LoanClient) listAccruing(args ...string) ([]AccruingLoan, error) {
+	payload, err := Call(c.stub, "loancc", "listAccruingLoans", args...)
+	if err != nil {
+		return nil, err
+	}
+	results := []struct {
+		Key    string       `json:"key"`
+		Record AccruingLoan `json:"record"`
+	}{}
+	err = json.Unmarshal(payload, &results)
+	if err != nil {
+		return nil, err
+	}
+	loans := []AccruingLoan{}
+	for _, result := range results {
+		result.Record.LoanID = result.Key
+		loans = append(loans, result.Record)
+	}
+	return loans, nil
+}
+
+// BusinessClient calls businesscc
+type BusinessClient struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewBusinessClient returns a businesscc client for the transaction
+func NewBusinessClient(stub shim.ChaincodeStubInterface) BusinessClient {
+	return BusinessClient{stub}
+}
+
+// Exists tells whether the business is on the ledger
+func (c BusinessClient) Exists(businessID string) (bool, error) {
+	// bisIDexists fails when the ID is taken
+	_, err := Call(c.stub, "businesscc", "bisIDexists", businessID)
+	if isCallError(err, errcode.AlreadyExists) {
+		return true, nil
+	}
+	return false, err
+}
+
+// WalletID returns the ID of the business's wallet of the type
+func (c BusinessClient) WalletID(businessID string, walletType string) (string, error) {
+	payload, err := Call(c.stub, "businesscc", "getWalletID", businessID, walletType)
+	return string(payload), err
+}
+
+// BankClient calls bankcc
+type BankClient struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewBankClient returns a bankcc client for the transaction
+func NewBankClient(stub shim.ChaincodeStubInterface) BankClient {
+	return BankClient{stub}
+}
+
+// WalletID returns the ID of the bank's wallet of the type
+func (c BankClient) WalletID(bankID string, walletType string) (string, error) {
+	payload, err := Call(c.stub, "bankcc", "getWalletID", bankID, walletType)
+	return string(payload), err
+}
+
+// TxnBalRecord is the part of a txnbalcc txn_balance_object that says how a
+// wallet moved
+type TxnBalRecord struct {
+	WalletID string       `json:"WalletID"`
+	Currency string       `json:"Currency"`
+	CAmt     money.Amount `json:"CreditAmount"`
+	DAmt     money.Amount `json:"DebitAmount"`
+}
+
+// TxnBalClient calls txnbalcc
+type TxnBalClient struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewTxnBalClient returns a txnbalcc client for the transaction
+func NewTxnBalClient(stub shim.ChaincodeStubInterface) TxnBalClient {
+	return TxnBalClient{stub}
+}
+
+// Put writes a txn_balance_object from the 13 arguments of putTxnBalInfo
+func (c TxnBalClient) Put(args []string) error {
+	_, err := Call(c.stub, "txnbalcc", "putTxnBalInfo", args...)
+	return err
+}
+
+// Record reads back a txn_balance_object
+func (c TxnBalClient) Record(txnBalID string) (TxnBalRecord, error) {
+	record := TxnBalRecord{}
+	payload, err := Call(c.stub, "txnbalcc", "getTxnBalRecord", txnBalID)
+	if err != nil {
+		return record, err
+	}
+	err = json.Unmarshal(payload, &record)
+	return record, err
+}
+
+// InstrumentClient calls instrumentcc
+type InstrumentClient struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewInstrumentClient returns an instrumentcc client for the transaction
+func NewInstrumentClient(stub shim.ChaincodeStubInterface) InstrumentClient {
+	return InstrumentClient{stub}
+}
+
+// Exists tells whether the seller has the instrument
+func (c InstrumentClient) Exists(refNo string, sellerID string) (bool, error) {
+	_, err := Call(c.stub, "instrumentcc", "getInstrument", refNo, sellerID)
+	if isCallError(err, errcode.NotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Amount returns the amount of the instrument, as instrumentcc prints it
+func (c InstrumentClient) Amount(refNo string, sellerID string) (string, error) {
+	payload, err := Call(c.stub, "instrumentcc", "getInstrumentAmt", refNo, sellerID)
+	return string(payload), err
+}
+
+// Currency returns the currency of the instrument
+func (c InstrumentClient) Currency(refNo string, sellerID string) (string, error) {
+	payload, err := Call(c.stub, "instrumentcc", "getInstrumentCurrency", refNo, sellerID)
+	return string(payload), err
+}
+
+// UpdateStatus sets the status of the instrument
+func (c InstrumentClient) UpdateStatus(refNo string, sellerID string, status string) error {
+	_, err := Call(c.stub, "instrumentcc", "updateInstrumentStatus", refNo, sellerID, status)
+	return err
+}
+
+// RestoreStatus sets the instrument status back to status if it is expected
+func (c InstrumentClient) RestoreStatus(refNo string, sellerID string, expected string, status string) error {
+	_, err := Call(c.stub, "instrumentcc", "restoreInstrumentStatus", refNo, sellerID, expected, status)
+	return err
+}
+
+// ProgramClient calls programcc
+type ProgramClient struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewProgramClient returns a programcc client for the transaction
+func NewProgramClient(stub shim.ChaincodeStubInterface) ProgramClient {
+	return ProgramClient{stub}
+}
+
+// Exists tells whether the program is on the ledger
+func (c ProgramClient) Exists(programID string) (bool, error) {
+	// programIDexists fails when the ID is taken
+	_, err := Call(c.stub, "programcc", "programIDexists", programID)
+	if isCallError(err, errcode.AlreadyExists) {
+		return true, nil
+	}
+	return false, err
+}
+
+// DueDateRule returns the calendar and the business-day rule the due dates
+// of the program are adjusted by. The calendar is empty if it has none.
+func (c ProgramClient) DueDateRule(programID string) (string, string, error) {
+	payload, err := Call(c.stub, "programcc", "getDueDateRule", programID)
+	if err != nil {
+		return "", "", err
+	}
+	rule := struct {
+		Calendar    string `json:"calendar"`
+		DueDateRule string `json:"dueDateRule"`
+	}{}
+	err = json.Unmarshal(payload, &rule)
+	return rule.Calendar, rule.DueDateRule, err
+}
+
+// AdjustDueDate moves the due date to a business day on the calendar of the
+// program, by its rule. It is kept as it is if the program has no calendar.
+func (c ProgramClient) AdjustDueDate(programID string, dueDate time.Time) (time.Time, error) {
+	calendarID, rule, err := c.DueDateRule(programID)
+	if err != nil || calendarID == "" {
+		return dueDate, err
+	}
+	return NewCalendarClient(c.stub).Adjust(calendarID, dueDate, rule)
+}
+
+// Waterfall returns the order repayments on the loans of the program pay
+// penal interest, charges, interest and principal in
+func (c ProgramClient) Waterfall(programID string) ([]string, error) {
+	payload, err := Call(c.stub, "programcc", "getWaterfall", programID)
+	if err != nil {
+		return nil, err
+	}
+	waterfall := []string{}
+	err = json.Unmarshal(payload, &waterfall)
+	return waterfall, err
+}
+
+// CalendarClient calls calendarcc
+type CalendarClient struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewCalendarClient returns a calendarcc client for the transaction
+func NewCalendarClient(stub shim.ChaincodeStubInterface) CalendarClient {
+	return CalendarClient{stub}
+}
+
+// Exists tells whether the calendar is on the ledger
+func (c CalendarClient) Exists(calendarID string) (bool, error) {
+	_, err := Call(c.stub, "calendarcc", "getCalendar", calendarID)
+	if isCallError(err, errcode.NotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Adjust moves the date to a business day on the calendar by the
+// business-day rule
+func (c CalendarClient) Adjust(calendarID string, date time.Time, rule string) (time.Time, error) {
+	payload, err := Call(c.stub, "calendarcc", "adjustDate", calendarID, date.Format("02/01/2006"), rule)
+	if err != nil {
+		return date, err
+	}
+	return time.Parse("02/01/2006", string(payload))
+}
+
+// PPRClient calls pprcc
+type PPRClient struct {
+	stub shim.ChaincodeStubInterface
+}
+
+// NewPPRClient returns a pprcc client for the transaction
+func NewPPRClient(stub shim.ChaincodeStubInterface) PPRClient {
+	return PPRClient{stub}
+}
+
+// Exists tells whether the PPR is on the ledger
+func (c PPRClient) Exists(pprID string) (bool, error) {
+	// pprIDexists fails when the ID is taken
+	_, err := Call(c.stub, "pprcc", "pprIDexists", pprID)
+	if isCallError(err, errcode.AlreadyExists) {
+		return true, nil
+	}
+	return false, err
+}
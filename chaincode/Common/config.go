@@ -0,0 +1,145 @@
+// Package common holds what the Encore chaincodes share when they call each
+// other: the channel and chaincode names they are deployed under, and typed
+// clients for the chaincodes they call.
+package common
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// configIndex is the composite key a chaincode keeps the Config it was
+// instantiated with under, apart from its records
+const configIndex = "Config"
+
+// Config names the channel and the chaincodes on it. A chaincode calls the
+// others by these names.
+type Config struct {
+	Channel    string `json:"channel"`
+	Bank       string `json:"bankcc"`
+	Business   string `json:"businesscc"`
+	Calendar   string `json:"calendarcc"`
+	Instrument string `json:"instrumentcc"`
+	Loan       string `json:"loancc"`
+	PPR        string `json:"pprcc"`
+	Program    string `json:"programcc"`
+	TxnBal     string `json:"txnbalcc"`
+	Txn        string `json:"txncc"`
+	Wallet     string `json:"walletcc"`
+
+	// Registry is the chaincode the Config was read from at Init, if any
+	Registry string `json:"registry,omitempty"`
+}
+
+// DefaultConfig is the channel and the names in the install scripts
+func DefaultConfig() Config {
+	return Config{
+		Channel:    "myc",
+		Bank:       "bankcc",
+		Business:   "businesscc",
+		Calendar:   "calendarcc",
+		Instrument: "instrumentcc",
+		Loan:       "loancc",
+		PPR:        "pprcc",
+		Program:    "programcc",
+		TxnBal:     "txnbalcc",
+		Txn:        "txncc",
+		Wallet:     "walletcc",
+	}
+}
+
+// Name returns the name the chaincode with the default name is deployed
+// under
+func (c Config) Name(chaincode string) string {
+	switch chaincode {
+	case "bankcc":
+		return c.Bank
+	case "businesscc":
+		return c.Business
+	case "calendarcc":
+		return c.Calendar
+	case "instrumentcc":
+		return c.Instrument
+	case "loancc":
+		return c.Loan
+	case "pprcc":
+		return c.PPR
+	case "programcc":
+		return c.Program
+	case "txnbalcc":
+		return c.TxnBal
+	case "txncc":
+		return c.Txn
+	case "walletcc":
+		return c.Wallet
+	}
+	return chaincode
+}
+
+// InitConfig stores the Config given to Init as a JSON object in the first
+// argument, e.g. {"Args":["init","{\"channel\":\"uatc\"}"]}. Names left out
+// keep their default. With a registry, e.g. {"channel":"uatc","registry":
+// "bankcc"}, the Config is read from the getConfig of that chaincode on the
+// channel instead, and the other names given override it. Without an
+// argument the stored Config is kept, so an upgrade does not reset it.
+func InitConfig(stub shim.ChaincodeStubInterface) error {
+	_, args := stub.GetFunctionAndParameters()
+	if len(args) == 0 || args[0] == "" {
+		return nil
+	}
+
+	cfg := DefaultConfig()
+	err := json.Unmarshal([]byte(args[0]), &cfg)
+	if err != nil {
+		return errors.New("invalid config: " + err.Error())
+	}
+	if cfg.Registry != "" {
+		response := stub.InvokeChaincode(cfg.Registry, ToChaincodeArgs("getConfig"), cfg.Channel)
+		if response.Status != shim.OK {
+			return errors.New("config registry " + cfg.Registry + ": " + response.Message)
+		}
+		registry := cfg.Registry
+		cfg = Config{}
+		err = json.Unmarshal(response.Payload, &cfg)
+		if err != nil {
+			return errors.New("config registry " + registry + ": " + err.Error())
+		}
+		// the names given to Init win over the registry's
+		json.Unmarshal([]byte(args[0]), &cfg)
+		cfg.Registry = registry
+	}
+	configKey, err := stub.CreateCompositeKey(configIndex, []string{})
+	if err != nil {
+		return err
+	}
+	cfgBytes, _ := json.Marshal(cfg)
+	return stub.PutState(configKey, cfgBytes)
+}
+
+// LoadConfig returns the Config the chaincode was instantiated with, or the
+// DefaultConfig
+func LoadConfig(stub shim.ChaincodeStubInterface) (Config, error) {
+	cfg := DefaultConfig()
+	configKey, err := stub.CreateCompositeKey(configIndex, []string{})
+	if err != nil {
+		return cfg, err
+	}
+	cfgBytes, err := stub.GetState(configKey)
+	if err != nil || cfgBytes == nil {
+		return cfg, err
+	}
+	err = json.Unmarshal(cfgBytes, &cfg)
+	return cfg, err
+}
+
+// GetConfig returns the Config of the chaincode as JSON, for its getConfig
+// query
+func GetConfig(stub shim.ChaincodeStubInterface) ([]byte, error) {
+	cfg, err := LoadConfig(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cfg)
+}
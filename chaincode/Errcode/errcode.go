@@ -0,0 +1,81 @@
+// Package errcode is the error response of the Encore chaincodes: a JSON
+// envelope with a machine code, the message and the chaincode the error
+// started in, e.g.
+//
+//	{"code":"NOT_FOUND","message":"No data exists on this loanID: 1loan","chaincode":"loancc"}
+//
+// A chaincode failing because a chaincode it called failed returns the
+// envelope of that chaincode unchanged, so the client gets the code and the
+// chaincode of the first failure however deep the call was.
+package errcode
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Code says what kind of error it is
+type Code string
+
+// Codes
+const (
+	NotFound          Code = "NOT_FOUND"
+	AlreadyExists     Code = "ALREADY_EXISTS"
+	InvalidArgument   Code = "INVALID_ARGUMENT"
+	InsufficientFunds Code = "INSUFFICIENT_FUNDS"
+	InvalidState      Code = "INVALID_STATE"
+	Unauthorized      Code = "UNAUTHORIZED"
+	// Internal is a failure of the ledger or of the chaincode itself
+	Internal Code = "INTERNAL"
+)
+
+// Envelope is the error message of a chaincode response
+type Envelope struct {
+	Code      Code   `json:"code"`
+	Message   string `json:"message"`
+	Chaincode string `json:"chaincode"`
+}
+
+// Error returns the envelope as JSON
+func (e *Envelope) Error() string {
+	envBytes, _ := json.Marshal(e)
+	return string(envBytes)
+}
+
+// New returns an error with the code, for the packages the chaincodes share.
+// The chaincode returning it names itself in Error.
+func New(code Code, message string) error {
+	return &Envelope{Code: code, Message: message}
+}
+
+// Error returns the error response of the chaincode. When the message holds
+// an envelope, that of a chaincode it called or of an error from New, the
+// envelope is returned as it is and the code is not used.
+func Error(chaincode string, code Code, message string) pb.Response {
+	env, ok := Find(message)
+	if !ok {
+		env = &Envelope{Code: code, Message: message}
+	}
+	if env.Chaincode == "" {
+		env.Chaincode = chaincode
+	}
+	return shim.Error(env.Error())
+}
+
+// Find returns the first envelope in the message, which is a response
+// message or an error with an envelope in it
+func Find(message string) (*Envelope, bool) {
+	start := strings.Index(message, `{"code":`)
+	if start < 0 {
+		return nil, false
+	}
+	env := &Envelope{}
+	err := json.NewDecoder(strings.NewReader(message[start:])).Decode(env)
+	if err != nil || env.Code == "" {
+		return nil, false
+	}
+	return env, true
+}
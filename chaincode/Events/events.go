@@ -0,0 +1,130 @@
+// Package events defines the chaincode events the Encore chaincodes emit when
+// they change loans, instruments, transactions and wallets, so that a block
+// listener can follow those changes without polling the ledger.
+//
+// Fabric keeps a single event per transaction, and only the one set by the
+// chaincode the client invoked; events set by chaincodes it calls in turn are
+// dropped. Every chaincode therefore emits one Envelope under the event name
+// Name holding all of its events, and a chaincode that changes state through
+// another chaincode (transactioncc posting to walletcc, loancc updating
+// instrumentcc) adds the events for those changes to its own envelope.
+//
+// The payloads below are the published schema. Fields may be added within a
+// SchemaVersion but never renamed or removed; anything else bumps the version.
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	clock "github.com/chaincode/Clock"
+	money "github.com/chaincode/Money"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Name is the chaincode event name every envelope is set under
+const Name = "encore.events"
+
+// SchemaVersion is the version of the envelope and of every payload type
+const SchemaVersion = 1
+
+// Event types
+const (
+	LoanSanctioned          = "loan.sanctioned"
+	LoanStatusChanged       = "loan.statusChanged"
+	InstrumentStatusChanged = "instrument.statusChanged"
+	DisbursementPosted      = "disbursement.posted"
+	RepaymentCollected      = "repayment.collected"
+	TxnPosted               = "txn.posted"
+	TxnReversed             = "txn.reversed"
+	WalletBalanceChanged    = "wallet.balanceChanged"
+)
+
+// Envelope is the payload of the chaincode event
+type Envelope struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Chaincode     string    `json:"chaincode"`
+	TxID          string    `json:"txID"`
+	Timestamp     time.Time `json:"timestamp"`
+	Events        []Event   `json:"events"`
+}
+
+// Event is one change, in the order the chaincode made them
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Loan is the payload of loan.sanctioned
+type Loan struct {
+	LoanID       string       `json:"loanID"`
+	InstrumentID string       `json:"instrumentID"`
+	SellerID     string       `json:"sellerID"`
+	BuyerID      string       `json:"buyerID"`
+	ProgramID    string       `json:"programID"`
+	SanctionAmt  money.Amount `json:"sanctionAmt"`
+	ROI          money.Rate   `json:"roi"`
+	DueDate      time.Time    `json:"dueDate"`
+}
+
+// StatusChange is the payload of loan.statusChanged and
+// instrument.statusChanged. ID is the loan ID, or the instrument reference
+// number with SellerID set.
+type StatusChange struct {
+	ID       string `json:"id"`
+	SellerID string `json:"sellerID,omitempty"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	TxnID    string `json:"txnID,omitempty"`
+}
+
+// Txn is the payload of disbursement.posted, repayment.collected, txn.posted
+// and txn.reversed
+type Txn struct {
+	TxnID        string       `json:"txnID"`
+	TxnType      string       `json:"txnType"`
+	TxnDate      time.Time    `json:"txnDate"` // the value date
+	PostingDate  time.Time    `json:"postingDate"`
+	LoanID       string       `json:"loanID"`
+	InstrumentID string       `json:"instrumentID"`
+	Amount       money.Amount `json:"amount"`
+	From         string       `json:"from"`
+	To           string       `json:"to"`
+	By           string       `json:"by"`
+	Legs         []string     `json:"legs"`
+	Reverses     string       `json:"reverses,omitempty"`
+}
+
+// WalletBalance is the payload of wallet.balanceChanged
+type WalletBalance struct {
+	WalletID   string       `json:"walletID"`
+	TxnID      string       `json:"txnID"`
+	OpeningBal money.Amount `json:"openingBal"`
+	Credit     money.Amount `json:"credit"`
+	Debit      money.Amount `json:"debit"`
+	ClosingBal money.Amount `json:"closingBal"`
+	FxRateID   string       `json:"fxRateID,omitempty"`
+}
+
+// New returns an event of the given type
+func New(eventType string, data interface{}) Event {
+	return Event{eventType, data}
+}
+
+// Emit sets the envelope of the events as the chaincode event of the
+// transaction. It is called once per invocation, with every event.
+func Emit(stub shim.ChaincodeStubInterface, chaincode string, evs ...Event) error {
+	if len(evs) == 0 {
+		return nil
+	}
+	txTime, err := clock.Now(stub)
+	if err != nil {
+		return err
+	}
+	env := Envelope{SchemaVersion, chaincode, stub.GetTxID(), txTime, evs}
+	envBytes, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return stub.SetEvent(Name, envBytes)
+}
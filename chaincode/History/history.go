@@ -0,0 +1,153 @@
+// Package history shows how a record looked over time, for audits.
+//
+// The ledger keeps every version of a key with the ID and timestamp of the
+// transaction that wrote it, but not who sent that transaction. Chaincodes
+// therefore write the records they are audited on with Put, which also
+// stores the client of the transaction under its txID, and read back every
+// version of a record with Get, a page at a time, oldest first.
+package history
+
+import (
+	"encoding/json"
+	"time"
+
+	auth "github.com/chaincode/Auth"
+	errcode "github.com/chaincode/Errcode"
+	query "github.com/chaincode/Query"
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// invokerIndex is the composite key prefix of the client of every
+// transaction that wrote a record with Put, keyed by the txID
+const invokerIndex = "Invoker~TxID"
+
+// Invoker is the client that sent a transaction and the chaincode it sent it
+// to, which is another one when the record was written through it
+type Invoker struct {
+	MSPID     string `json:"mspID"`
+	ID        string `json:"id"`
+	Chaincode string `json:"chaincode"`
+}
+
+// Version is a record as a transaction left it. Invoker is nil for versions
+// written before the chaincode kept its invokers.
+type Version struct {
+	TxID      string          `json:"txID"`
+	Timestamp time.Time       `json:"timestamp"`
+	IsDelete  bool            `json:"isDelete"`
+	Invoker   *Invoker        `json:"invoker"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// Page is some of the versions of a record. Bookmark is passed to Get for
+// the next page and is empty on the last one.
+type Page struct {
+	Records             []Version `json:"records"`
+	FetchedRecordsCount int       `json:"fetchedRecordsCount"`
+	Bookmark            string    `json:"bookmark"`
+}
+
+// Put writes the record and notes the client of the transaction
+func Put(stub shim.ChaincodeStubInterface, key string, value []byte) error {
+	err := stub.PutState(key, value)
+	if err != nil {
+		return err
+	}
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return err
+	}
+	id, err := cid.GetID(stub)
+	if err != nil {
+		return err
+	}
+	invoked, err := auth.InvokedChaincode(stub)
+	if err != nil {
+		return err
+	}
+	invokerKey, err := stub.CreateCompositeKey(invokerIndex, []string{stub.GetTxID()})
+	if err != nil {
+		return err
+	}
+	invokerBytes, _ := json.Marshal(Invoker{mspID, id, invoked})
+	return stub.PutState(invokerKey, invokerBytes)
+}
+
+// Get returns a page of the versions of the record. pageArgs are the page
+// size and the bookmark of the page, both optional.
+func Get(stub shim.ChaincodeStubInterface, key string, pageArgs []string) (Page, error) {
+	page := Page{Records: []Version{}}
+	pageSize := query.DefaultPageSize
+	bookmark := ""
+	var err error
+	if len(pageArgs) > 0 {
+		pageSize, err = query.PageSize(pageArgs[0])
+		if err != nil {
+			return page, err
+		}
+	}
+	if len(pageArgs) > 1 {
+		bookmark = pageArgs[1]
+	}
+
+	historyIterator, err := stub.GetHistoryForKey(key)
+	if err != nil {
+		return page, err
+	}
+	defer historyIterator.Close()
+
+	// the bookmark is the txID of the last version of the previous page
+	skipping := bookmark != ""
+	for historyIterator.HasNext() {
+		mod, err := historyIterator.Next()
+		if err != nil {
+			return page, err
+		}
+		if skipping {
+			skipping = mod.TxId != bookmark
+			continue
+		}
+		if len(page.Records) == pageSize {
+			page.Bookmark = page.Records[pageSize-1].TxID
+			break
+		}
+
+		version := Version{TxID: mod.TxId, IsDelete: mod.IsDelete, Value: json.RawMessage("null")}
+		if mod.Timestamp != nil {
+			version.Timestamp = time.Unix(mod.Timestamp.Seconds, int64(mod.Timestamp.Nanos)).UTC()
+		}
+		if !mod.IsDelete {
+			version.Value = mod.Value
+			if !json.Valid(mod.Value) {
+				version.Value, _ = json.Marshal(string(mod.Value))
+			}
+		}
+		version.Invoker, err = invoker(stub, mod.TxId)
+		if err != nil {
+			return page, err
+		}
+		page.Records = append(page.Records, version)
+	}
+	if skipping {
+		return page, errcode.New(errcode.InvalidArgument, "no version "+bookmark+" of "+key+" to continue from")
+	} else if bookmark == "" && len(page.Records) == 0 {
+		return page, errcode.New(errcode.NotFound, "No history exists on this key: "+key)
+	}
+	page.FetchedRecordsCount = len(page.Records)
+	return page, nil
+}
+
+func invoker(stub shim.ChaincodeStubInterface, txID string) (*Invoker, error) {
+	invokerKey, err := stub.CreateCompositeKey(invokerIndex, []string{txID})
+	if err != nil {
+		return nil, err
+	}
+	invokerBytes, err := stub.GetState(invokerKey)
+	if err != nil || invokerBytes == nil {
+		return nil, err
+	}
+	inv := &Invoker{}
+	err = json.Unmarshal(invokerBytes, inv)
+	return inv, err
+}
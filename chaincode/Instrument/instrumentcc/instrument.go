@@ -0,0 +1,456 @@
+package instrumentcc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	auth "github.com/chaincode/Auth"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	events "github.com/chaincode/Events"
+	history "github.com/chaincode/History"
+	money "github.com/chaincode/Money"
+	schema "github.com/chaincode/Schema"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Chaincode is the instrumentcc chaincode
+type Chaincode struct {
+}
+
+type instrumentInfo struct {
+	//Instrument ID for storing is auto generated
+	InstrumentRefNo string       `json:"RefNo"`         //[0]
+	InstrumentDate  time.Time    `json:"Date"`          //[1]
+	SellBusinessID  string       `json:"SellerID"`      //[2]
+	BuyBusinsessID  string       `json:"BuyerID"`       //[3]
+	InsAmount       money.Amount `json:"Amount"`        //[4]
+	InsStatus       string       `json:"Status"`        // not required
+	InsDueDate      time.Time    `json:"DueDate"`       //[5]
+	ProgramID       string       `json:"ProgramID"`     //[6]
+	PPRid           string       `json:"PPRID"`         //[7]
+	UploadBatchNo   string       `json:"UploadBatchNo"` //[8]
+	ValueDate       time.Time    `json:"ValueDate"`     //[9]
+}
+
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	indexName := "InstrumentRefNo~SellBusinessID~InsAmount"
+	inst := instrumentInfo{}
+
+	refNoSellIDkey, err := stub.CreateCompositeKey(indexName, []string{inst.InstrumentRefNo, inst.SellBusinessID, inst.InsAmount.String()})
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, "Composite key InstrumentRefNo~SellBusinessID~InsAmount can not be created (instrument)")
+	}
+	value := []byte{0x00}
+	stub.PutState(refNoSellIDkey, value)
+	// the channel and the names of the other chaincodes, if instantiated with them
+	err = common.InitConfig(stub)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// accessPolicy says who may call each function of instrumentcc
+var accessPolicy = auth.Policy{
+	"enterInstrument":         auth.Roles(auth.Maker, auth.Ops),
+	"getInstrument":           auth.Roles(auth.Anyone),
+	"updateInstrumentStatus":  auth.Callers("loancc", "txncc"),
+	"restoreInstrumentStatus": auth.Callers("loancc", "txncc"),
+	"getInstrumentAmt":        auth.Roles(auth.Anyone),
+	"getInstrumentCurrency":   auth.Roles(auth.Anyone),
+	"getInstrumentHistory":    auth.Roles(auth.Auditor, auth.Ops, auth.BankAdmin),
+	"getConfig":               auth.Roles(auth.Anyone),
+}
+
+// argSchemas names the arguments of the functions of instrumentcc that take a JSON
+// object
+var argSchemas = schema.Schemas{
+	"enterInstrument": {
+		{Name: "refNo", Kind: schema.Text},
+		{Name: "instrumentDate", Kind: schema.Date},
+		{Name: "sellerID", Kind: schema.Text},
+		{Name: "buyerID", Kind: schema.Text},
+		{Name: "amount", Kind: schema.Number},
+		{Name: "dueDate", Kind: schema.Date},
+		{Name: "programID", Kind: schema.Text},
+		{Name: "pprID", Kind: schema.Text},
+		{Name: "uploadBatchNo", Kind: schema.Text},
+		{Name: "valueDate", Kind: schema.DateTime},
+		{Name: "currency", Kind: schema.Text, Optional: true},
+	},
+	"updateInstrumentStatus": {
+		{Name: "refNo", Kind: schema.Text},
+		{Name: "sellerID", Kind: schema.Text},
+		{Name: "status", Kind: schema.Text},
+	},
+}
+
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	err := accessPolicy.Check(stub, function)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Unauthorized, err.Error())
+	}
+	args, err = argSchemas.Parse(function, args)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, err.Error())
+	}
+	if function == "enterInstrument" {
+		//Used to enter new instrument data
+		return enterInstrument(stub, args)
+	} else if function == "getInstrument" {
+		//used to retrieve the instrument data
+		return getInstrument(stub, args)
+	} else if function == "updateInstrumentStatus" { //instrumentStatus
+		//Updates instrument status accordingly
+		return updateInstrumentStatus(stub, args)
+	} else if function == "restoreInstrumentStatus" {
+		//Puts back the status of a reversed transaction or a cancelled loan
+		return restoreInstrumentStatus(stub, args)
+	} else if function == "getInstrumentAmt" {
+		return getInstrumentAmt(stub, args)
+	} else if function == "getInstrumentCurrency" {
+		return getInstrumentCurrency(stub, args)
+	} else if function == "getInstrumentHistory" {
+		//Returns every version of the instrument, a page at a time
+		return getInstrumentHistory(stub, args)
+	} else if function == "getConfig" {
+		//Returns the channel and the chaincode names it was instantiated with
+		cfgBytes, err := common.GetConfig(stub)
+		if err != nil {
+			return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+		}
+		return shim.Success(cfgBytes)
+	}
+
+	return errcode.Error("instrumentcc", errcode.InvalidArgument, "No function named "+function+" in Instrumentsssss")
+
+}
+
+func enterInstrument(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 10 && len(args) != 11 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, "Invalid number of arguments in enterInstrument (required:10 or 11) given:"+xLenStr)
+
+	}
+
+	// Checking existence of Instrument Reference No. – Supplier ID pair
+	refNoSellIDiterator, _ := stub.GetStateByPartialCompositeKey("InstrumentRefNo~SellBusinessID~InsAmount", []string{args[0], args[2]})
+	refNoSellIDdata, _ := refNoSellIDiterator.Next()
+	if refNoSellIDdata != nil {
+		return errcode.Error("instrumentcc", errcode.AlreadyExists, "Instrument Reference No. – Supplier ID pair already exists")
+	}
+	defer refNoSellIDiterator.Close()
+
+	//Checking existence of ProgramID
+	exists, err := common.NewProgramClient(stub).Exists(args[6])
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("instrumentcc", errcode.NotFound, "ProgramId "+args[6]+" does not exits")
+	}
+
+	//Checking existence of pprID
+	exists, err = common.NewPPRClient(stub).Exists(args[7])
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("instrumentcc", errcode.NotFound, "PprId "+args[7]+" does not exits")
+	}
+
+	business := common.NewBusinessClient(stub)
+	//Checking existence of SellerBusinessID
+	exists, err = business.Exists(args[2])
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("instrumentcc", errcode.NotFound, "BusinessId "+args[2]+" does not exits")
+	}
+
+	//Checking existence of BuyerBusinessID
+	exists, err = business.Exists(args[3])
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("instrumentcc", errcode.NotFound, "BusinessId "+args[3]+" does not exits")
+	}
+
+	//InstrumentDate -> instDate
+	instDate, err := time.Parse("02/01/2006", args[1])
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, err.Error())
+	}
+
+	//args[10] -> currency of the instrument, INR when not given
+	currency := money.DefaultCurrency
+	if len(args) == 11 && args[10] != "" {
+		currency = strings.ToUpper(args[10])
+	}
+	insAmt, err := money.Parse(args[4], currency)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, err.Error())
+	}
+
+	//InsDueDate -> insDate
+	insDueDate, err := time.Parse("02/01/2006", args[5])
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, err.Error())
+	}
+	// a due date off a business day moves by the calendar and rule of the program
+	insDueDate, err = common.NewProgramClient(stub).AdjustDueDate(args[6], insDueDate)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	}
+	//Converting the incoming date from Dd/mm/yy:hh:mm:ss to Dd/mm/yyThh:mm:ss for parsing
+	vString := args[9][:10] + "T" + args[9][11:] //removing the ":" part from the string
+
+	//ValueDate -> vDate
+	vDate, err := time.Parse("02/01/2006T15:04:05", vString)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, "error in parsing the date and time (instrument)"+err.Error())
+	}
+
+	// Hashing for key to store in ledger
+	hash := sha256.New()
+	instID := strings.ToLower(args[0] + args[2])
+	hash.Write([]byte(instID))
+	md := hash.Sum(nil)
+	instIDsha := hex.EncodeToString(md)
+
+	inst := instrumentInfo{args[0], instDate, args[2], args[3], insAmt, "open", insDueDate, args[6], args[7], args[8], vDate}
+	instBytes, err := json.Marshal(inst)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	}
+	history.Put(stub, instIDsha, instBytes)
+
+	return shim.Success([]byte("Successfully added instrument to the ledger"))
+}
+
+func updateInstrumentStatus(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+		args[0] -> instrument reference number
+		args[1] -> seller ID
+		args[2] -> status
+	*/
+	key := strings.ToLower(args[0] + args[1])
+	hash := sha256.New()
+	instID := strings.ToLower(key)
+	hash.Write([]byte(instID))
+	md := hash.Sum(nil)
+	instIDsha := hex.EncodeToString(md)
+
+	instBytes, err := stub.GetState(instIDsha)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, "Unable to fetch instrument info for status updation")
+	}
+	inst := instrumentInfo{}
+	err = json.Unmarshal(instBytes, &inst)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, "Error in unmarshaling the instrument (updateInsStatus)")
+	}
+	/*
+	 updated sequentially Open > Sanctioned > Overdue>Settled or Open > Sanctioned > Settled,
+	 with Disbursed between Sanctioned and Overdue once the loan is disbursed, and
+	 Closed after Settled, Disbursed or Overdue once the loan is closed
+	*/
+	if (args[2] == "sanctioned") && (inst.InsStatus != "open") {
+		return errcode.Error("instrumentcc", errcode.InvalidState, "Instrument status cannot be sanctioned as it is not open")
+	} else if (args[2] == "overdue") && ((inst.InsStatus != "sanctioned") && (inst.InsStatus != "disbursed")) {
+		return errcode.Error("instrumentcc", errcode.InvalidState, "Instrument status cannot be overdue as it is not sanctioned or disbursed")
+	} else if (args[2] == "settled") && ((inst.InsStatus != "overdue") && (inst.InsStatus != "sanctioned") && (inst.InsStatus != "disbursed")) {
+		return errcode.Error("instrumentcc", errcode.InvalidState, "Instrument status cannot be settled as it is not overdue, sanctioned or disbursed")
+	} else if (args[2] == "closed") && ((inst.InsStatus != "settled") && (inst.InsStatus != "disbursed") && (inst.InsStatus != "overdue")) {
+		return errcode.Error("instrumentcc", errcode.InvalidState, "Instrument status cannot be closed as it is not settled, disbursed or overdue")
+	}
+	prevStatus := inst.InsStatus
+	inst.InsStatus = args[2]
+	instBytes, _ = json.Marshal(inst)
+	history.Put(stub, instIDsha, instBytes)
+
+	err = events.Emit(stub, "instrumentcc", events.New(events.InstrumentStatusChanged, events.StatusChange{ID: args[0], SellerID: args[1], From: prevStatus, To: args[2]}))
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte("Instrument status updated successfully"))
+
+}
+
+func restoreInstrumentStatus(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+		args[0] -> instrument reference number
+		args[1] -> seller ID
+		args[2] -> status the reversed transaction left behind
+		args[3] -> status to restore
+	*/
+	if len(args) != 4 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, "Invalid number of arguments in restoreInstrumentStatus (required:4) given: "+xLenStr)
+	}
+	key := strings.ToLower(args[0] + args[1])
+	hash := sha256.New()
+	hash.Write([]byte(key))
+	instIDsha := hex.EncodeToString(hash.Sum(nil))
+
+	instBytes, err := stub.GetState(instIDsha)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, "Unable to fetch instrument info for status restore")
+	} else if instBytes == nil {
+		return errcode.Error("instrumentcc", errcode.NotFound, "No instrument "+args[0]+" for seller "+args[1])
+	}
+	inst := instrumentInfo{}
+	err = json.Unmarshal(instBytes, &inst)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, "Error in unmarshaling the instrument (restoreInsStatus)")
+	}
+	// Only the status written by the reversed transaction can be put back
+	if inst.InsStatus != args[2] {
+		return errcode.Error("instrumentcc", errcode.InvalidState, "Instrument status is "+inst.InsStatus+", not "+args[2]+", cannot restore it to "+args[3])
+	}
+	inst.InsStatus = args[3]
+	instBytes, _ = json.Marshal(inst)
+	err = history.Put(stub, instIDsha, instBytes)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	}
+	err = events.Emit(stub, "instrumentcc", events.New(events.InstrumentStatusChanged, events.StatusChange{ID: args[0], SellerID: args[1], From: args[2], To: args[3]}))
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte("Instrument status restored successfully"))
+}
+
+func getInstrument(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, "Invalid number of arguments in getInstrument (required:2) given:"+xLenStr)
+
+	}
+	/*
+		args[0] -> InstrumentRefNo
+		args[1] -> SellBusinessID
+	*/
+	hash := sha256.New()
+	instID := strings.ToLower(args[0] + args[1])
+	hash.Write([]byte(instID))
+	md := hash.Sum(nil)
+	instIDsha := hex.EncodeToString(md)
+
+	insBytes, err := stub.GetState(instIDsha)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	} else if insBytes == nil {
+		return errcode.Error("instrumentcc", errcode.NotFound, "No data exists on this InstrumentID: "+args[0])
+	}
+
+	//ins := instrumentInfo{}
+	//err = json.Unmarshal(insBytes, &ins)
+	//insString := fmt.Sprintf("%+v", ins)
+	return shim.Success(nil)
+}
+
+func getInstrumentAmt(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, "invalid no. of arguments (requiered 2)")
+	}
+
+	/*fmt.Println("before refNoSellIDiterator")
+	refNoSellIDiterator, err := stub.GetStateByPartialCompositeKey("InstrumentRefNo~SellBusinessID~InsAmount", args)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	}
+	fmt.Println("before refNoSellIDiterator")
+	refNoSellIDdata, err := refNoSellIDiterator.Next()
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	}
+	//fmt.Println(refNoSellIDdata.Key)
+	_, insAmt, err := stub.SplitCompositeKey(refNoSellIDdata.Key)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, "Error splitting the composite key " + err.Error())
+	}
+	fmt.Println(insAmt)
+	*/
+	hash := sha256.New()
+	instID := strings.ToLower(args[0] + args[1])
+	hash.Write([]byte(instID))
+	md := hash.Sum(nil)
+	instIDsha := hex.EncodeToString(md)
+
+	insBytes, err := stub.GetState(instIDsha)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	} else if insBytes == nil {
+		return errcode.Error("instrumentcc", errcode.NotFound, "No data exists on this InstrumentID: "+instIDsha)
+	}
+
+	ins := instrumentInfo{}
+	err = json.Unmarshal(insBytes, &ins)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, "Cannot unmarshal json")
+	}
+
+	return shim.Success([]byte(ins.InsAmount.String()))
+}
+
+func getInstrumentCurrency(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, "invalid no. of arguments (requiered 2)")
+	}
+
+	/*
+		args[0] -> InstrumentRefNo
+		args[1] -> SellBusinessID
+	*/
+	hash := sha256.New()
+	instID := strings.ToLower(args[0] + args[1])
+	hash.Write([]byte(instID))
+	md := hash.Sum(nil)
+	instIDsha := hex.EncodeToString(md)
+
+	insBytes, err := stub.GetState(instIDsha)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	} else if insBytes == nil {
+		return errcode.Error("instrumentcc", errcode.NotFound, "No data exists on this InstrumentID: "+instIDsha)
+	}
+
+	ins := instrumentInfo{}
+	err = json.Unmarshal(insBytes, &ins)
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, "Cannot unmarshal json")
+	}
+
+	return shim.Success([]byte(ins.InsAmount.Currency))
+}
+
+func getInstrumentHistory(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> InstrumentRefNo
+	 *args[1] -> SellBusinessID
+	 *args[2] -> page size, 20 when not given (at most 100)
+	 *args[3] -> bookmark of the previous page, for the next one
+	 */
+	if len(args) < 2 || len(args) > 4 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("instrumentcc", errcode.InvalidArgument, "Invalid number of arguments in getInstrumentHistory (required:2 to 4) given: "+xLenStr)
+	}
+	hash := sha256.New()
+	hash.Write([]byte(strings.ToLower(args[0] + args[1])))
+	instIDsha := hex.EncodeToString(hash.Sum(nil))
+
+	page, err := history.Get(stub, instIDsha, args[2:])
+	if err != nil {
+		return errcode.Error("instrumentcc", errcode.Internal, err.Error())
+	}
+	pageBytes, _ := json.Marshal(page)
+	return shim.Success(pageBytes)
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+
+	instrumentcc "github.com/chaincode/Instrument/instrumentcc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() {
+	err := shim.Start(new(instrumentcc.Chaincode))
+	if err != nil {
+		fmt.Printf("instrumetcc: "+"Error starting Instrument chaincode: %s\n", err)
+	}
+}
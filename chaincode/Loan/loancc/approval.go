@@ -0,0 +1,112 @@
+package loancc
+
+import (
+	"encoding/json"
+	"strconv"
+
+	approval "github.com/chaincode/Approval"
+	errcode "github.com/chaincode/Errcode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// A loan is sanctioned in two steps: a maker submits the arguments of
+// newLoanInfo as a pending request and a checker approves it, which is when
+// newLoanInfo runs
+
+func submitLoan(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 14 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in submitLoan (required:14) given: "+xLenStr)
+	}
+	response := loanIDexists(stub, args[0])
+	if response.Status != shim.OK {
+		return errcode.Error("loancc", errcode.AlreadyExists, response.Message)
+	}
+
+	req, err := approval.Submit(stub, "newLoanInfo", args)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte(req.RequestID))
+}
+
+func approveLoan(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> RequestID
+	 */
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in approveLoan (required:1) given: "+xLenStr)
+	}
+
+	req, err := approval.Approve(stub, "newLoanInfo", args[0])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	response := newLoanInfo(stub, req.Args)
+	if response.Status != shim.OK {
+		return response
+	}
+	req.Result = req.Args[0]
+	err = approval.Put(stub, req)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte(req.Args[0]))
+}
+
+func rejectLoan(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> RequestID
+	 *args[1] -> Reason
+	 */
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in rejectLoan (required:2) given: "+xLenStr)
+	}
+
+	_, err := approval.Reject(stub, "newLoanInfo", args[0], args[1])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+func getLoanRequest(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in getLoanRequest (required:1) given: "+xLenStr)
+	}
+
+	req, err := approval.Get(stub, "newLoanInfo", args[0])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	reqBytes, _ := json.Marshal(req)
+	return shim.Success(reqBytes)
+}
+
+func listLoanRequests(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> Status (optional: pending, approved or rejected)
+	 */
+	if len(args) > 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in listLoanRequests (required:0 or 1) given: "+xLenStr)
+	}
+	status := ""
+	if len(args) == 1 {
+		status = args[0]
+	}
+
+	reqs, err := approval.List(stub, "newLoanInfo", status)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	reqsBytes, _ := json.Marshal(reqs)
+	return shim.Success(reqsBytes)
+}
@@ -0,0 +1,341 @@
+package loancc
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	clock "github.com/chaincode/Clock"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	events "github.com/chaincode/Events"
+	history "github.com/chaincode/History"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Loan statuses
+const (
+	statusSanctioned    = "sanctioned"
+	statusPartDisbursed = "part_disbursed"
+	statusDisbursed     = "disbursed"
+	statusOverdue       = "overdue"
+	statusPartCollected = "part_collected"
+	statusCollected     = "collected"
+	statusWrittenOff    = "written_off"
+	statusClosed        = "closed"
+	statusCancelled     = "cancelled"
+)
+
+// legacyStatuses are the statuses loans were written with before the state
+// machine, as they are now
+var legacyStatuses = map[string]string{
+	"part disbursed": statusPartDisbursed,
+	"part collected": statusPartCollected,
+}
+
+// accruingStatuses are those of the loans that earn interest: disbursed and
+// not yet repaid or written off
+var accruingStatuses = []string{statusPartDisbursed, statusDisbursed, statusOverdue, statusPartCollected}
+
+// transition is a move a loan can make to another status. Posted moves are
+// made by txncc for the txn that moved the money, the others by a checker
+// with changeLoanStatus. The guard, if any, says why the move cannot be made
+// now.
+type transition struct {
+	To     string `json:"to"`
+	Posted bool   `json:"posted"`
+	guard  func(stub shim.ChaincodeStubInterface, loan loanInfo) error
+}
+
+// transitions are the moves allowed from each status. Closed and cancelled
+// loans make no more.
+var transitions = map[string][]transition{
+	statusSanctioned: {
+		{To: statusPartDisbursed, Posted: true},
+		{To: statusDisbursed, Posted: true},
+		{To: statusCancelled},
+	},
+	statusPartDisbursed: {
+		{To: statusPartDisbursed, Posted: true},
+		{To: statusDisbursed, Posted: true},
+		{To: statusPartCollected, Posted: true},
+		{To: statusCollected, Posted: true},
+		{To: statusOverdue, guard: pastDue},
+		{To: statusWrittenOff},
+	},
+	statusDisbursed: {
+		{To: statusPartCollected, Posted: true},
+		{To: statusCollected, Posted: true},
+		{To: statusOverdue, guard: pastDue},
+		{To: statusWrittenOff},
+	},
+	statusOverdue: {
+		{To: statusPartCollected, Posted: true},
+		{To: statusCollected, Posted: true},
+		{To: statusWrittenOff},
+	},
+	statusPartCollected: {
+		{To: statusPartCollected, Posted: true},
+		{To: statusCollected, Posted: true},
+		{To: statusOverdue, guard: pastDue},
+		{To: statusWrittenOff},
+	},
+	statusCollected: {
+		{To: statusClosed, guard: settled},
+	},
+	statusWrittenOff: {
+		{To: statusClosed},
+	},
+}
+
+// statusChange is a transition a loan made, and why
+type statusChange struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason"`
+	TxID      string    `json:"txID"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// pastDue allows a loan to become overdue once its due date has passed
+func pastDue(stub shim.ChaincodeStubInterface, loan loanInfo) error {
+	today, err := clock.PostingDate(stub)
+	if err != nil {
+		return err
+	}
+	if !today.After(loan.DueDate) {
+		return errcode.New(errcode.InvalidState, "Loan is due on "+loan.DueDate.Format(clock.DateLayout)+", it is not overdue on "+today.Format(clock.DateLayout))
+	}
+	return nil
+}
+
+// settled allows closing a loan once its wallets are empty
+func settled(stub shim.ChaincodeStubInterface, loan loanInfo) error {
+	wallet := common.NewWalletClient(stub)
+	for walletType, walletID := range map[string]string{"disbursed": loan.LoanDisbursedWalletID, "charges": loan.LoanChargesWalletID, "accrued": loan.LoanAccruedInterestWalletID} {
+		bal, err := wallet.Balance(walletID)
+		if err != nil {
+			return err
+		}
+		if !bal.IsZero() {
+			return errcode.New(errcode.InvalidState, "Loan is not settled, its "+walletType+" wallet holds "+bal.String())
+		}
+	}
+	return nil
+}
+
+// loanStatus returns the status of the loan, as the state machine names it
+func loanStatus(loan loanInfo) string {
+	if status, ok := legacyStatuses[loan.LoanStatus]; ok {
+		return status
+	}
+	return loan.LoanStatus
+}
+
+// instrumentStatuses are the statuses of the instrument of a loan in each
+// status. A written-off loan leaves its instrument as it was.
+var instrumentStatuses = map[string]string{
+	statusSanctioned:    "sanctioned",
+	statusPartDisbursed: "disbursed",
+	statusDisbursed:     "disbursed",
+	statusOverdue:       "overdue",
+	statusPartCollected: "disbursed",
+	statusCollected:     "settled",
+	statusClosed:        "closed",
+	statusCancelled:     "open",
+}
+
+// instrumentStatus returns the status of the instrument of the loan when the
+// loan is in the status
+func instrumentStatus(loan loanInfo, status string) string {
+	if status != statusWrittenOff {
+		return instrumentStatuses[status]
+	}
+	for i := len(loan.Transitions) - 1; i >= 0; i-- {
+		if loan.Transitions[i].To == statusWrittenOff {
+			return instrumentStatuses[loan.Transitions[i].From]
+		}
+	}
+	return ""
+}
+
+// moveInstrument moves the instrument of the loan along with its move from
+// one status to another, and returns the event of the change, if any. A
+// restored instrument is only moved back from the status the loan left it in.
+func moveInstrument(stub shim.ChaincodeStubInterface, loan loanInfo, from string, to string, restore bool) ([]events.Event, error) {
+	fromIns, toIns := instrumentStatus(loan, from), instrumentStatus(loan, to)
+	if fromIns == toIns || fromIns == "" || toIns == "" {
+		return nil, nil
+	}
+	var err error
+	if restore {
+		err = common.NewInstrumentClient(stub).RestoreStatus(loan.InstNum, loan.SellerBusinessID, fromIns, toIns)
+	} else {
+		err = common.NewInstrumentClient(stub).UpdateStatus(loan.InstNum, loan.SellerBusinessID, toIns)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []events.Event{events.New(events.InstrumentStatusChanged, events.StatusChange{ID: loan.InstNum, SellerID: loan.SellerBusinessID, From: fromIns, To: toIns})}, nil
+}
+
+// findTransition returns the move of the table from one status to another
+func findTransition(from string, to string) (transition, bool) {
+	for _, t := range transitions[from] {
+		if t.To == to {
+			return t, true
+		}
+	}
+	return transition{}, false
+}
+
+// moveLoan makes the transition of the loan to the status if the table
+// allows it, and records it with the reason. The caller writes the loan.
+func moveLoan(stub shim.ChaincodeStubInterface, loan *loanInfo, to string, reason string, posted bool) error {
+	from := loanStatus(*loan)
+	if reason == "" {
+		return errcode.New(errcode.InvalidArgument, "A reason is required to move the loan to "+to)
+	}
+	t, ok := findTransition(from, to)
+	if !ok {
+		return errcode.New(errcode.InvalidState, "Loan cannot move from "+from+" to "+to)
+	} else if t.Posted && !posted {
+		return errcode.New(errcode.InvalidState, "Loan moves to "+to+" only when a txn is posted")
+	} else if !t.Posted && posted {
+		return errcode.New(errcode.InvalidState, "Loan does not move to "+to+" when a txn is posted")
+	}
+	if t.guard != nil {
+		err := t.guard(stub, *loan)
+		if err != nil {
+			return err
+		}
+	}
+	return recordStatus(stub, loan, from, to, reason)
+}
+
+// recordStatus sets the status of the loan and adds the change to its
+// transitions
+func recordStatus(stub shim.ChaincodeStubInterface, loan *loanInfo, from string, to string, reason string) error {
+	now, err := clock.Now(stub)
+	if err != nil {
+		return err
+	}
+	loan.LoanStatus = to
+	loan.Transitions = append(loan.Transitions, statusChange{from, to, reason, stub.GetTxID(), now})
+	return nil
+}
+
+func changeLoanStatus(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> LoanID
+	 *args[1] -> Status to move the loan to (overdue, written_off, closed or cancelled)
+	 *args[2] -> Reason
+	 */
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in changeLoanStatus (required:3) given: "+xLenStr)
+	}
+	loan, err := getLoan(stub, args[0])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	from := loanStatus(loan)
+	err = moveLoan(stub, &loan, args[1], args[2], false)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	loanBytes, _ := json.Marshal(loan)
+	err = history.Put(stub, args[0], loanBytes)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, "Error in loan status updation "+err.Error())
+	}
+
+	evs := []events.Event{events.New(events.LoanStatusChanged, events.StatusChange{ID: args[0], From: from, To: args[1]})}
+
+	// A cancelled loan frees the instrument to be financed again, an overdue
+	// loan's instrument is overdue too and a closed loan closes it
+	insEvs, err := moveInstrument(stub, loan, from, args[1], args[1] == statusCancelled)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	evs = append(evs, insEvs...)
+	err = events.Emit(stub, "loancc", evs...)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte("Loan status changed to " + args[1]))
+}
+
+func markLoanOverdue(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> LoanID
+	 *args[1] -> Reason
+	 */
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in markLoanOverdue (required:2) given: "+xLenStr)
+	}
+	return changeLoanStatus(stub, []string{args[0], statusOverdue, args[1]})
+}
+
+func getAllowedTransitions(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> LoanID
+	 */
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in getAllowedTransitions (required:1) given: "+xLenStr)
+	}
+	loan, err := getLoan(stub, args[0])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+
+	// the moves whose guards pass now
+	allowed := []transition{}
+	for _, t := range transitions[loanStatus(loan)] {
+		if t.guard == nil || t.guard(stub, loan) == nil {
+			allowed = append(allowed, t)
+		}
+	}
+	allowedBytes, _ := json.Marshal(allowed)
+	return shim.Success(allowedBytes)
+}
+
+func getLoanTransitions(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> LoanID
+	 */
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in getLoanTransitions (required:1) given: "+xLenStr)
+	}
+	loan, err := getLoan(stub, args[0])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	changes := loan.Transitions
+	if changes == nil {
+		changes = []statusChange{}
+	}
+	changesBytes, _ := json.Marshal(changes)
+	return shim.Success(changesBytes)
+}
+
+// getLoan reads the loan
+func getLoan(stub shim.ChaincodeStubInterface, loanID string) (loanInfo, error) {
+	loan := loanInfo{}
+	loanBytes, err := stub.GetState(loanID)
+	if err != nil {
+		return loan, err
+	} else if loanBytes == nil {
+		return loan, errcode.New(errcode.NotFound, "No data exists on this loanID: "+loanID)
+	}
+	err = json.Unmarshal(loanBytes, &loan)
+	return loan, err
+}
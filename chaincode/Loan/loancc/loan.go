@@ -0,0 +1,678 @@
+package loancc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	auth "github.com/chaincode/Auth"
+	clock "github.com/chaincode/Clock"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	events "github.com/chaincode/Events"
+	history "github.com/chaincode/History"
+	money "github.com/chaincode/Money"
+	schema "github.com/chaincode/Schema"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Chaincode is the loancc chaincode
+type Chaincode struct {
+}
+
+type loanInfo struct {
+	InstNum                     string         `json:"InstrumentNo"`          //[1]//Instrument Number
+	ExposureBusinessID          string         `json:"ExposureBusinessID"`    //[2]//buyer for now
+	ProgramID                   string         `json:"ProgramID"`             //[3]
+	SanctionAmt                 money.Amount   `json:"SanctionAmt"`           //[4]
+	SanctionDate                time.Time      `json:"SanctionDate"`          //auto generated as created, from the txn timestamp
+	SanctionAuthority           string         `json:"SanctionAuthority"`     //[5]
+	ROI                         money.Rate     `json:"ROI"`                   //[6]
+	DueDate                     time.Time      `json:"DueDate"`               //[7]
+	ValueDate                   time.Time      `json:"ValueDate"`             //[8]//with time
+	PostingDate                 time.Time      `json:"PostingDate"`           //business date of the sanction
+	LoanStatus                  string         `json:"LoanStatus"`            //[]
+	LoanDisbursedWalletID       string         `json:"DisbursementWallet"`    //[9]
+	LoanChargesWalletID         string         `json:"ChargesWallet"`         //[10]
+	LoanAccruedInterestWalletID string         `json:"AccruedInterestWallet"` //[11]
+	BuyerBusinessID             string         `json:"BuyerID"`               //[12]
+	SellerBusinessID            string         `json:"SellerID"`              //[13]
+	DocType                     string         `json:"docType"`               //loanDocType, to query loans by
+	Transitions                 []statusChange `json:"Transitions"`           //every status the loan took, and why
+}
+
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	// the channel and the names of the other chaincodes, if instantiated with them
+	err := common.InitConfig(stub)
+	if err != nil {
+		return errcode.Error("loancc", errcode.InvalidArgument, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// accessPolicy says who may call each function of loancc
+var accessPolicy = auth.Policy{
+	"submitLoan":            auth.Roles(auth.Maker),
+	"approveLoan":           auth.Roles(auth.Checker),
+	"rejectLoan":            auth.Roles(auth.Checker),
+	"getLoanRequest":        auth.Roles(auth.Anyone),
+	"listLoanRequests":      auth.Roles(auth.Anyone),
+	"getLoanInfo":           auth.Roles(auth.Anyone),
+	"updateLoanInfo":        auth.Callers("txncc"),
+	"loanIDexists":          auth.Roles(auth.Anyone),
+	"getLoanStatus":         auth.Roles(auth.Anyone),
+	"getLoanSancAmt":        auth.Roles(auth.Anyone),
+	"getWalletID":           auth.Roles(auth.Anyone),
+	"getSellerID":           auth.Roles(auth.Anyone),
+	"getBuyerID":            auth.Roles(auth.Anyone),
+	"getProgramID":          auth.Roles(auth.Anyone),
+	"restoreLoanStatus":     auth.Callers("txncc"),
+	"changeLoanStatus":      auth.Roles(auth.Checker, auth.BankAdmin),
+	"markLoanOverdue":       auth.Callers("txncc"),
+	"getAllowedTransitions": auth.Roles(auth.Anyone),
+	"getLoanTransitions":    auth.Roles(auth.Anyone),
+	"getLoanHistory":        auth.Roles(auth.Auditor, auth.Ops, auth.BankAdmin),
+	"queryLoans":            auth.Roles(auth.Anyone),
+	"getLoansByStatus":      auth.Roles(auth.Anyone),
+	"getLoansByBuyer":       auth.Roles(auth.Anyone),
+	"getLoansBySeller":      auth.Roles(auth.Anyone),
+	"getLoansByProgram":     auth.Roles(auth.Anyone),
+	"getLoansByDueDate":     auth.Roles(auth.Anyone),
+	"listAccruingLoans":     auth.Callers("txncc"),
+	"getConfig":             auth.Roles(auth.Anyone),
+}
+
+// argSchemas names the arguments of the functions of loancc that take a JSON
+// object
+var argSchemas = schema.Schemas{
+	"submitLoan": {
+		{Name: "loanID", Kind: schema.Text},
+		{Name: "instrumentID", Kind: schema.Text},
+		{Name: "exposureBusinessID", Kind: schema.Text},
+		{Name: "programID", Kind: schema.Text},
+		{Name: "sanctionAmt", Kind: schema.Number},
+		{Name: "sanctionAuthority", Kind: schema.Text},
+		{Name: "roi", Kind: schema.Number},
+		{Name: "dueDate", Kind: schema.Date},
+		{Name: "valueDate", Kind: schema.DateTime},
+		{Name: "disbursedWalletAmt", Kind: schema.Number},
+		{Name: "chargesWalletAmt", Kind: schema.Number},
+		{Name: "accruedWalletAmt", Kind: schema.Number},
+		{Name: "buyerID", Kind: schema.Text},
+		{Name: "sellerID", Kind: schema.Text},
+	},
+	"updateLoanInfo": {
+		{Name: "loanID", Kind: schema.Text},
+		{Name: "status", Kind: schema.Text},
+		{Name: "txnType", Kind: schema.Text},
+	},
+}
+
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	err := accessPolicy.Check(stub, function)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Unauthorized, err.Error())
+	}
+	args, err = argSchemas.Parse(function, args)
+	if err != nil {
+		return errcode.Error("loancc", errcode.InvalidArgument, err.Error())
+	}
+
+	if function == "submitLoan" {
+		//Submits a new Loan Data for approval
+		return submitLoan(stub, args)
+	} else if function == "approveLoan" {
+		//Creates the new Loan Data of an approved request
+		return approveLoan(stub, args)
+	} else if function == "rejectLoan" {
+		//Rejects a new Loan Data request
+		return rejectLoan(stub, args)
+	} else if function == "getLoanRequest" {
+		//Retrieves a new Loan Data request
+		return getLoanRequest(stub, args)
+	} else if function == "listLoanRequests" {
+		//Lists the new Loan Data requests
+		return listLoanRequests(stub, args)
+	} else if function == "getLoanInfo" {
+		//Retrieves the existing data
+		return getLoanInfo(stub, args)
+	} else if function == "updateLoanInfo" {
+		//Updates variables for loan structure
+		return updateLoanInfo(stub, args)
+	} else if function == "loanIDexists" {
+		//Checks the existence of loan ID
+		return loanIDexists(stub, args[0])
+	} else if function == "getLoanStatus" {
+		//Returns the Loan status
+		return getLoanStatus(stub, args[0])
+	} else if function == "getLoanSancAmt" {
+		//Returns the Sanc Amt
+		return getLoanSancAmt(stub, args[0])
+	} else if function == "getWalletID" {
+		//Returns the walletID for the required wallet type
+		return getWalletID(stub, args)
+	} else if function == "getSellerID" {
+		//Returns the Seller Id
+		return getSellerID(stub, args[0])
+	} else if function == "getBuyerID" {
+		return getBuyerID(stub, args[0])
+	} else if function == "getProgramID" {
+		//Returns the program of the loan
+		return getProgramID(stub, args[0])
+	} else if function == "restoreLoanStatus" {
+		//Puts back the status of a reversed transaction
+		return restoreLoanStatus(stub, args)
+	} else if function == "listAccruingLoans" {
+		//Returns every loan that earns interest, for the accrual run
+		return listAccruingLoans(stub, args)
+	} else if function == "changeLoanStatus" {
+		//Moves the loan to a status no txn moves it to, with the reason
+		return changeLoanStatus(stub, args)
+	} else if function == "markLoanOverdue" {
+		//Moves the loan past its due date to overdue, for the EOD run
+		return markLoanOverdue(stub, args)
+	} else if function == "getAllowedTransitions" {
+		//Returns the statuses the loan can move to now
+		return getAllowedTransitions(stub, args)
+	} else if function == "getLoanTransitions" {
+		//Returns every status the loan took, and why
+		return getLoanTransitions(stub, args)
+	} else if function == "getLoanHistory" {
+		//Returns every version of the loan, a page at a time
+		return getLoanHistory(stub, args)
+	} else if function == "queryLoans" {
+		//Returns the loans that match a CouchDB selector, a page at a time
+		return queryLoans(stub, args)
+	} else if function == "getLoansByStatus" {
+		return getLoansBy(stub, "getLoansByStatus", "LoanStatus", args)
+	} else if function == "getLoansByBuyer" {
+		return getLoansBy(stub, "getLoansByBuyer", "BuyerID", args)
+	} else if function == "getLoansBySeller" {
+		return getLoansBy(stub, "getLoansBySeller", "SellerID", args)
+	} else if function == "getLoansByProgram" {
+		return getLoansBy(stub, "getLoansByProgram", "ProgramID", args)
+	} else if function == "getLoansByDueDate" {
+		//Returns the loans due in a range of dates, soonest first
+		return getLoansByDueDate(stub, args)
+	} else if function == "getConfig" {
+		//Returns the channel and the chaincode names it was instantiated with
+		cfgBytes, err := common.GetConfig(stub)
+		if err != nil {
+			return errcode.Error("loancc", errcode.Internal, err.Error())
+		}
+		return shim.Success(cfgBytes)
+	}
+	return errcode.Error("loancc", errcode.InvalidArgument, "No function named "+function+" in Loanssssssssssss")
+}
+
+func newLoanInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	if len(args) != 14 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in newLoanInfo(loan) (required:14) given: "+xLenStr)
+	}
+
+	//Checking existence of loanID
+	println("Checking existence of loanID")
+	response := loanIDexists(stub, args[0])
+	if response.Status != shim.OK {
+		return errcode.Error("loancc", errcode.AlreadyExists, response.Message)
+	}
+
+	//Checking existence of ExposureBusinessID
+	println("Checking existence of ExposureBusinessID")
+	business := common.NewBusinessClient(stub)
+	exists, err := business.Exists(args[2])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("loancc", errcode.NotFound, "ExposureBusinessID "+args[2]+" does not exits")
+	}
+
+	//Checking if Instrument ID is Instrument Ref. No.
+	println("Checking if Instrument ID is Instrument Ref. No.")
+	instrument := common.NewInstrumentClient(stub)
+	exists, err = instrument.Exists(args[1], args[13])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("loancc", errcode.NotFound, "Instrument refrence no "+args[1]+" does not exits")
+	}
+
+	// getting the sanction amount from the instrument
+	instAmtStr, err := instrument.Amount(args[1], args[13])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	fmt.Println("instAmtStr: " + instAmtStr)
+
+	// the loan and its wallets are in the currency of the instrument
+	currency, err := instrument.Currency(args[1], args[13])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	instAmt, err := money.Parse(instAmtStr, currency)
+	if err != nil {
+		return errcode.Error("loancc", errcode.InvalidArgument, "Unable to parse instAmt(loan): "+err.Error())
+	}
+
+	//Getting the discount percentage
+	println("Getting the discount percentage")
+	discount, err := common.Call(stub, "pprcc", "getDiscountPercentage", args[3], args[13])
+	if err != nil {
+		return errcode.Error("loancc", errcode.NotFound, "No PPR of seller "+args[13]+" in program "+args[3]+": "+err.Error())
+	}
+
+	discountPercent, err := money.ParseRate(string(discount))
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, "Unable to parse the discount percentage: "+err.Error())
+	}
+	amt, _ := instAmt.Sub(instAmt.Percent(discountPercent, money.HalfUp))
+
+	//SanctionAmt -> sAmt
+	println("SanctionAmt -> sAmt")
+	sAmt, err := money.Parse(args[4], instAmt.Currency)
+	if err != nil {
+		return errcode.Error("loancc", errcode.InvalidArgument, err.Error())
+	}
+
+	if c, _ := sAmt.Cmp(amt); c > 0 || sAmt.IsZero() {
+		return errcode.Error("loancc", errcode.InvalidArgument, "Sanction amount exceeds the required value or it is zero : "+args[4])
+	}
+
+	//SanctionDate ->sDate
+	println("SanctionDate ->sDate")
+	sDate, err := clock.Now(stub)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	pDate := clock.Date(sDate)
+
+	roi, err := money.ParseRate(args[6])
+	if err != nil {
+		return errcode.Error("loancc", errcode.InvalidArgument, err.Error())
+	}
+
+	//Parsing into date for storage but hh:mm:ss will also be stored as
+	println("Parsing into date for storage")
+	//00:00:00 .000Z with the date
+	//DueDate -> dDate
+	dDate, err := time.Parse("02/01/2006", args[7])
+	if err != nil {
+		return errcode.Error("loancc", errcode.InvalidArgument, err.Error())
+	}
+	// a due date off a business day moves by the calendar and rule of the program
+	dDate, err = common.NewProgramClient(stub).AdjustDueDate(args[3], dDate)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+
+	//Converting the incoming date from Dd/mm/yy:hh:mm:ss to Dd/mm/yyThh:mm:ss for parsing
+	println("Converting the incoming date from Dd/mm/yy:hh:mm:ss to Dd/mm/yyThh:mm:ss for parsing")
+	vDateStr := args[8][:10]
+	vTime := args[8][11:]
+	vStr := vDateStr + "T" + vTime
+
+	//ValueDate ->vDate
+	println("ValueDate ->vDate")
+	vDate, err := time.Parse("02/01/2006T15:04:05", vStr)
+	if err != nil {
+		return errcode.Error("loancc", errcode.InvalidArgument, err.Error())
+	}
+
+	hash := sha256.New()
+	println("Hashing wallets")
+	// Hashing LoanDisbursedWalletID
+	LoanDisbursedWalletStr := args[9] + "LoanDisbursedWallet"
+	hash.Write([]byte(LoanDisbursedWalletStr))
+	md := hash.Sum(nil)
+	LoanDisbursedWalletIDsha := hex.EncodeToString(md)
+	createWallet(stub, LoanDisbursedWalletIDsha, args[9], currency)
+
+	// Hashing LoanChargesWalletID
+	LoanChargesWalletStr := args[10] + "LoanChargesWallet"
+	hash.Write([]byte(LoanChargesWalletStr))
+	md = hash.Sum(nil)
+	LoanChargesWalletIDsha := hex.EncodeToString(md)
+	createWallet(stub, LoanChargesWalletIDsha, args[10], currency)
+
+	// Hashing LoanAccruedInterestWalletID
+	LoanAccruedInterestWalletStr := args[11] + "LoanAccruedInterestWallet"
+	hash.Write([]byte(LoanAccruedInterestWalletStr))
+	md = hash.Sum(nil)
+	LoanAccruedInterestWalletIDsha := hex.EncodeToString(md)
+	createWallet(stub, LoanAccruedInterestWalletIDsha, args[11], currency)
+
+	//Checking existence of BuyerBusinessID
+	println("Checking existence of BuyerBusinessID")
+	exists, err = business.Exists(args[13])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("loancc", errcode.NotFound, "BuyerBusinessID "+args[13]+" does not exits")
+	}
+
+	//Checking existence of SellerBusinessID
+	println("Checking existence of SellerBusinessID")
+	exists, err = business.Exists(args[13])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("loancc", errcode.NotFound, "SellerBusinessID "+args[13]+" does not exits")
+	}
+
+	println("marshalling loaninfo")
+	sanction := statusChange{To: statusSanctioned, Reason: "sanctioned by " + args[5], TxID: stub.GetTxID(), Timestamp: sDate}
+	loan := loanInfo{args[1], args[2], args[3], sAmt, sDate, args[6], roi, dDate, vDate, pDate, statusSanctioned, LoanDisbursedWalletIDsha, LoanChargesWalletIDsha, LoanAccruedInterestWalletIDsha, args[12], args[13], loanDocType, []statusChange{sanction}}
+	loanBytes, err := json.Marshal(loan)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	history.Put(stub, args[0], loanBytes)
+
+	println("changing inst status")
+	//argsList := []string{args[1], args[13], "sanctioned"}
+	//argsListStr := strings.Join(argsList, ",")
+	err = instrument.UpdateStatus(args[1], args[13], "sanctioned")
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+
+	sanctioned := events.Loan{LoanID: args[0], InstrumentID: loan.InstNum, SellerID: loan.SellerBusinessID, BuyerID: loan.BuyerBusinessID, ProgramID: loan.ProgramID, SanctionAmt: loan.SanctionAmt, ROI: loan.ROI, DueDate: loan.DueDate}
+	err = events.Emit(stub, "loancc",
+		events.New(events.LoanSanctioned, sanctioned),
+		events.New(events.InstrumentStatusChanged, events.StatusChange{ID: args[1], SellerID: args[13], From: "open", To: "sanctioned"}))
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte("Successfully added loan info into ledger"))
+}
+
+func createWallet(stub shim.ChaincodeStubInterface, walletID string, amt string, currency string) pb.Response {
+	err := common.NewWalletClient(stub).NewWallet(walletID, amt, currency)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, "Unable to create new wallet from business")
+	}
+	return shim.Success([]byte("created new wallet from business"))
+}
+
+func loanIDexists(stub shim.ChaincodeStubInterface, loanID string) pb.Response {
+	ifExists, _ := stub.GetState(loanID)
+	if ifExists != nil {
+		fmt.Println(ifExists)
+		return errcode.Error("loancc", errcode.AlreadyExists, "LoanId "+loanID+" exits. Cannot create new ID")
+	}
+	return shim.Success(nil)
+}
+
+func getLoanStatus(stub shim.ChaincodeStubInterface, loanID string) pb.Response {
+	fmt.Println("loancc: inside getLoanStatus")
+	loanBytes, err := stub.GetState(loanID)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if loanBytes == nil {
+		return errcode.Error("loancc", errcode.NotFound, "No data exists on this loanID: "+loanID)
+	}
+
+	loan := loanInfo{}
+	err = json.Unmarshal(loanBytes, &loan)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, "Error unmarshiling in loanstatus(loan):"+err.Error())
+	}
+
+	return shim.Success([]byte(loanStatus(loan)))
+}
+
+func getLoanSancAmt(stub shim.ChaincodeStubInterface, loanID string) pb.Response {
+	fmt.Println("loancc: inside getLoanSancAmt")
+	loanBytes, err := stub.GetState(loanID)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if loanBytes == nil {
+		return errcode.Error("loancc", errcode.NotFound, "No data exists on this loanID: "+loanID)
+	}
+
+	loan := loanInfo{}
+	err = json.Unmarshal(loanBytes, &loan)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, "Error unmarshiling in loanstatus(loan):"+err.Error())
+	}
+	fmt.Println(loan.SanctionAmt)
+	sancAmtString := loan.SanctionAmt.String()
+	fmt.Println(sancAmtString)
+	return shim.Success([]byte(sancAmtString))
+}
+
+func getWalletID(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	loanBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if loanBytes == nil {
+		return errcode.Error("loancc", errcode.NotFound, "No data exists on this loanID: "+args[0])
+	}
+
+	loan := loanInfo{}
+	err = json.Unmarshal(loanBytes, &loan)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, "Unable to parse into loan the structure (loanWalletValues)"+err.Error())
+	}
+
+	walletID := ""
+
+	switch args[1] {
+	case "accrued":
+		walletID = loan.LoanAccruedInterestWalletID
+	case "charges":
+		walletID = loan.LoanChargesWalletID
+	case "disbursed":
+		walletID = loan.LoanDisbursedWalletID
+	default:
+		return errcode.Error("loancc", errcode.NotFound, "There is no wallet of this type in Loan :"+args[1])
+	}
+
+	return shim.Success([]byte(walletID))
+}
+
+func getLoanInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in getLoanInfo (required:1) given:"+xLenStr)
+
+	}
+
+	loanBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if loanBytes == nil {
+		return errcode.Error("loancc", errcode.NotFound, "No data exists on this loanID: "+args[0])
+	}
+
+	loan := loanInfo{}
+	err = json.Unmarshal(loanBytes, &loan)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+
+	loanString := fmt.Sprintf("%+v", loan)
+	fmt.Printf("Loan Info:%s\n ", loanString)
+
+	return shim.Success(loanBytes)
+}
+
+func getSellerID(stub shim.ChaincodeStubInterface, loanID string) pb.Response {
+
+	loanBytes, err := stub.GetState(loanID)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if loanBytes == nil {
+		return errcode.Error("loancc", errcode.NotFound, "No data exists on this loanID (getSellerID): "+loanID)
+	}
+
+	loan := loanInfo{}
+	err = json.Unmarshal(loanBytes, &loan)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+
+	return shim.Success([]byte(loan.SellerBusinessID))
+}
+
+func getBuyerID(stub shim.ChaincodeStubInterface, loanID string) pb.Response {
+
+	loanBytes, err := stub.GetState(loanID)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if loanBytes == nil {
+		return errcode.Error("loancc", errcode.NotFound, "No data exists on this loanID (getSellerID): "+loanID)
+	}
+
+	loan := loanInfo{}
+	err = json.Unmarshal(loanBytes, &loan)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+
+	return shim.Success([]byte(loan.BuyerBusinessID))
+}
+
+func getProgramID(stub shim.ChaincodeStubInterface, loanID string) pb.Response {
+
+	loanBytes, err := stub.GetState(loanID)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	} else if loanBytes == nil {
+		return errcode.Error("loancc", errcode.NotFound, "No data exists on this loanID (getProgramID): "+loanID)
+	}
+
+	loan := loanInfo{}
+	err = json.Unmarshal(loanBytes, &loan)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+
+	return shim.Success([]byte(loan.ProgramID))
+}
+
+func updateLoanInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> LoanID
+	 *args[1] -> Status the posted txn moves the loan to
+	 *args[2] -> Txn type
+	 */
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in updateLoanInfo (required:3) given: "+xLenStr)
+	}
+	loan, err := getLoan(stub, args[0])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, "error in reading loan: in updateLoanInfo"+err.Error())
+	}
+	prevStatus := loanStatus(loan)
+	err = moveLoan(stub, &loan, args[1], args[2], true)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	loanBytes, _ := json.Marshal(loan)
+	err = history.Put(stub, args[0], loanBytes)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, "Error in loan updation "+err.Error())
+	}
+
+	evs := []events.Event{events.New(events.LoanStatusChanged, events.StatusChange{ID: args[0], From: prevStatus, To: args[1]})}
+
+	// The first disbursement marks the instrument disbursed, a repayment in
+	// full settles it and one out of overdue makes it disbursed again
+	insEvs, err := moveInstrument(stub, loan, prevStatus, args[1], false)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	evs = append(evs, insEvs...)
+	err = events.Emit(stub, "loancc", evs...)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte("Loan status updated to " + args[1]))
+}
+
+func restoreLoanStatus(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> LoanID
+	 *args[1] -> Status the reversed transaction left behind
+	 *args[2] -> Status to restore
+	 */
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in restoreLoanStatus (required:3) given: "+xLenStr)
+	}
+
+	loan, err := getLoan(stub, args[0])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, "error in reading loan: in restoreLoanStatus"+err.Error())
+	}
+	from, to := args[1], args[2]
+	if status, ok := legacyStatuses[from]; ok {
+		from = status
+	}
+	if status, ok := legacyStatuses[to]; ok {
+		to = status
+	}
+
+	// A later transaction has moved the loan on, so reversing this one would
+	// leave it in the wrong state
+	if loanStatus(loan) != from {
+		return errcode.Error("loancc", errcode.InvalidState, "Loan status is "+loanStatus(loan)+", not "+from+", cannot restore it to "+to)
+	}
+	// Only a posted move can be undone
+	if t, ok := findTransition(to, from); !ok || !t.Posted {
+		return errcode.Error("loancc", errcode.InvalidState, "Loan did not move from "+to+" to "+from+" by a txn, cannot restore it")
+	}
+	err = recordStatus(stub, &loan, from, to, "reversal")
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	loanBytes, _ := json.Marshal(loan)
+	err = history.Put(stub, args[0], loanBytes)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, "Error in loan status updation "+err.Error())
+	}
+
+	evs := []events.Event{events.New(events.LoanStatusChanged, events.StatusChange{ID: args[0], From: from, To: to})}
+
+	// The instrument goes back with the loan
+	insEvs, err := moveInstrument(stub, loan, from, to, true)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	evs = append(evs, insEvs...)
+	err = events.Emit(stub, "loancc", evs...)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte("Loan status restored to " + to))
+}
+
+func getLoanHistory(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> LoanID
+	 *args[1] -> page size, 20 when not given (at most 100)
+	 *args[2] -> bookmark of the previous page, for the next one
+	 */
+	if len(args) < 1 || len(args) > 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in getLoanHistory (required:1 to 3) given: "+xLenStr)
+	}
+	page, err := history.Get(stub, args[0], args[1:])
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	pageBytes, _ := json.Marshal(page)
+	return shim.Success(pageBytes)
+}
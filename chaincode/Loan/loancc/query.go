@@ -0,0 +1,112 @@
+package loancc
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	clock "github.com/chaincode/Clock"
+	errcode "github.com/chaincode/Errcode"
+	query "github.com/chaincode/Query"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// loanDocType marks the loans among the records of loancc, which the
+// queries are limited to. The CouchDB indexes in META-INF start with it.
+const loanDocType = "loan"
+
+func queryLoans(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> CouchDB selector on the fields of the loan, e.g. {"LoanStatus":"disbursed","SanctionAmt.currency":"INR"}
+	 *args[1] -> page size, 20 when not given (at most 100)
+	 *args[2] -> bookmark of the previous page, for the next one
+	 */
+	if len(args) < 1 || len(args) > 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in queryLoans (required:1 to 3) given: "+xLenStr)
+	}
+	selector := map[string]interface{}{}
+	err := json.Unmarshal([]byte(args[0]), &selector)
+	if err != nil {
+		return errcode.Error("loancc", errcode.InvalidArgument, "The selector is not a JSON object: "+err.Error())
+	}
+	return findLoans(stub, selector, nil, args[1:])
+}
+
+// getLoansBy returns the loans with a value of the field
+func getLoansBy(stub shim.ChaincodeStubInterface, function string, field string, args []string) pb.Response {
+	/*
+	 *args[0] -> value of the field
+	 *args[1] -> page size, 20 when not given (at most 100)
+	 *args[2] -> bookmark of the previous page, for the next one
+	 */
+	if len(args) < 1 || len(args) > 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in "+function+" (required:1 to 3) given: "+xLenStr)
+	}
+	return findLoans(stub, map[string]interface{}{field: args[0]}, nil, args[1:])
+}
+
+func getLoansByDueDate(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> first due date
+	 *args[1] -> last due date
+	 *args[2] -> page size, 20 when not given (at most 100)
+	 *args[3] -> bookmark of the previous page, for the next one
+	 */
+	if len(args) < 2 || len(args) > 4 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in getLoansByDueDate (required:2 to 4) given: "+xLenStr)
+	}
+	from, err := time.Parse(clock.DateLayout, args[0])
+	if err != nil {
+		return errcode.Error("loancc", errcode.InvalidArgument, err.Error())
+	}
+	to, err := time.Parse(clock.DateLayout, args[1])
+	if err != nil {
+		return errcode.Error("loancc", errcode.InvalidArgument, err.Error())
+	}
+	selector := map[string]interface{}{
+		"DueDate": map[string]string{
+			"$gte": from.Format(time.RFC3339),
+			"$lt":  to.AddDate(0, 0, 1).Format(time.RFC3339),
+		},
+	}
+	sort := []query.Sort{{"docType": "asc"}, {"DueDate": "asc"}}
+	return findLoans(stub, selector, sort, args[2:])
+}
+
+func listAccruingLoans(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> BuyerID (optional, every buyer when not given)
+	 */
+	if len(args) > 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("loancc", errcode.InvalidArgument, "Invalid number of arguments in listAccruingLoans (required:0 or 1) given: "+xLenStr)
+	}
+	selector := map[string]interface{}{
+		"docType":    loanDocType,
+		"LoanStatus": map[string]interface{}{"$in": accruingStatuses},
+	}
+	if len(args) == 1 {
+		selector["BuyerID"] = args[0]
+	}
+	results, err := query.All(stub, selector)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	resultsBytes, _ := json.Marshal(results)
+	return shim.Success(resultsBytes)
+}
+
+// findLoans returns a page of the loans that match the selector
+func findLoans(stub shim.ChaincodeStubInterface, selector map[string]interface{}, sort []query.Sort, pageArgs []string) pb.Response {
+	selector["docType"] = loanDocType
+	page, err := query.Get(stub, selector, sort, pageArgs)
+	if err != nil {
+		return errcode.Error("loancc", errcode.Internal, err.Error())
+	}
+	pageBytes, _ := json.Marshal(page)
+	return shim.Success(pageBytes)
+}
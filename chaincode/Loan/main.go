@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+
+	loancc "github.com/chaincode/Loan/loancc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() {
+	err := shim.Start(new(loancc.Chaincode))
+	if err != nil {
+		fmt.Printf("loancc: "+"Error starting Loan chaincode: %s\n", err)
+	}
+}
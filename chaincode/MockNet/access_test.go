@@ -0,0 +1,58 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	approval "github.com/chaincode/Approval"
+	errcode "github.com/chaincode/Errcode"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+func denied(t *testing.T, response pb.Response, what string) {
+	t.Helper()
+	env, ok := errcode.Find(response.Message)
+	if !ok || env.Code != errcode.Unauthorized || !strings.HasPrefix(env.Message, "access denied") {
+		t.Errorf("%s was not denied: %d %s", what, response.Status, response.Message)
+	}
+}
+
+func TestAccessControl(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	walletID := string(mustInvoke(t, n, c.ops, "bankcc", "getWalletID", "1bank", "main"))
+
+	// Wallets only move by credit and debit through txncc, whoever the client is
+	denied(t, n.Invoke(c.admin, "walletcc", "credit", walletID, "100", "x1"), "direct credit")
+	denied(t, n.Invoke(c.admin, "walletcc", "updateWallet", walletID, "1000000"), "direct updateWallet")
+	denied(t, n.Invoke(c.ops, "txnbalcc", "putTxnBalInfo", "x1"), "direct putTxnBalInfo")
+
+	// Roles
+	denied(t, n.Invoke(c.maker, "businesscc", "updateBusinessInfo", "1bus", "Limit", "9000000"), "limit raised by a maker")
+	denied(t, n.Invoke(c.outsider, "bankcc", "writeBankInfo", "2bank", "sbi", "chennai", "41A", "0", "0", "0", "0", "0"), "bank written without a role")
+	denied(t, n.Invoke(c.checker, "txncc", "submitTxn", "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh"), "txn submitted by a checker")
+	denied(t, n.Invoke(c.maker, "loancc", "newLoanInfo", "1loan"), "loan sanctioned without approval")
+	mustInvoke(t, n, c.outsider, "bankcc", "getBankInfo", "1bank")
+}
+
+func TestRejectedLoan(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+
+	reqID := string(mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus"))
+	mustFail(t, n.Invoke(c.checker, "loancc", "rejectLoan", reqID, ""), "rejection without a reason")
+	mustInvoke(t, n, c.checker, "loancc", "rejectLoan", reqID, "limit exceeded")
+	mustFail(t, n.Invoke(c.checker, "loancc", "approveLoan", reqID), "approval of a rejected request")
+	mustFail(t, n.Invoke(c.ops, "loancc", "getLoanStatus", "1loan"), "status of a rejected loan")
+
+	reqs := []approval.Request{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", "listLoanRequests", approval.Rejected), &reqs)
+	if len(reqs) != 1 || reqs[0].RequestID != reqID || reqs[0].Reason != "limit exceeded" {
+		t.Errorf("rejected requests: %+v", reqs)
+	}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", "listLoanRequests", approval.Pending), &reqs)
+	if len(reqs) != 0 {
+		t.Errorf("pending requests: %+v", reqs)
+	}
+}
@@ -0,0 +1,70 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	errcode "github.com/chaincode/Errcode"
+)
+
+func TestAccrueInterest(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	mustInvoke(t, n, c.maker, "instrumentcc", "enterInstrument", "2ins", "23/10/2018", "2bus", "1bus", "1000", "23/07/2019", "1prg", "1ppr", "34", "04/01/2018:12:43:59")
+
+	// 900 disbursed at 5% with value date 24/04, and a loan that is only sanctioned
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "24/04/2018:10:00:00", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	reqID = mustInvoke(t, n, c.maker, "loancc", "submitLoan", "2loan", "2ins", "1bus", "1prg", "800", "pragadeesh", "5", "23/10/2018", "24/04/2018:10:00:00", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+
+	type run struct {
+		DayCount string
+		Loans    []struct {
+			LoanID, TxnID, Skipped string
+			Days                   int64
+		}
+	}
+	accrue := func(date string) run {
+		t.Helper()
+		r := run{}
+		json.Unmarshal(mustInvoke(t, n, c.ops, "txncc", "accrueInterest", date, "1bank", "ops"), &r)
+		return r
+	}
+
+	// 30 days of ACT/365 on 900 at 5% is 3.6986
+	n.Time = time.Date(2018, time.May, 24, 18, 0, 0, 0, time.UTC)
+	r := accrue("24/05/2018")
+	if r.DayCount != "ACT/365" || len(r.Loans) != 1 || r.Loans[0].LoanID != "1loan" || r.Loans[0].Days != 30 || r.Loans[0].TxnID == "" {
+		t.Fatalf("first run: %+v", r)
+	}
+	if bal := walletBalance(t, n, c, "loancc", "1loan", "accrued"); bal != "3.70" {
+		t.Errorf("loan accrued wallet after 30 days: %s", bal)
+	}
+
+	// running again for the same date posts nothing
+	r = accrue("24/05/2018")
+	if len(r.Loans) != 1 || r.Loans[0].TxnID != "" || r.Loans[0].Skipped == "" {
+		t.Errorf("second run: %+v", r)
+	}
+	if bal := walletBalance(t, n, c, "loancc", "1loan", "accrued"); bal != "3.70" {
+		t.Errorf("loan accrued wallet after the second run: %s", bal)
+	}
+
+	// under 30/360 the 31st counts as a day of its own after the 24th: 7 days, 0.875
+	mustFail(t, n.Invoke(c.ops, "txncc", "putDayCount", "30/360"), "day-count convention put by ops")
+	failsWith(t, n.Invoke(c.admin, "txncc", "putDayCount", "ACT/ACT"), errcode.InvalidArgument, "txncc")
+	mustInvoke(t, n, c.admin, "txncc", "putDayCount", "30/360")
+	n.Time = time.Date(2018, time.May, 31, 18, 0, 0, 0, time.UTC)
+	failsWith(t, n.Invoke(c.ops, "txncc", "accrueInterest", "01/06/2018", "1bank", "ops"), errcode.InvalidArgument, "txncc")
+	r = accrue("31/05/2018")
+	if r.DayCount != "30/360" || len(r.Loans) != 1 || r.Loans[0].Days != 7 {
+		t.Fatalf("30/360 run: %+v", r)
+	}
+	if bal := walletBalance(t, n, c, "loancc", "1loan", "accrued"); bal != "4.58" {
+		t.Errorf("loan accrued wallet after 37 days: %s", bal)
+	}
+}
@@ -0,0 +1,123 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	errcode "github.com/chaincode/Errcode"
+)
+
+func TestBulkRepayment(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	mustInvoke(t, n, c.maker, "instrumentcc", "enterInstrument", "2ins", "23/10/2018", "2bus", "1bus", "1000", "23/07/2019", "1prg", "1ppr", "34", "04/01/2018:12:43:59")
+
+	// two loans of the buyer 1bus, 2loan due first
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "24/04/2018:10:00:00", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	reqID = mustInvoke(t, n, c.maker, "loancc", "submitLoan", "2loan", "2ins", "1bus", "1prg", "800", "pragadeesh", "5", "10/05/2018", "24/04/2018:10:00:00", "0.0", "0.0", "0.0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "500", "1bank", "2bus", "pragadeesh")
+	post(t, n, c, "2txn", "disbursement", "24/04/2018", "2loan", "2ins", "500", "1bank", "2bus", "pragadeesh")
+
+	type amount struct{ Value string }
+	type bulk struct {
+		Repayments []struct {
+			TxnID, LoanID string
+			Amount        amount
+		}
+		Unapplied      amount
+		UnappliedTxnID string
+	}
+
+	failsWith(t, n.Invoke(c.checker, "txncc", "repayBulk", "b1", "24/05/2018", "1bus", "1100", "1bank", "checker", "9loan"), errcode.InvalidArgument, "txncc")
+	failsWith(t, n.Invoke(c.checker, "txncc", "repayBulk", "b1", "24/05/2018", "1bus", "1100", "1bank", "checker", "1loan:600,2loan:600"), errcode.InvalidArgument, "txncc")
+
+	// 300 to 1loan as asked, and the other 800 held for the buyer
+	b := bulk{}
+	json.Unmarshal(mustInvoke(t, n, c.checker, "txncc", "repayBulk", "b1", "24/05/2018", "1bus", "1100", "1bank", "checker", "1loan:300"), &b)
+	if len(b.Repayments) != 1 || b.Repayments[0].TxnID != "b1_1" || b.Repayments[0].Amount.Value != "300.00" || b.Unapplied.Value != "800.00" || b.UnappliedTxnID != "b1_credit" {
+		t.Fatalf("bulk repayment b1: %+v", b)
+	}
+	if status := loanStatus(t, n, c); status != "part_collected" {
+		t.Errorf("1loan status after b1: %s", status)
+	}
+	if credit := string(mustInvoke(t, n, c.ops, "txncc", "getUnappliedCredit", "1bus")); credit != "800.00" {
+		t.Errorf("unapplied credit after b1: %s", credit)
+	}
+	if bal := walletBalance(t, n, c, "businesscc", "1bus", "main"); bal != "8900.00" {
+		t.Errorf("buyer main wallet after b1: %s", bal)
+	}
+
+	// the credit goes to 2loan, due first, then to what is left of 1loan
+	b = bulk{}
+	json.Unmarshal(mustInvoke(t, n, c.checker, "txncc", "applyUnappliedCredit", "a1", "25/05/2018", "1bus", "1bank", "checker"), &b)
+	if len(b.Repayments) != 2 || b.Repayments[0].LoanID != "2loan" || b.Repayments[0].Amount.Value != "500.00" || b.Repayments[1].LoanID != "1loan" || b.Repayments[1].Amount.Value != "200.00" || b.Unapplied.Value != "100.00" {
+		t.Fatalf("credit applied by a1: %+v", b)
+	}
+	for _, loanID := range []string{"1loan", "2loan"} {
+		if status := string(mustInvoke(t, n, c.ops, "loancc", "getLoanStatus", loanID)); status != "collected" {
+			t.Errorf("%s status after a1: %s", loanID, status)
+		}
+		if bal := walletBalance(t, n, c, "loancc", loanID, "disbursed"); bal != "0.00" {
+			t.Errorf("%s disbursed wallet after a1: %s", loanID, bal)
+		}
+	}
+	if credit := string(mustInvoke(t, n, c.ops, "txncc", "getUnappliedCredit", "1bus")); credit != "100.00" {
+		t.Errorf("unapplied credit after a1: %s", credit)
+	}
+	// both repayments of a1 come out of the bank liability wallet
+	if bal := walletBalance(t, n, c, "bankcc", "1bank", "liability"); bal != "1100.00" {
+		t.Errorf("bank liability wallet after a1: %s", bal)
+	}
+
+	// what is left is paid back, no more than that
+	failsWith(t, n.Invoke(c.checker, "txncc", "refundUnappliedCredit", "r1", "26/05/2018", "1bus", "150", "1bank", "checker"), errcode.InsufficientFunds, "txncc")
+	mustInvoke(t, n, c.checker, "txncc", "refundUnappliedCredit", "r1", "26/05/2018", "1bus", "100", "1bank", "checker")
+	if bal := walletBalance(t, n, c, "businesscc", "1bus", "main"); bal != "9000.00" {
+		t.Errorf("buyer main wallet after the refund: %s", bal)
+	}
+
+	// reversing the refund gives the credit back, but the credit of b1 is
+	// applied and cannot be reversed
+	mustInvoke(t, n, c.checker, "txncc", "reverseTxn", "r1", "refunded twice", "checker")
+	if credit := string(mustInvoke(t, n, c.ops, "txncc", "getUnappliedCredit", "1bus")); credit != "100.00" {
+		t.Errorf("unapplied credit after reversing the refund: %s", credit)
+	}
+	failsWith(t, n.Invoke(c.checker, "txncc", "reverseTxn", "b1_credit", "wrong buyer", "checker"), errcode.InsufficientFunds, "txncc")
+}
+
+func TestAppliedExcess(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	mustInvoke(t, n, c.maker, "instrumentcc", "enterInstrument", "2ins", "23/10/2018", "2bus", "1bus", "1000", "23/07/2019", "1prg", "1ppr", "34", "04/01/2018:12:43:59")
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "24/04/2018:10:00:00", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	reqID = mustInvoke(t, n, c.maker, "loancc", "submitLoan", "2loan", "2ins", "1bus", "1prg", "800", "pragadeesh", "5", "23/10/2018", "24/04/2018:10:00:00", "0.0", "0.0", "0.0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "500", "1bank", "2bus", "pragadeesh")
+	post(t, n, c, "2txn", "disbursement", "24/04/2018", "2loan", "2ins", "500", "1bank", "2bus", "pragadeesh")
+
+	// 600 repays the 500 of 1loan and the other 100 is held for the buyer,
+	// then goes to 2loan; the buyer owes 600 less, not 700
+	post(t, n, c, "3txn", "repayment", "24/05/2018", "1loan", "1ins", "600", "1bus", "1bank", "pragadeesh")
+	if credit := string(mustInvoke(t, n, c.ops, "txncc", "getUnappliedCredit", "1bus")); credit != "100.00" {
+		t.Errorf("unapplied credit after the repayment: %s", credit)
+	}
+	if bal := walletBalance(t, n, c, "businesscc", "1bus", "liability"); bal != "9500.00" {
+		t.Errorf("buyer liability wallet after the repayment: %s", bal)
+	}
+	mustInvoke(t, n, c.checker, "txncc", "applyUnappliedCredit", "a1", "25/05/2018", "1bus", "1bank", "checker")
+	if credit := string(mustInvoke(t, n, c.ops, "txncc", "getUnappliedCredit", "1bus")); credit != "0.00" {
+		t.Errorf("unapplied credit after a1: %s", credit)
+	}
+	if bal := walletBalance(t, n, c, "businesscc", "1bus", "liability"); bal != "9400.00" {
+		t.Errorf("buyer liability wallet after a1: %s", bal)
+	}
+	if bal := walletBalance(t, n, c, "loancc", "2loan", "disbursed"); bal != "400.00" {
+		t.Errorf("2loan disbursed wallet after a1: %s", bal)
+	}
+}
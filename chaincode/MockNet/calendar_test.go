@@ -0,0 +1,77 @@
+package mocknet_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	errcode "github.com/chaincode/Errcode"
+)
+
+func TestCalendar(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+
+	// Sundays and Gandhi Jayanti, Tuesday 02/10/2018, are off
+	failsWith(t, n.Invoke(c.maker, "calendarcc", "putCalendar", "in", "India", "Sunday"), errcode.Unauthorized, "calendarcc")
+	failsWith(t, n.Invoke(c.admin, "calendarcc", "putCalendar", "in", "India", "Sunday,Funday"), errcode.InvalidArgument, "calendarcc")
+	mustInvoke(t, n, c.admin, "calendarcc", "putCalendar", "in", "India", "Sunday")
+	mustInvoke(t, n, c.admin, "calendarcc", "putHoliday", "in", "02/10/2018", "Gandhi Jayanti")
+	mustInvoke(t, n, c.admin, "calendarcc", "putHoliday", "in", "25/12/2018", "Christmas")
+	failsWith(t, n.Invoke(c.admin, "calendarcc", "putHoliday", "nowhere", "25/12/2018", "Christmas"), errcode.NotFound, "calendarcc")
+	mustInvoke(t, n, c.admin, "calendarcc", "removeHoliday", "in", "25/12/2018")
+	holidays := []struct{ Name string }{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "calendarcc", "getHolidays", "in", "2018"), &holidays)
+	if len(holidays) != 1 || holidays[0].Name != "Gandhi Jayanti" {
+		t.Errorf("holidays of 2018: %+v", holidays)
+	}
+	if business := string(mustInvoke(t, n, c.ops, "calendarcc", "isBusinessDay", "in", "02/10/2018")); business != "false" {
+		t.Errorf("02/10/2018 is a business day: %s", business)
+	}
+
+	for _, adj := range []struct{ date, rule, want string }{
+		{"30/09/2018", "none", "30/09/2018"},
+		{"30/09/2018", "following", "01/10/2018"},
+		{"02/10/2018", "following", "03/10/2018"},
+		{"02/10/2018", "preceding", "01/10/2018"},
+		// the Monday after is in October, so the Saturday before
+		{"30/09/2018", "modified_following", "29/09/2018"},
+		{"03/10/2018", "modified_following", "03/10/2018"},
+	} {
+		if got := string(mustInvoke(t, n, c.ops, "calendarcc", "adjustDate", "in", adj.date, adj.rule)); got != adj.want {
+			t.Errorf("%s %s: %s, not %s", adj.rule, adj.date, got, adj.want)
+		}
+	}
+	failsWith(t, n.Invoke(c.ops, "calendarcc", "adjustDate", "in", "30/09/2018", "nearest"), errcode.InvalidArgument, "calendarcc")
+
+	// a program on the calendar moves the due dates of its instruments and loans
+	failsWith(t, n.Invoke(c.maker, "programcc", "writeProgram", "2prg", "program2", "1bus", "Accounts_Payable", "10/04/2019", "10000", "6", "buyer", "4", "100", "pragadeesh", "123452", "nowhere"), errcode.NotFound, "programcc")
+	failsWith(t, n.Invoke(c.maker, "programcc", "writeProgram", "2prg", "program2", "1bus", "Accounts_Payable", "10/04/2019", "10000", "6", "buyer", "4", "100", "pragadeesh", "123452", "in", "nearest"), errcode.InvalidArgument, "programcc")
+	mustInvoke(t, n, c.maker, "programcc", "writeProgram", "2prg", "program2", "1bus", "Accounts_Payable", "10/04/2019", "10000", "6", "buyer", "4", "100", "pragadeesh", "123452", "in")
+	mustInvoke(t, n, c.maker, "pprcc", "createPPR", "2ppr", "2prg", "2bus", "seller", "12000", "3", "100", "5", "40", "34tf2")
+	mustInvoke(t, n, c.maker, "instrumentcc", "enterInstrument", "2ins", "23/10/2018", "2bus", "1bus", "1000", "02/10/2018", "2prg", "1ppr", "34", "04/01/2018:12:43:59")
+	inst := struct{ DueDate time.Time }{}
+	instKey := sha256.Sum256([]byte("2ins2bus"))
+	json.Unmarshal(n.Stub("instrumentcc").State[hex.EncodeToString(instKey[:])], &inst)
+	if want := time.Date(2018, time.October, 3, 0, 0, 0, 0, time.UTC); !inst.DueDate.Equal(want) {
+		t.Errorf("2ins due date %s", inst.DueDate)
+	}
+
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "2loan", "2ins", "1bus", "2prg", "600", "pragadeesh", "5", "30/09/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	loan := struct{ DueDate time.Time }{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", "getLoanInfo", "2loan"), &loan)
+	if want := time.Date(2018, time.October, 1, 0, 0, 0, 0, time.UTC); !loan.DueDate.Equal(want) {
+		t.Errorf("2loan due date %s", loan.DueDate)
+	}
+
+	// a program without a calendar keeps the due date
+	reqID = mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/09/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", "getLoanInfo", "1loan"), &loan)
+	if want := time.Date(2018, time.September, 23, 0, 0, 0, 0, time.UTC); !loan.DueDate.Equal(want) {
+		t.Errorf("1loan due date %s", loan.DueDate)
+	}
+}
@@ -0,0 +1,41 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBusinessDates(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+
+	// Sanctioned at 20:00 UTC on 23/04, which is 24/04 in India
+	n.Time = time.Date(2018, time.April, 23, 20, 0, 0, 0, time.UTC)
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+
+	loan := struct{ SanctionDate, ValueDate, PostingDate time.Time }{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", "getLoanInfo", "1loan"), &loan)
+	if !loan.SanctionDate.Equal(n.Time) {
+		t.Errorf("sanction date %s, not the txn timestamp %s", loan.SanctionDate, n.Time)
+	}
+	if want := time.Date(2018, time.September, 25, 20, 45, 1, 0, time.UTC); !loan.ValueDate.Equal(want) {
+		t.Errorf("loan value date %s", loan.ValueDate)
+	}
+	if want := time.Date(2018, time.April, 24, 0, 0, 0, 0, time.UTC); !loan.PostingDate.Equal(want) {
+		t.Errorf("loan posting date %s", loan.PostingDate)
+	}
+
+	// A disbursement with an earlier value date is posted on the day it is approved
+	n.Time = time.Date(2018, time.April, 25, 10, 0, 0, 0, time.UTC)
+	post(t, n, c, "1txn", "disbursement", "20/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+	txn := struct{ TxnDate, PostingDate time.Time }{}
+	json.Unmarshal(n.Stub("txncc").State["1txn"], &txn)
+	if want := time.Date(2018, time.April, 20, 0, 0, 0, 0, time.UTC); !txn.TxnDate.Equal(want) {
+		t.Errorf("txn value date %s", txn.TxnDate)
+	}
+	if want := time.Date(2018, time.April, 25, 0, 0, 0, 0, time.UTC); !txn.PostingDate.Equal(want) {
+		t.Errorf("txn posting date %s", txn.PostingDate)
+	}
+}
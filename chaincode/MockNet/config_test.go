@@ -0,0 +1,77 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	common "github.com/chaincode/Common"
+	mocknet "github.com/chaincode/MockNet"
+	walletcc "github.com/chaincode/Wallet/walletcc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func TestConfiguredNames(t *testing.T) {
+	n, c := newNetwork(t)
+	n.Add("walletcc-uat", new(walletcc.Chaincode))
+
+	response := n.Init("bankcc", `{"walletcc":"walletcc-uat"}`)
+	if response.Status != shim.OK {
+		t.Fatal(response.Message)
+	}
+	mustInvoke(t, n, c.admin, "bankcc", "writeBankInfo", "1bank", "kvb", "chennai", "40A", "100000", "0", "0", "0", "0")
+
+	// The bank's wallets are created on the walletcc it was configured with
+	walletID := string(mustInvoke(t, n, c.ops, "bankcc", "getWalletID", "1bank", "main"))
+	if n.Stub("walletcc-uat").State[walletID] == nil {
+		t.Error("main wallet is not on walletcc-uat")
+	}
+	if n.Stub("walletcc").State[walletID] != nil {
+		t.Error("main wallet is on walletcc")
+	}
+
+	mustFail(t, n.Init("bankcc", `{"walletcc":`), "init with a broken config")
+}
+
+// TestRegistry deploys the chaincodes under other names on another channel,
+// as in UAT, with bankcc as the registry the others read their config from
+func TestRegistry(t *testing.T) {
+	n, c := install(t, mocknet.NewOnChannel("uatc"), "-uat")
+
+	cfg := common.Config{Channel: "uatc", Bank: "bankcc-uat", Business: "businesscc-uat", Calendar: "calendarcc-uat", Instrument: "instrumentcc-uat", Loan: "loancc-uat",
+		PPR: "pprcc-uat", Program: "programcc-uat", TxnBal: "txnbalcc-uat", Txn: "txncc-uat", Wallet: "walletcc-uat"}
+	cfgBytes, _ := json.Marshal(cfg)
+	response := n.Init("bankcc-uat", string(cfgBytes))
+	if response.Status != shim.OK {
+		t.Fatal(response.Message)
+	}
+	for _, name := range []string{"businesscc", "calendarcc", "instrumentcc", "loancc", "pprcc", "programcc", "txnbalcc", "txncc", "walletcc"} {
+		response = n.Init(name+"-uat", `{"channel":"uatc","registry":"bankcc-uat"}`)
+		if response.Status != shim.OK {
+			t.Fatalf("%s: %s", name, response.Message)
+		}
+	}
+
+	got := common.Config{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "txncc-uat", "getConfig"), &got)
+	cfg.Registry = "bankcc-uat"
+	if got != cfg {
+		t.Fatalf("txncc-uat config: %+v", got)
+	}
+
+	// A loan is sanctioned and disbursed with every call on the UAT names
+	mustInvoke(t, n, c.admin, "bankcc-uat", "writeBankInfo", "1bank", "kvb", "chennai", "40A", "100000", "0", "0", "0", "0")
+	mustInvoke(t, n, c.maker, "businesscc-uat", "putNewBusinessInfo", "1bus", "tata", "12348901", "4000000", "10000", "0", "10000", "12", "8", "0", "0")
+	mustInvoke(t, n, c.maker, "businesscc-uat", "putNewBusinessInfo", "2bus", "mrf", "12348902", "4000000", "10000", "0", "0", "12", "8", "0", "0")
+	mustInvoke(t, n, c.maker, "programcc-uat", "writeProgram", "1prg", "program1", "1bus", "Accounts_Payable", "10/04/2019", "10000", "6", "buyer", "4", "100", "pragadeesh", "123452")
+	mustInvoke(t, n, c.maker, "pprcc-uat", "createPPR", "1ppr", "1prg", "2bus", "seller", "12000", "3", "100", "5", "40", "34tf2")
+	mustInvoke(t, n, c.maker, "instrumentcc-uat", "enterInstrument", "1ins", "23/10/2018", "2bus", "1bus", "1000", "23/07/2019", "1prg", "1ppr", "34", "04/01/2018:12:43:59")
+	reqID := mustInvoke(t, n, c.maker, "loancc-uat", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc-uat", "approveLoan", string(reqID))
+	reqID = mustInvoke(t, n, c.maker, "txncc-uat", "submitTxn", "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+	mustInvoke(t, n, c.checker, "txncc-uat", "approveTxn", string(reqID))
+
+	if status := string(mustInvoke(t, n, c.ops, "loancc-uat", "getLoanStatus", "1loan")); status != "disbursed" {
+		t.Errorf("loan status after disbursement: %s", status)
+	}
+	denied(t, n.Invoke(c.admin, "walletcc-uat", "credit", "x", "100", "x1"), "direct credit")
+}
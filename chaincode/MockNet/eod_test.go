@@ -0,0 +1,144 @@
+package mocknet_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	money "github.com/chaincode/Money"
+)
+
+func TestRunEOD(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	mustInvoke(t, n, c.maker, "instrumentcc", "enterInstrument", "2ins", "23/10/2018", "2bus", "1bus", "1000", "23/07/2019", "1prg", "1ppr", "34", "04/01/2018:12:43:59")
+
+	// 1loan is due in October, 2loan on 10/05. loancc names the wallets of a
+	// loan after their opening amounts, so 2loan opens them at 0.0 to have
+	// wallets of its own.
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "24/04/2018:10:00:00", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	reqID = mustInvoke(t, n, c.maker, "loancc", "submitLoan", "2loan", "2ins", "1bus", "1prg", "800", "pragadeesh", "5", "10/05/2018", "24/04/2018:10:00:00", "0.0", "0.0", "0.0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "500", "1bank", "2bus", "pragadeesh")
+	post(t, n, c, "2txn", "disbursement", "24/04/2018", "2loan", "2ins", "500", "1bank", "2bus", "pragadeesh")
+	mustInvoke(t, n, c.admin, "txncc", "putPenalRate", "2")
+
+	type run struct {
+		Status, Cursor string
+		LoansDone      int
+		MarkedOverdue  int
+		Loans          []struct {
+			LoanID        string
+			MarkedOverdue bool
+			Accrual       struct{ TxnID string }
+			Penal         *struct {
+				TxnID string
+				Days  int64
+			}
+		}
+	}
+	runEOD := func() run {
+		t.Helper()
+		r := run{}
+		json.Unmarshal(mustInvoke(t, n, c.ops, "txncc", "runEOD", "24/05/2018", "1bank", "ops", "1"), &r)
+		return r
+	}
+
+	// a chunk of one loan at a time: the first call leaves the run open after 1loan
+	n.Time = time.Date(2018, time.May, 24, 18, 0, 0, 0, time.UTC)
+	r := runEOD()
+	if r.Status != "running" || r.Cursor != "1loan" || len(r.Loans) != 1 || r.Loans[0].Accrual.TxnID == "" || r.Loans[0].Penal != nil {
+		t.Fatalf("first chunk: %+v", r)
+	}
+
+	// the second goes on with 2loan, which is past due: overdue, and 14 days
+	// of 2% on 500 is 0.3836
+	r = runEOD()
+	if r.Status != "completed" || r.LoansDone != 2 || r.MarkedOverdue != 1 || len(r.Loans) != 1 || r.Loans[0].LoanID != "2loan" || !r.Loans[0].MarkedOverdue {
+		t.Fatalf("second chunk: %+v", r)
+	}
+	if penal := r.Loans[0].Penal; penal == nil || penal.TxnID == "" || penal.Days != 14 {
+		t.Errorf("penal interest of 2loan: %+v", penal)
+	}
+	if status := string(mustInvoke(t, n, c.ops, "loancc", "getLoanStatus", "2loan")); status != "overdue" {
+		t.Errorf("2loan status after EOD: %s", status)
+	}
+	if bal := walletBalance(t, n, c, "loancc", "2loan", "charges"); bal != "0.38" {
+		t.Errorf("2loan charges wallet after EOD: %s", bal)
+	}
+	inst := struct{ Status string }{}
+	instKey := sha256.Sum256([]byte("2ins2bus"))
+	json.Unmarshal(n.Stub("instrumentcc").State[hex.EncodeToString(instKey[:])], &inst)
+	if inst.Status != "overdue" {
+		t.Errorf("2ins status after EOD: %q", inst.Status)
+	}
+
+	// a completed run is not run again
+	r = runEOD()
+	if r.Status != "completed" || r.LoansDone != 2 || len(r.Loans) != 0 {
+		t.Errorf("completed run: %+v", r)
+	}
+	if bal := walletBalance(t, n, c, "loancc", "2loan", "charges"); bal != "0.38" {
+		t.Errorf("2loan charges wallet after the rerun: %s", bal)
+	}
+
+	report := run{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "txncc", "getEODReport", "24/05/2018"), &report)
+	if report.Status != "completed" || len(report.Loans) != 2 || report.Loans[0].LoanID != "1loan" || report.Loans[1].LoanID != "2loan" {
+		t.Errorf("EOD report: %+v", report)
+	}
+}
+
+func TestRunEODSharedWallets(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	mustInvoke(t, n, c.maker, "instrumentcc", "enterInstrument", "2ins", "23/10/2018", "2bus", "1bus", "1000", "23/07/2019", "1prg", "1ppr", "34", "04/01/2018:12:43:59")
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "10/05/2018", "24/04/2018:10:00:00", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	reqID = mustInvoke(t, n, c.maker, "loancc", "submitLoan", "2loan", "2ins", "1bus", "1prg", "800", "pragadeesh", "5", "10/05/2018", "24/04/2018:10:00:00", "0.0", "0.0", "0.0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "500", "1bank", "2bus", "pragadeesh")
+	post(t, n, c, "2txn", "disbursement", "24/04/2018", "2loan", "2ins", "300", "1bank", "2bus", "pragadeesh")
+	mustInvoke(t, n, c.admin, "txncc", "putPenalRate", "2")
+	bankCharges := walletBalance(t, n, c, "bankcc", "1bank", "charges")
+
+	// both loans in one chunk: their accruals and penal interest all go to
+	// the seller's chargesOut wallet and the penal interest to the bank's
+	// charges wallet, which have to add up
+	n.Time = time.Date(2018, time.May, 24, 18, 0, 0, 0, time.UTC)
+	mustInvoke(t, n, c.ops, "txncc", "runEOD", "24/05/2018", "1bank", "ops")
+	sum := func(amts ...string) string {
+		t.Helper()
+		total := money.Zero("INR")
+		for _, s := range amts {
+			amt, err := money.Parse(s, "INR")
+			if err != nil {
+				t.Fatal(err)
+			}
+			total, _ = total.Add(amt)
+		}
+		return total.String()
+	}
+	owed := []string{}
+	penal := []string{bankCharges}
+	for _, loanID := range []string{"1loan", "2loan"} {
+		accrued := walletBalance(t, n, c, "loancc", loanID, "accrued")
+		charges := walletBalance(t, n, c, "loancc", loanID, "charges")
+		if accrued == "0.00" || charges == "0.00" {
+			t.Fatalf("%s after EOD: accrued %s, charges %s", loanID, accrued, charges)
+		}
+		owed = append(owed, accrued, charges)
+		penal = append(penal, charges)
+	}
+	if bal, want := walletBalance(t, n, c, "businesscc", "2bus", "chargesOut"), sum(owed...); bal != want {
+		t.Errorf("seller chargesOut wallet after EOD: %s, the loans owe %s", bal, want)
+	}
+	if bal, want := walletBalance(t, n, c, "bankcc", "1bank", "charges"), sum(penal...); bal != want {
+		t.Errorf("bank charges wallet after EOD: %s, want %s", bal, want)
+	}
+}
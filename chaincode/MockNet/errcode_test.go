@@ -0,0 +1,57 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	errcode "github.com/chaincode/Errcode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// failsWith checks that the response is an error envelope with the code from
+// the chaincode and returns it
+func failsWith(t *testing.T, response pb.Response, code errcode.Code, chaincode string) errcode.Envelope {
+	t.Helper()
+	env := errcode.Envelope{}
+	if response.Status == shim.OK {
+		t.Fatalf("succeeded, expected %s from %s", code, chaincode)
+	} else if err := json.Unmarshal([]byte(response.Message), &env); err != nil {
+		t.Fatalf("not an error envelope: %s", response.Message)
+	}
+	if env.Code != code || env.Chaincode != chaincode {
+		t.Errorf("%s from %s, expected %s from %s: %s", env.Code, env.Chaincode, code, chaincode, env.Message)
+	}
+	return env
+}
+
+func TestErrorEnvelopes(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+
+	failsWith(t, n.Invoke(c.ops, "loancc", "getLoanInfo", "9loan"), errcode.NotFound, "loancc")
+	failsWith(t, n.Invoke(c.ops, "loancc", "getLoanInfo"), errcode.InvalidArgument, "loancc")
+	failsWith(t, n.Invoke(c.admin, "bankcc", "writeBankInfo", "1bank", "kvb", "chennai", "40A", "100000", "0", "0", "0", "0"), errcode.AlreadyExists, "bankcc")
+	failsWith(t, n.Invoke(c.outsider, "businesscc", "putNewBusinessInfo", "3bus", "abc", "12348903", "4000000", "10000", "0", "0", "12", "8", "0", "0"), errcode.Unauthorized, "businesscc")
+
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	failsWith(t, n.Invoke(c.maker, "loancc", "approveLoan", string(reqID)), errcode.Unauthorized, "loancc")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	failsWith(t, n.Invoke(c.checker, "loancc", "approveLoan", string(reqID)), errcode.InvalidState, "loancc")
+
+	// An instrument that is not open cannot be sanctioned again, which
+	// instrumentcc says to loancc
+	reqID = mustInvoke(t, n, c.maker, "loancc", "submitLoan", "2loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	failsWith(t, n.Invoke(c.checker, "loancc", "approveLoan", string(reqID)), errcode.InvalidState, "instrumentcc")
+
+	// Collecting more penal interest than the seller's main wallet holds
+	// fails in txncc, which moves the wallets before walletcc is called
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+	reqID = mustInvoke(t, n, c.maker, "txncc", "submitTxn", "2txn", "penal_interest_collection", "24/05/2018", "1loan", "1ins", "100000", "1bank", "2bus", "pragadeesh")
+	env := failsWith(t, n.Invoke(c.checker, "txncc", "approveTxn", string(reqID)), errcode.InsufficientFunds, "txncc")
+	walletID := string(mustInvoke(t, n, c.ops, "businesscc", "getWalletID", "2bus", "main"))
+	if !strings.HasPrefix(env.Message, "Insufficient balance in WalletId "+walletID) {
+		t.Errorf("insufficient funds message: %s", env.Message)
+	}
+}
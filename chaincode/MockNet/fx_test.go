@@ -0,0 +1,75 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	errcode "github.com/chaincode/Errcode"
+)
+
+func TestFxRates(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+
+	// a USD invoice financed by the INR bank, at 80 INR to the USD
+	mustInvoke(t, n, c.maker, "instrumentcc", "enterInstrument", "3ins", "23/10/2018", "2bus", "1bus", "1000", "23/07/2019", "1prg", "1ppr", "34", "04/01/2018:12:43:59", "USD")
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "3ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+
+	failsWith(t, n.Invoke(c.maker, "walletcc", "putFxRate", "usdinr", "USD", "INR", "80", "23/04/2018"), errcode.Unauthorized, "walletcc")
+	failsWith(t, n.Invoke(c.admin, "walletcc", "putFxRate", "usdinr", "USD", "usd", "1", "23/04/2018"), errcode.InvalidArgument, "walletcc")
+	failsWith(t, n.Invoke(c.admin, "walletcc", "putFxRate", "usdinr", "USD", "INR", "0", "23/04/2018"), errcode.InvalidArgument, "walletcc")
+	mustInvoke(t, n, c.admin, "walletcc", "putFxRate", "usdinr", "usd", "inr", "80", "23/04/2018")
+	failsWith(t, n.Invoke(c.admin, "walletcc", "putFxRate", "usdinr", "USD", "INR", "81", "23/04/2018"), errcode.AlreadyExists, "walletcc")
+	mustInvoke(t, n, c.admin, "walletcc", "putFxRate", "eurinr", "EUR", "INR", "90", "23/04/2018")
+	fx := struct{ FromCurrency, ToCurrency, Rate string }{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "walletcc", "getFxRate", "usdinr"), &fx)
+	if fx.FromCurrency != "USD" || fx.ToCurrency != "INR" || fx.Rate != "80" {
+		t.Errorf("FX rate usdinr: %+v", fx)
+	}
+	failsWith(t, n.Invoke(c.ops, "walletcc", "getFxRate", "gbpinr"), errcode.NotFound, "walletcc")
+
+	// the INR wallets are not moved in USD, nor by a rate from another currency
+	n.Time = n.Time.Add(24 * time.Hour)
+	for _, fxRateID := range []string{"", "eurinr"} {
+		reqID = mustInvoke(t, n, c.maker, "txncc", "submitTxn", "1txn", "disbursement", "24/04/2018", "1loan", "3ins", "10", "1bank", "2bus", "pragadeesh", "", fxRateID)
+		failsWith(t, n.Invoke(c.checker, "txncc", "approveTxn", string(reqID)), errcode.InvalidArgument, "txncc")
+		mustInvoke(t, n, c.checker, "txncc", "rejectTxn", string(reqID), "no rate")
+	}
+
+	// 10 USD disbursed is 800 INR out of the bank to the seller
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "3ins", "10", "1bank", "2bus", "pragadeesh", "", "usdinr")
+	for _, w := range []struct{ ccName, id, walletType, want string }{
+		{"bankcc", "1bank", "main", "200.00"},
+		{"businesscc", "2bus", "main", "10800.00"},
+		{"businesscc", "2bus", "principalOut", "800.00"},
+		{"loancc", "1loan", "disbursed", "10.00"},
+	} {
+		if bal := walletBalance(t, n, c, w.ccName, w.id, w.walletType); bal != w.want {
+			t.Errorf("%s %s wallet after the disbursement: %s", w.id, w.walletType, bal)
+		}
+	}
+	walletID := string(mustInvoke(t, n, c.ops, "bankcc", "getWalletID", "1bank", "main"))
+	s := walletStatement(t, n, c, walletID, "24/04/2018", "24/04/2018")
+	if len(s.Entries) != 1 || s.Entries[0].DAmt.String() != "800.00" || s.Entries[0].DAmt.Currency != "INR" || s.Entries[0].FxRateID != "usdinr" {
+		t.Errorf("bank main wallet statement of the disbursement: %+v", s)
+	}
+
+	// the buyer repays it in INR at the same rate, and the reversal undoes it
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "2txn", "repayment", "25/04/2018", "1loan", "3ins", "10", "1bus", "1bank", "pragadeesh", "", "usdinr")
+	if bal := walletBalance(t, n, c, "businesscc", "1bus", "main"); bal != "9200.00" {
+		t.Errorf("buyer main wallet after the repayment: %s", bal)
+	}
+	if bal := walletBalance(t, n, c, "bankcc", "1bank", "main"); bal != "1000.00" {
+		t.Errorf("bank main wallet after the repayment: %s", bal)
+	}
+	mustInvoke(t, n, c.checker, "txncc", "reverseTxn", "2txn", "wrong buyer", "checker")
+	if bal := walletBalance(t, n, c, "bankcc", "1bank", "main"); bal != "200.00" {
+		t.Errorf("bank main wallet after the reversal: %s", bal)
+	}
+	if bal := walletBalance(t, n, c, "loancc", "1loan", "disbursed"); bal != "10.00" {
+		t.Errorf("loan disbursed wallet after the reversal: %s", bal)
+	}
+}
@@ -0,0 +1,58 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	errcode "github.com/chaincode/Errcode"
+	history "github.com/chaincode/History"
+)
+
+func TestLoanHistory(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	n.Time = n.Time.Add(time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+
+	page := history.Page{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", "getLoanHistory", "1loan"), &page)
+	if page.FetchedRecordsCount != 2 || len(page.Records) != 2 || page.Bookmark != "" {
+		t.Fatalf("history of 1loan: %+v", page)
+	}
+	sanctioned, disbursed := page.Records[0], page.Records[1]
+	if sanctioned.TxID == "" || sanctioned.TxID == disbursed.TxID || !disbursed.Timestamp.After(sanctioned.Timestamp) {
+		t.Errorf("versions %s at %s and %s at %s", sanctioned.TxID, sanctioned.Timestamp, disbursed.TxID, disbursed.Timestamp)
+	}
+	if inv := sanctioned.Invoker; inv == nil || inv.MSPID != "Org1MSP" || inv.ID == "" || inv.Chaincode != "loancc" {
+		t.Errorf("sanctioned by %+v", inv)
+	}
+	// the disbursement updates the loan through txncc
+	if inv := disbursed.Invoker; inv == nil || inv.Chaincode != "txncc" {
+		t.Errorf("disbursed by %+v", inv)
+	}
+	loan := struct{ LoanStatus string }{}
+	json.Unmarshal(disbursed.Value, &loan)
+	if loan.LoanStatus != "disbursed" {
+		t.Errorf("status after the disbursement %q", loan.LoanStatus)
+	}
+
+	// a page at a time
+	first := history.Page{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", "getLoanHistory", "1loan", "1"), &first)
+	if len(first.Records) != 1 || first.Records[0].TxID != sanctioned.TxID || first.Bookmark != sanctioned.TxID {
+		t.Fatalf("first page: %+v", first)
+	}
+	second := history.Page{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", "getLoanHistory", "1loan", "1", first.Bookmark), &second)
+	if len(second.Records) != 1 || second.Records[0].TxID != disbursed.TxID || second.Bookmark != "" {
+		t.Errorf("second page: %+v", second)
+	}
+
+	failsWith(t, n.Invoke(c.ops, "loancc", "getLoanHistory", "1loan", "0"), errcode.InvalidArgument, "loancc")
+	failsWith(t, n.Invoke(c.ops, "loancc", "getLoanHistory", "9loan"), errcode.NotFound, "loancc")
+	failsWith(t, n.Invoke(c.maker, "loancc", "getLoanHistory", "1loan"), errcode.Unauthorized, "loancc")
+}
@@ -0,0 +1,200 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	bankcc "github.com/chaincode/Bank/bankcc"
+	businesscc "github.com/chaincode/Business/businesscc"
+	calendarcc "github.com/chaincode/Calendar/calendarcc"
+	errcode "github.com/chaincode/Errcode"
+	events "github.com/chaincode/Events"
+	instrumentcc "github.com/chaincode/Instrument/instrumentcc"
+	loancc "github.com/chaincode/Loan/loancc"
+	mocknet "github.com/chaincode/MockNet"
+	pprcc "github.com/chaincode/PPR/pprcc"
+	programcc "github.com/chaincode/Program/programcc"
+	txncc "github.com/chaincode/Transactions/txncc"
+	txnbalcc "github.com/chaincode/TxnBalance/txnbalcc"
+	walletcc "github.com/chaincode/Wallet/walletcc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+type clients struct {
+	admin, maker, checker, ops, outsider *mocknet.Client
+}
+
+// newNetwork installs every chaincode under the name it has on the channel
+func newNetwork(t *testing.T) (*mocknet.Network, clients) {
+	return install(t, mocknet.New(), "")
+}
+
+// install adds every chaincode to the network under its name with the suffix
+func install(t *testing.T, n *mocknet.Network, suffix string) (*mocknet.Network, clients) {
+	n.Add("bankcc"+suffix, new(bankcc.Chaincode))
+	n.Add("businesscc"+suffix, new(businesscc.Chaincode))
+	n.Add("calendarcc"+suffix, new(calendarcc.Chaincode))
+	n.Add("instrumentcc"+suffix, new(instrumentcc.Chaincode))
+	n.Add("loancc"+suffix, new(loancc.Chaincode))
+	n.Add("pprcc"+suffix, new(pprcc.Chaincode))
+	n.Add("programcc"+suffix, new(programcc.Chaincode))
+	n.Add("txnbalcc"+suffix, new(txnbalcc.Chaincode))
+	n.Add("txncc"+suffix, new(txncc.Chaincode))
+	n.Add("walletcc"+suffix, new(walletcc.Chaincode))
+
+	c := clients{}
+	for _, client := range []struct {
+		c     **mocknet.Client
+		name  string
+		roles string
+	}{
+		{&c.admin, "admin", "bank-admin"},
+		{&c.maker, "maker", "maker"},
+		{&c.checker, "checker", "checker"},
+		{&c.ops, "ops", "ops"},
+		{&c.outsider, "outsider", ""},
+	} {
+		var err error
+		*client.c, err = n.NewClient("Org1MSP", client.name, client.roles)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return n, c
+}
+
+func mustInvoke(t *testing.T, n *mocknet.Network, client *mocknet.Client, chaincode string, function string, args ...string) []byte {
+	t.Helper()
+	response := n.Invoke(client, chaincode, function, args...)
+	if response.Status != shim.OK {
+		t.Fatalf("%s %s: %s", chaincode, function, response.Message)
+	}
+	return response.Payload
+}
+
+func mustFail(t *testing.T, response pb.Response, what string) {
+	t.Helper()
+	if response.Status == shim.OK {
+		t.Fatalf("%s succeeded", what)
+	}
+}
+
+// onboard creates the bank, the buyer 1bus and seller 2bus, their program
+// and PPR and an instrument of 1000 on which a loan of 900 can be sanctioned.
+// The bank's main wallet starts with 1000 and the buyer's with 10000.
+func onboard(t *testing.T, n *mocknet.Network, c clients) {
+	mustInvoke(t, n, c.admin, "bankcc", "writeBankInfo", "1bank", "kvb", "chennai", "40A", "100000", "0", "0", "0", "0")
+	mustInvoke(t, n, c.maker, "businesscc", "putNewBusinessInfo", "1bus", "tata", "12348901", "4000000", "10000", "0", "10000", "12", "8", "0", "0")
+	mustInvoke(t, n, c.maker, "businesscc", "putNewBusinessInfo", "2bus", "mrf", "12348902", "4000000", "10000", "0", "0", "12", "8", "0", "0")
+	mustInvoke(t, n, c.maker, "programcc", "writeProgram", "1prg", "program1", "1bus", "Accounts_Payable", "10/04/2019", "10000", "6", "buyer", "4", "100", "pragadeesh", "123452")
+	mustInvoke(t, n, c.maker, "pprcc", "createPPR", "1ppr", "1prg", "2bus", "seller", "12000", "3", "100", "5", "40", "34tf2")
+	mustInvoke(t, n, c.maker, "instrumentcc", "enterInstrument", "1ins", "23/10/2018", "2bus", "1bus", "1000", "23/07/2019", "1prg", "1ppr", "34", "04/01/2018:12:43:59")
+}
+
+// post submits a transaction as the maker and approves it as the checker
+func post(t *testing.T, n *mocknet.Network, c clients, args ...string) string {
+	t.Helper()
+	reqID := mustInvoke(t, n, c.maker, "txncc", "submitTxn", args...)
+	return string(mustInvoke(t, n, c.checker, "txncc", "approveTxn", string(reqID)))
+}
+
+func walletBalance(t *testing.T, n *mocknet.Network, c clients, ccName string, id string, walletType string) string {
+	t.Helper()
+	walletID := mustInvoke(t, n, c.ops, ccName, "getWalletID", id, walletType)
+	return string(mustInvoke(t, n, c.ops, "walletcc", "getWallet", string(walletID)))
+}
+
+// eventTypes lists the types of the events of the last transaction sent
+// to the chaincode
+func eventTypes(n *mocknet.Network, chaincode string) []string {
+	env := events.Envelope{}
+	if n.Event(chaincode) != nil {
+		json.Unmarshal(n.Event(chaincode).Payload, &env)
+	}
+	types := []string{}
+	for _, event := range env.Events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func loanStatus(t *testing.T, n *mocknet.Network, c clients) string {
+	t.Helper()
+	return string(mustInvoke(t, n, c.ops, "loancc", "getLoanStatus", "1loan"))
+}
+
+func TestLoanLifecycle(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+
+	// Sanction, at most the instrument amount less the 5% discount of the
+	// seller
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "960", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	failsWith(t, n.Invoke(c.checker, "loancc", "approveLoan", string(reqID)), errcode.InvalidArgument, "loancc")
+	reqID = mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustFail(t, n.Invoke(c.maker, "loancc", "approveLoan", string(reqID)), "approval by the maker")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	if status := loanStatus(t, n, c); status != "sanctioned" {
+		t.Fatalf("loan status after sanction: %s", status)
+	}
+	if types := eventTypes(n, "loancc"); len(types) != 2 || types[0] != events.LoanSanctioned || types[1] != events.InstrumentStatusChanged {
+		t.Errorf("sanction events: %v", types)
+	}
+
+	// Disburse 900 from the bank to the seller
+	n.Time = n.Time.Add(24 * time.Hour)
+	txnID := post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+	if txnID != "1txn" {
+		t.Fatalf("disbursement posted as %s", txnID)
+	}
+	if status := loanStatus(t, n, c); status != "disbursed" {
+		t.Fatalf("loan status after disbursement: %s", status)
+	}
+	if bal := walletBalance(t, n, c, "bankcc", "1bank", "main"); bal != "100.00" {
+		t.Errorf("bank main wallet after disbursement: %s", bal)
+	}
+	if bal := walletBalance(t, n, c, "loancc", "1loan", "disbursed"); bal != "900.00" {
+		t.Errorf("loan disbursed wallet after disbursement: %s", bal)
+	}
+	if types := eventTypes(n, "txncc"); len(types) == 0 || types[0] != events.DisbursementPosted {
+		t.Errorf("disbursement events: %v", types)
+	}
+
+	// Accrue 20 of interest on the loan
+	n.Time = n.Time.Add(30 * 24 * time.Hour)
+	post(t, n, c, "2txn", "accrual", "24/05/2018", "1loan", "1ins", "20", "2bus", "1bank", "pragadeesh")
+	if bal := walletBalance(t, n, c, "loancc", "1loan", "accrued"); bal != "20.00" {
+		t.Errorf("loan accrued wallet after accrual: %s", bal)
+	}
+
+	// The buyer repays the interest and the principal and the loan is settled
+	n.Time = n.Time.Add(60 * 24 * time.Hour)
+	post(t, n, c, "3txn", "repayment", "23/07/2018", "1loan", "1ins", "920", "1bus", "1bank", "pragadeesh")
+	if status := loanStatus(t, n, c); status != "collected" {
+		t.Fatalf("loan status after repayment: %s", status)
+	}
+	if bal := walletBalance(t, n, c, "bankcc", "1bank", "main"); bal != "1020.00" {
+		t.Errorf("bank main wallet after repayment: %s", bal)
+	}
+	if bal := walletBalance(t, n, c, "businesscc", "1bus", "main"); bal != "9080.00" {
+		t.Errorf("buyer main wallet after repayment: %s", bal)
+	}
+	if bal := walletBalance(t, n, c, "loancc", "1loan", "disbursed"); bal != "0.00" {
+		t.Errorf("loan disbursed wallet after repayment: %s", bal)
+	}
+
+	// Reversing the repayment puts the loan and the wallets back
+	mustInvoke(t, n, c.checker, "txncc", "reverseTxn", "3txn", "wrong buyer", "checker")
+	if status := loanStatus(t, n, c); status != "disbursed" {
+		t.Fatalf("loan status after reversal: %s", status)
+	}
+	if bal := walletBalance(t, n, c, "bankcc", "1bank", "main"); bal != "100.00" {
+		t.Errorf("bank main wallet after reversal: %s", bal)
+	}
+	if bal := walletBalance(t, n, c, "businesscc", "1bus", "main"); bal != "10000.00" {
+		t.Errorf("buyer main wallet after reversal: %s", bal)
+	}
+	mustFail(t, n.Invoke(c.checker, "txncc", "reverseTxn", "3txn", "again", "checker"), "second reversal")
+}
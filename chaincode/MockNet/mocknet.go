@@ -0,0 +1,330 @@
+// Package mocknet runs chaincodes on shim.MockStubs wired together, so that
+// their InvokeChaincode calls reach each other as they do on a peer, and
+// invokes them as clients with an MSP ID and certificate roles.
+//
+// Every chaincode sees the client of the transaction, the chaincode the
+// client invoked (in the signed proposal, which is what auth.Policy checks
+// callers with) and the transaction time of the Network, can read the history
+// of its keys and can run CouchDB queries on its state.
+//
+// As on a peer, a transaction reads the state as it was committed before it:
+// what it writes, in any of the chaincodes it reaches, is not seen by its own
+// reads, and only the last write of a key is kept. The writes are committed,
+// and added to the history of their keys, when the invoked chaincode
+// succeeds, and dropped when it fails.
+package mocknet
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/common/attrmgr"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Channel is the channel the chaincodes of a New network call each other on
+const Channel = "myc"
+
+// Client is an identity that invokes the chaincodes
+type Client struct {
+	MSPID   string
+	Name    string
+	creator []byte
+}
+
+// Network is a set of chaincodes that can call each other
+type Network struct {
+	// Time is the timestamp of the next transactions
+	Time time.Time
+
+	channel string
+	stubs   map[string]*shim.MockStub
+	history map[string]map[string][]*queryresult.KeyModification
+	events  map[string]*pb.ChaincodeEvent
+	client  *Client
+	invoked string
+	txns    int
+	// writes of the running transaction, by chaincode, committed when it succeeds
+	writes map[string]*writeSet
+}
+
+// writeSet is what a transaction wrote to the state of a chaincode, in order
+type writeSet struct {
+	keys   []string
+	values map[string][]byte // nil for a delete
+}
+
+func (w *writeSet) put(key string, value []byte) {
+	if _, ok := w.values[key]; !ok {
+		w.keys = append(w.keys, key)
+	}
+	w.values[key] = append([]byte(nil), value...)
+	if len(value) == 0 {
+		w.values[key] = nil
+	}
+}
+
+// New returns an empty network on Channel
+func New() *Network {
+	return NewOnChannel(Channel)
+}
+
+// NewOnChannel returns an empty network on the channel
+func NewOnChannel(channel string) *Network {
+	return &Network{
+		Time:    time.Date(2018, time.April, 23, 10, 0, 0, 0, time.UTC),
+		channel: channel,
+		stubs:   map[string]*shim.MockStub{},
+		history: map[string]map[string][]*queryresult.KeyModification{},
+		events:  map[string]*pb.ChaincodeEvent{},
+		writes:  map[string]*writeSet{},
+	}
+}
+
+// Add installs the chaincode under the name. Every chaincode of the network
+// can call it, and it can call every other one.
+func (n *Network) Add(name string, cc shim.Chaincode) {
+	stub := shim.NewMockStub(name, &clientChaincode{n, name, cc})
+	n.history[name] = map[string][]*queryresult.KeyModification{}
+	for other, otherStub := range n.stubs {
+		otherStub.MockPeerChaincode(name+"/"+n.channel, stub)
+		stub.MockPeerChaincode(other+"/"+n.channel, otherStub)
+	}
+	n.stubs[name] = stub
+}
+
+// Stub returns the MockStub of the chaincode, to look at its state
+func (n *Network) Stub(name string) *shim.MockStub {
+	return n.stubs[name]
+}
+
+// NewClient returns a client of the MSP whose certificate has the roles in
+// its role attribute, comma separated
+func (n *Network) NewClient(mspID string, name string, roles string) (*Client, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(len(name) + 1)),
+		Subject:      pkix.Name{CommonName: name, Organization: []string{mspID}},
+		NotBefore:    n.Time.Add(-time.Hour),
+		NotAfter:     n.Time.Add(24 * 365 * time.Hour),
+	}
+	if roles != "" {
+		attrs, err := json.Marshal(&attrmgr.Attributes{Attrs: map[string]string{"role": roles}})
+		if err != nil {
+			return nil, err
+		}
+		template.ExtraExtensions = []pkix.Extension{{Id: attrmgr.AttrOID, Value: attrs}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	sid := &msp.SerializedIdentity{Mspid: mspID, IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+	creator, err := proto.Marshal(sid)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{MSPID: mspID, Name: name, creator: creator}, nil
+}
+
+// Init instantiates the chaincode with the arguments
+func (n *Network) Init(chaincode string, args ...string) pb.Response {
+	stub, ok := n.stubs[chaincode]
+	if !ok {
+		return shim.Error("mocknet: no chaincode " + chaincode)
+	}
+	n.txns++
+	txID := "tx" + strconv.Itoa(n.txns)
+	response := stub.MockInit(txID, toChaincodeArgs("init", args...))
+	n.commit(txID, response)
+	return response
+}
+
+// Invoke sends a transaction from the client to the chaincode
+func (n *Network) Invoke(client *Client, chaincode string, function string, args ...string) pb.Response {
+	stub, ok := n.stubs[chaincode]
+	if !ok {
+		return shim.Error("mocknet: no chaincode " + chaincode)
+	}
+	n.txns++
+	n.client, n.invoked = client, chaincode
+	defer func() { n.client, n.invoked = nil, "" }()
+
+	txID := "tx" + strconv.Itoa(n.txns)
+	response := stub.MockInvoke(txID, toChaincodeArgs(function, args...))
+	n.commit(txID, response)
+
+	// Only the event of the invoked chaincode reaches a block listener
+	for name, s := range n.stubs {
+		for len(s.ChaincodeEventsChannel) > 0 {
+			event := <-s.ChaincodeEventsChannel
+			if name == chaincode {
+				n.events[name] = event
+			}
+		}
+	}
+	return response
+}
+
+// commit writes what the transaction wrote to the state of every chaincode,
+// and to its history, when the response is a success. A failed transaction
+// leaves no writes.
+func (n *Network) commit(txID string, response pb.Response) {
+	writes := n.writes
+	n.writes = map[string]*writeSet{}
+	if response.Status >= shim.ERRORTHRESHOLD {
+		return
+	}
+	ts := &timestamp.Timestamp{Seconds: n.Time.Unix(), Nanos: int32(n.Time.Nanosecond())}
+	for name, w := range writes {
+		stub := n.stubs[name]
+		stub.MockTransactionStart(txID)
+		for _, key := range w.keys {
+			value := w.values[key]
+			if value == nil {
+				stub.DelState(key)
+			} else {
+				stub.PutState(key, value)
+			}
+			mod := &queryresult.KeyModification{TxId: txID, Value: value, Timestamp: ts, IsDelete: value == nil}
+			n.history[name][key] = append(n.history[name][key], mod)
+		}
+		stub.MockTransactionEnd(txID)
+	}
+}
+
+// Event returns the last chaincode event set by a transaction sent to the
+// chaincode, or nil
+func (n *Network) Event(chaincode string) *pb.ChaincodeEvent {
+	return n.events[chaincode]
+}
+
+// clientChaincode runs the chaincode with a stub that knows the client and
+// the time of the network's transaction
+type clientChaincode struct {
+	n    *Network
+	name string
+	cc   shim.Chaincode
+}
+
+func (c *clientChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return c.cc.Init(&clientStub{stub, c.n, c.name})
+}
+
+func (c *clientChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	return c.cc.Invoke(&clientStub{stub, c.n, c.name})
+}
+
+// clientStub reads the committed state of the chaincode and keeps its writes
+// in the write set of the transaction, as a peer does: a transaction does not
+// read its own writes, whichever chaincode of it made them
+type clientStub struct {
+	shim.ChaincodeStubInterface
+	n    *Network
+	name string
+}
+
+func (s *clientStub) PutState(key string, value []byte) error {
+	if key == "" {
+		return errors.New("mocknet: empty key")
+	}
+	s.writeSet().put(key, value)
+	return nil
+}
+
+func (s *clientStub) DelState(key string) error {
+	s.writeSet().put(key, nil)
+	return nil
+}
+
+func (s *clientStub) writeSet() *writeSet {
+	w, ok := s.n.writes[s.name]
+	if !ok {
+		w = &writeSet{values: map[string][]byte{}}
+		s.n.writes[s.name] = w
+	}
+	return w
+}
+
+func (s *clientStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return &historyIterator{mods: s.n.history[s.name][key]}, nil
+}
+
+func (s *clientStub) GetCreator() ([]byte, error) {
+	if s.n.client == nil {
+		return nil, errors.New("mocknet: no client")
+	}
+	return s.n.client.creator, nil
+}
+
+func (s *clientStub) GetSignedProposal() (*pb.SignedProposal, error) {
+	ext, err := proto.Marshal(&pb.ChaincodeHeaderExtension{ChaincodeId: &pb.ChaincodeID{Name: s.n.invoked}})
+	if err != nil {
+		return nil, err
+	}
+	chdr, err := proto.Marshal(&common.ChannelHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION), ChannelId: s.n.channel, TxId: s.GetTxID(), Extension: ext})
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := proto.Marshal(&common.Header{ChannelHeader: chdr})
+	if err != nil {
+		return nil, err
+	}
+	prop, err := proto.Marshal(&pb.Proposal{Header: hdr})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SignedProposal{ProposalBytes: prop}, nil
+}
+
+func (s *clientStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return &timestamp.Timestamp{Seconds: s.n.Time.Unix(), Nanos: int32(s.n.Time.Nanosecond())}, nil
+}
+
+// historyIterator goes through the writes to a key, oldest first
+type historyIterator struct {
+	mods []*queryresult.KeyModification
+	next int
+}
+
+func (it *historyIterator) HasNext() bool {
+	return it.next < len(it.mods)
+}
+
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, errors.New("mocknet: no more history")
+	}
+	it.next++
+	return it.mods[it.next-1], nil
+}
+
+func (it *historyIterator) Close() error {
+	return nil
+}
+
+func toChaincodeArgs(function string, args ...string) [][]byte {
+	bargs := [][]byte{[]byte(function)}
+	for _, arg := range args {
+		bargs = append(bargs, []byte(arg))
+	}
+	return bargs
+}
@@ -0,0 +1,45 @@
+package mocknet_test
+
+import (
+	"testing"
+	"time"
+
+	errcode "github.com/chaincode/Errcode"
+)
+
+func TestPostingRules(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+
+	// the interest accrued on the loan is owed by the seller, the txn type
+	// taken in any case
+	post(t, n, c, "2txn", "Accrual", "24/05/2018", "1loan", "1ins", "20", "1bank", "2bus", "pragadeesh")
+	for _, w := range []struct{ ccName, id, walletType, want string }{
+		{"loancc", "1loan", "accrued", "20.00"},
+		{"businesscc", "2bus", "chargesOut", "20.00"},
+		{"businesscc", "2bus", "principalOut", "900.00"},
+	} {
+		if bal := walletBalance(t, n, c, w.ccName, w.id, w.walletType); bal != w.want {
+			t.Errorf("%s %s wallet after the accrual: %s", w.id, w.walletType, bal)
+		}
+	}
+
+	// a rule whose memo legs do not balance is refused when it is posted
+	mustInvoke(t, n, c.admin, "txncc", "putPostingRule", "accrual", `{"bankIs":"from","legs":[{"role":"loan","walletType":"accrued","side":"debit","amount":"amount"}]}`)
+	reqID = mustInvoke(t, n, c.maker, "txncc", "submitTxn", "3txn", "accrual", "25/05/2018", "1loan", "1ins", "5", "1bank", "2bus", "pragadeesh")
+	failsWith(t, n.Invoke(c.checker, "txncc", "approveTxn", string(reqID)), errcode.Internal, "txncc")
+	if bal := walletBalance(t, n, c, "loancc", "1loan", "accrued"); bal != "20.00" {
+		t.Errorf("loan accrued wallet after the refused accrual: %s", bal)
+	}
+
+	// interest collected pays down what the seller owes
+	mustInvoke(t, n, c.admin, "programcc", "putWaterfall", "1prg", "interest,penal,charges,principal")
+	post(t, n, c, "4txn", "repayment", "26/05/2018", "1loan", "1ins", "500", "1bus", "1bank", "pragadeesh")
+	if bal := walletBalance(t, n, c, "businesscc", "2bus", "chargesOut"); bal != "0.00" {
+		t.Errorf("seller chargesOut wallet after the repayment: %s", bal)
+	}
+}
@@ -0,0 +1,279 @@
+package mocknet
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// GetQueryResult runs a CouchDB query on the state of the chaincode. Only the
+// selector and the sort of the query are used, and the selector may use the
+// operators $eq $ne $gt $gte $lt $lte $in $nin $exists $regex $and $or $nor
+// and $not. Indexes are not needed.
+func (s *clientStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	kvs, err := s.query(query)
+	if err != nil {
+		return nil, err
+	}
+	return &queryIterator{kvs: kvs}, nil
+}
+
+// GetQueryResultWithPagination runs a query as GetQueryResult does and
+// returns a page of the results. The bookmark is the key of the last result
+// of the previous page.
+func (s *clientStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	kvs, err := s.query(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	start := 0
+	if bookmark != "" {
+		for start < len(kvs) && kvs[start].Key != bookmark {
+			start++
+		}
+		if start == len(kvs) {
+			return nil, nil, errors.New("mocknet: invalid bookmark " + bookmark)
+		}
+		start++
+	}
+	end := len(kvs)
+	if pageSize > 0 && start+int(pageSize) < end {
+		end = start + int(pageSize)
+	}
+	kvs = kvs[start:end]
+
+	meta := &pb.QueryResponseMetadata{FetchedRecordsCount: int32(len(kvs)), Bookmark: bookmark}
+	if len(kvs) > 0 {
+		meta.Bookmark = kvs[len(kvs)-1].Key
+	}
+	return &queryIterator{kvs: kvs}, meta, nil
+}
+
+// query returns the JSON values of the state that match the query, in key
+// order unless it is sorted
+func (s *clientStub) query(query string) ([]*queryresult.KV, error) {
+	q := struct {
+		Selector map[string]interface{} `json:"selector"`
+		Sort     []interface{}          `json:"sort"`
+	}{}
+	err := json.Unmarshal([]byte(query), &q)
+	if err != nil {
+		return nil, errors.New("mocknet: invalid query: " + err.Error())
+	} else if q.Selector == nil {
+		return nil, errors.New("mocknet: the query has no selector")
+	}
+
+	it, err := s.GetStateByRange("", "")
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	kvs := []*queryresult.KV{}
+	for it.HasNext() {
+		kv, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		var doc interface{}
+		if json.Unmarshal(kv.Value, &doc) != nil {
+			continue
+		}
+		if _, ok := doc.(map[string]interface{}); ok && matches(doc, q.Selector) {
+			kvs = append(kvs, kv)
+		}
+	}
+
+	for i := len(q.Sort) - 1; i >= 0; i-- {
+		field, desc := "", false
+		switch by := q.Sort[i].(type) {
+		case string:
+			field = by
+		case map[string]interface{}:
+			for f, dir := range by {
+				field, desc = f, dir == "desc"
+			}
+		}
+		sort.SliceStable(kvs, func(a, b int) bool {
+			var docA, docB interface{}
+			json.Unmarshal(kvs[a].Value, &docA)
+			json.Unmarshal(kvs[b].Value, &docB)
+			valA, _ := lookup(docA, field)
+			valB, _ := lookup(docB, field)
+			if desc {
+				return collate(valB, valA) < 0
+			}
+			return collate(valA, valB) < 0
+		})
+	}
+	return kvs, nil
+}
+
+// matches says whether the document matches the selector
+func matches(doc interface{}, selector map[string]interface{}) bool {
+	for field, cond := range selector {
+		switch field {
+		case "$and", "$or", "$nor":
+			subs, _ := cond.([]interface{})
+			n := 0
+			for _, sub := range subs {
+				subSel, _ := sub.(map[string]interface{})
+				if matches(doc, subSel) {
+					n++
+				}
+			}
+			if (field == "$and" && n != len(subs)) || (field == "$or" && n == 0) || (field == "$nor" && n != 0) {
+				return false
+			}
+			continue
+		case "$not":
+			subSel, _ := cond.(map[string]interface{})
+			if matches(doc, subSel) {
+				return false
+			}
+			continue
+		}
+
+		val, found := lookup(doc, field)
+		ops, isOps := cond.(map[string]interface{})
+		if isOps {
+			for op := range ops {
+				if !strings.HasPrefix(op, "$") {
+					isOps = false
+				}
+			}
+		}
+		if !isOps {
+			ops = map[string]interface{}{"$eq": cond}
+		}
+		for op, arg := range ops {
+			if !satisfies(val, found, op, arg) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// satisfies says whether the value of a field is as the operator wants
+func satisfies(val interface{}, found bool, op string, arg interface{}) bool {
+	if op == "$exists" {
+		return found == (arg == true)
+	} else if !found {
+		return false
+	}
+	switch op {
+	case "$eq":
+		return collate(val, arg) == 0
+	case "$ne":
+		return collate(val, arg) != 0
+	case "$gt", "$gte", "$lt", "$lte":
+		if kind(val) != kind(arg) {
+			return false
+		}
+		c := collate(val, arg)
+		return (op == "$gt" && c > 0) || (op == "$gte" && c >= 0) || (op == "$lt" && c < 0) || (op == "$lte" && c <= 0)
+	case "$in", "$nin":
+		in := false
+		list, _ := arg.([]interface{})
+		for _, item := range list {
+			in = in || collate(val, item) == 0
+		}
+		return in == (op == "$in")
+	case "$regex":
+		str, ok := val.(string)
+		pattern, _ := arg.(string)
+		re, err := regexp.Compile(pattern)
+		return ok && err == nil && re.MatchString(str)
+	}
+	return false
+}
+
+// lookup returns the value of a field, which is a dotted path in the document
+func lookup(doc interface{}, field string) (interface{}, bool) {
+	for _, name := range strings.Split(field, ".") {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		doc, ok = obj[name]
+		if !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// kind is the rank of the type of a JSON value in CouchDB's collation
+func kind(val interface{}) int {
+	switch val.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	case []interface{}:
+		return 4
+	}
+	return 5
+}
+
+// collate compares two JSON values as CouchDB orders them
+func collate(a interface{}, b interface{}) int {
+	if kind(a) != kind(b) {
+		return kind(a) - kind(b)
+	}
+	switch a := a.(type) {
+	case bool:
+		if a == b.(bool) {
+			return 0
+		} else if !a {
+			return -1
+		}
+		return 1
+	case float64:
+		if a < b.(float64) {
+			return -1
+		} else if a > b.(float64) {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	case nil:
+		return 0
+	}
+	aBytes, _ := json.Marshal(a)
+	bBytes, _ := json.Marshal(b)
+	return strings.Compare(string(aBytes), string(bBytes))
+}
+
+// queryIterator goes through the results of a query
+type queryIterator struct {
+	kvs  []*queryresult.KV
+	next int
+}
+
+func (it *queryIterator) HasNext() bool {
+	return it.next < len(it.kvs)
+}
+
+func (it *queryIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, errors.New("mocknet: no more results")
+	}
+	it.next++
+	return it.kvs[it.next-1], nil
+}
+
+func (it *queryIterator) Close() error {
+	return nil
+}
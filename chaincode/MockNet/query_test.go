@@ -0,0 +1,87 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	errcode "github.com/chaincode/Errcode"
+	mocknet "github.com/chaincode/MockNet"
+	query "github.com/chaincode/Query"
+)
+
+// loanKeys runs a loancc query and returns the loan IDs and the bookmark
+func loanKeys(t *testing.T, n *mocknet.Network, c clients, function string, args ...string) ([]string, string) {
+	t.Helper()
+	page := query.Page{}
+	err := json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", function, args...), &page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{}
+	for _, result := range page.Records {
+		keys = append(keys, result.Key)
+	}
+	if page.FetchedRecordsCount != len(keys) {
+		t.Errorf("%s: fetched %d records, counted %d", function, len(keys), page.FetchedRecordsCount)
+	}
+	return keys, page.Bookmark
+}
+
+func TestQueryLoans(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	mustInvoke(t, n, c.maker, "instrumentcc", "enterInstrument", "2ins", "23/10/2018", "2bus", "1bus", "1000", "23/07/2019", "1prg", "1ppr", "34", "04/01/2018:12:43:59")
+
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	reqID = mustInvoke(t, n, c.maker, "loancc", "submitLoan", "2loan", "2ins", "1bus", "1prg", "800", "pragadeesh", "5", "10/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+
+	for _, q := range []struct {
+		function string
+		args     []string
+		want     []string
+	}{
+		{"getLoansByStatus", []string{"sanctioned"}, []string{"2loan"}},
+		{"getLoansByStatus", []string{"collected"}, []string{}},
+		{"getLoansByBuyer", []string{"1bus"}, []string{"1loan", "2loan"}},
+		{"getLoansBySeller", []string{"1bus"}, []string{}},
+		{"getLoansByProgram", []string{"1prg"}, []string{"1loan", "2loan"}},
+		// due dates are stored a day after the one given, and soonest first
+		{"getLoansByDueDate", []string{"01/10/2018", "31/10/2018"}, []string{"2loan", "1loan"}},
+		{"getLoansByDueDate", []string{"12/10/2018", "24/10/2018"}, []string{"1loan"}},
+		{"queryLoans", []string{`{"LoanStatus":{"$in":["sanctioned","disbursed"]},"SanctionAmt.value":"800.00"}`}, []string{"2loan"}},
+		// only loans, not the loan requests or the other records of loancc
+		{"queryLoans", []string{`{}`}, []string{"1loan", "2loan"}},
+	} {
+		keys, bookmark := loanKeys(t, n, c, q.function, q.args...)
+		if len(keys) != len(q.want) || bookmark != "" {
+			t.Errorf("%s %v: %v bookmark %q, expected %v", q.function, q.args, keys, bookmark, q.want)
+			continue
+		}
+		for i := range keys {
+			if keys[i] != q.want[i] {
+				t.Errorf("%s %v: %v, expected %v", q.function, q.args, keys, q.want)
+			}
+		}
+	}
+
+	// a page at a time
+	first, bookmark := loanKeys(t, n, c, "getLoansByBuyer", "1bus", "1")
+	if len(first) != 1 || first[0] != "1loan" || bookmark == "" {
+		t.Fatalf("first page %v bookmark %q", first, bookmark)
+	}
+	second, bookmark := loanKeys(t, n, c, "getLoansByBuyer", "1bus", "1", bookmark)
+	if len(second) != 1 || second[0] != "2loan" || bookmark == "" {
+		t.Fatalf("second page %v bookmark %q", second, bookmark)
+	}
+	last, bookmark := loanKeys(t, n, c, "getLoansByBuyer", "1bus", "1", bookmark)
+	if len(last) != 0 || bookmark != "" {
+		t.Errorf("last page %v bookmark %q", last, bookmark)
+	}
+
+	failsWith(t, n.Invoke(c.ops, "loancc", "queryLoans", `["LoanStatus"]`), errcode.InvalidArgument, "loancc")
+	failsWith(t, n.Invoke(c.ops, "loancc", "getLoansByStatus", "sanctioned", "101"), errcode.InvalidArgument, "loancc")
+	failsWith(t, n.Invoke(c.ops, "loancc", "getLoansByDueDate", "2018-10-01", "31/10/2018"), errcode.InvalidArgument, "loancc")
+}
@@ -0,0 +1,54 @@
+package mocknet_test
+
+import (
+	"strings"
+	"testing"
+
+	errcode "github.com/chaincode/Errcode"
+)
+
+func TestJSONArguments(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", `{"loanID":"1loan","instrumentID":"1ins","exposureBusinessID":"1bus","programID":"1prg",
+		"sanctionAmt":900,"sanctionAuthority":"pragadeesh","roi":5,"dueDate":"23/10/2018","valueDate":"25/09/2018:20:45:01",
+		"disbursedWalletAmt":0,"chargesWalletAmt":0,"accruedWalletAmt":0,"buyerID":"1bus","sellerID":"2bus"}`)
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	if status := loanStatus(t, n, c); status != "sanctioned" {
+		t.Fatalf("loan status after sanction: %s", status)
+	}
+
+	// The optional clientRequestID is left out
+	reqID = mustInvoke(t, n, c.maker, "txncc", "submitTxn", `{"txnID":"1txn","txnType":"disbursement","txnDate":"24/04/2018","loanID":"1loan",
+		"instrumentID":"1ins","amount":"900","from":"1bank","to":"2bus","by":"pragadeesh"}`)
+	mustInvoke(t, n, c.checker, "txncc", "approveTxn", string(reqID))
+	if status := loanStatus(t, n, c); status != "disbursed" {
+		t.Fatalf("loan status after disbursement: %s", status)
+	}
+
+	// Every field in error is named
+	env := failsWith(t, n.Invoke(c.maker, "txncc", "submitTxn", `{"txnID":"2txn","txnType":"accrual","txnDate":"2018-05-24","loanID":"1loan",
+		"instrumentID":"1ins","amount":"twenty","from":"2bus","to":"1bank","note":"x"}`), errcode.InvalidArgument, "txncc")
+	for _, want := range []string{`txnDate: must be a date as dd/mm/yyyy, not "2018-05-24"`, `amount: must be a number, not "twenty"`, "by: is required", "note: is not a field of submitTxn"} {
+		if !strings.Contains(env.Message, want) {
+			t.Errorf("%q does not say %q", env.Message, want)
+		}
+	}
+
+	// The positional form is checked too
+	env = failsWith(t, n.Invoke(c.maker, "txncc", "submitTxn", "2txn", "accrual", "24/05/2018", "1loan", "1ins", "twenty", "2bus", "1bank", "pragadeesh"), errcode.InvalidArgument, "txncc")
+	if !strings.Contains(env.Message, `amount: must be a number, not "twenty"`) {
+		t.Errorf("positional submitTxn: %s", env.Message)
+	}
+
+	// updates take named arguments too
+	env = failsWith(t, n.Invoke(c.admin, "businesscc", "updateBusinessInfo", `{"businessID":"1bus","field":"business limit"}`), errcode.InvalidArgument, "businesscc")
+	if !strings.Contains(env.Message, "value: is required") {
+		t.Errorf("updateBusinessInfo without a value: %s", env.Message)
+	}
+	mustInvoke(t, n, c.admin, "businesscc", "updateBusinessInfo", `{"businessID":"1bus","field":"business limit","value":5000}`)
+	if info := string(n.Stub("businesscc").State["1bus"]); !strings.Contains(info, `"Limit":5000`) {
+		t.Errorf("business after the update: %s", info)
+	}
+}
@@ -0,0 +1,142 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	errcode "github.com/chaincode/Errcode"
+	mocknet "github.com/chaincode/MockNet"
+	money "github.com/chaincode/Money"
+)
+
+// statement is the wallet statement walletcc returns
+type statement struct {
+	OpeningBal money.Amount
+	Entries    []struct {
+		TxnID      string
+		CAmt       money.Amount
+		DAmt       money.Amount
+		ClosingBal money.Amount
+		FxRateID   string
+	}
+	ClosingBal money.Amount
+}
+
+func walletStatement(t *testing.T, n *mocknet.Network, c clients, walletID string, from string, to string) statement {
+	t.Helper()
+	s := statement{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "walletcc", "getWalletStatement", walletID, from, to), &s)
+	return s
+}
+
+func TestWalletStatement(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+
+	// The bank's main wallet opens with 1000 on 23/04, pays out 500 on 24/04
+	// and 400 on 25/04 and is repaid 900 on 22/06
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "500", "1bank", "2bus", "pragadeesh")
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "2txn", "disbursement", "25/04/2018", "1loan", "1ins", "400", "1bank", "2bus", "pragadeesh")
+	n.Time = n.Time.Add(58 * 24 * time.Hour)
+	post(t, n, c, "3txn", "repayment", "22/06/2018", "1loan", "1ins", "900", "1bus", "1bank", "pragadeesh")
+	walletID := string(mustInvoke(t, n, c.ops, "bankcc", "getWalletID", "1bank", "main"))
+
+	for _, test := range []struct {
+		from, to         string
+		opening, closing string
+		moved            []string
+	}{
+		{"23/04/2018", "23/04/2018", "0.00", "1000.00", []string{"+1000.00"}},
+		{"24/04/2018", "30/04/2018", "1000.00", "100.00", []string{"-500.00", "-400.00"}},
+		{"25/04/2018", "25/04/2018", "500.00", "100.00", []string{"-400.00"}},
+		{"01/05/2018", "01/06/2018", "100.00", "100.00", nil},
+		{"01/05/2018", "30/06/2018", "100.00", "1000.00", []string{"+900.00"}},
+	} {
+		s := walletStatement(t, n, c, walletID, test.from, test.to)
+		if s.OpeningBal.String() != test.opening || s.ClosingBal.String() != test.closing || len(s.Entries) != len(test.moved) {
+			t.Errorf("statement %s to %s: %+v", test.from, test.to, s)
+			continue
+		}
+		bal := s.OpeningBal
+		for i, entry := range s.Entries {
+			moved := "+" + entry.CAmt.String()
+			if entry.CAmt.IsZero() {
+				moved = "-" + entry.DAmt.String()
+			}
+			bal, _ = bal.Add(entry.CAmt)
+			bal, _ = bal.Sub(entry.DAmt)
+			if moved != test.moved[i] || entry.TxnID == "" || entry.ClosingBal.String() != bal.String() {
+				t.Errorf("statement %s to %s, entry %d: %+v, expected %s", test.from, test.to, i, entry, test.moved[i])
+			}
+		}
+	}
+	failsWith(t, n.Invoke(c.ops, "walletcc", "getWalletStatement", walletID, "30/04/2018", "24/04/2018"), errcode.InvalidArgument, "walletcc")
+}
+
+func TestWalletLegs(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+	post(t, n, c, "2txn", "accrual", "24/05/2018", "1loan", "1ins", "20", "1bank", "2bus", "pragadeesh")
+
+	// the bank's charges wallet is credited and debited by the same txn, and
+	// the seller's chargesOut debited twice by the repayment
+	mustInvoke(t, n, c.admin, "txncc", "putPostingRule", "charges", `{"bankIs":"from","legs":[`+
+		`{"role":"bank","walletType":"charges","side":"credit","amount":"amount"},`+
+		`{"role":"seller","walletType":"chargesOut","side":"credit","amount":"amount"},`+
+		`{"role":"loan","walletType":"charges","side":"debit","amount":"amount"},`+
+		`{"role":"bank","walletType":"charges","side":"debit","amount":"amount"}]}`)
+	n.Time = n.Time.Add(time.Hour)
+	post(t, n, c, "3txn", "charges", "25/05/2018", "1loan", "1ins", "10", "1bank", "2bus", "pragadeesh")
+	n.Time = n.Time.Add(time.Hour)
+	post(t, n, c, "4txn", "repayment", "26/05/2018", "1loan", "1ins", "500", "1bus", "1bank", "pragadeesh")
+
+	for _, test := range []struct {
+		ccName, id, walletType, txnID string
+		moved                         []string
+	}{
+		{"bankcc", "1bank", "charges", "3txn", []string{"+10.00", "-10.00"}},
+		{"businesscc", "2bus", "chargesOut", "4txn", []string{"-10.00", "-20.00"}},
+	} {
+		walletID := string(mustInvoke(t, n, c.ops, test.ccName, "getWalletID", test.id, test.walletType))
+		s := walletStatement(t, n, c, walletID, "23/04/2018", "31/05/2018")
+		moved, legs := []string{}, map[string]bool{}
+		bal := s.OpeningBal
+		for i, entry := range s.Entries {
+			if !strings.HasPrefix(entry.TxnID, test.txnID+"_") {
+				bal = entry.ClosingBal
+				continue
+			}
+			if entry.CAmt.IsZero() {
+				moved = append(moved, "-"+entry.DAmt.String())
+			} else {
+				moved = append(moved, "+"+entry.CAmt.String())
+			}
+			legs[entry.TxnID] = true
+			bal, _ = bal.Add(entry.CAmt)
+			bal, _ = bal.Sub(entry.DAmt)
+			if entry.ClosingBal.String() != bal.String() {
+				t.Errorf("%s %s wallet, entry %d: %+v, expected closing %s", test.id, test.walletType, i, entry, bal)
+			}
+		}
+		if len(moved) != len(test.moved) || len(legs) != len(test.moved) {
+			t.Errorf("%s %s wallet moved by %s: %v in %d legs, expected %v", test.id, test.walletType, test.txnID, moved, len(legs), test.moved)
+			continue
+		}
+		for i := range moved {
+			if moved[i] != test.moved[i] {
+				t.Errorf("%s %s wallet moved by %s: %v, expected %v", test.id, test.walletType, test.txnID, moved, test.moved)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	errcode "github.com/chaincode/Errcode"
+	history "github.com/chaincode/History"
+	mocknet "github.com/chaincode/MockNet"
+)
+
+// allowedTransitions returns the statuses the loan can move to now
+func allowedTransitions(t *testing.T, n *mocknet.Network, c clients, loanID string) []string {
+	t.Helper()
+	allowed := []struct{ To string }{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", "getAllowedTransitions", loanID), &allowed)
+	statuses := []string{}
+	for _, a := range allowed {
+		statuses = append(statuses, a.To)
+	}
+	return statuses
+}
+
+// instrumentStatuses returns the statuses the instrument had, oldest first
+func instrumentStatuses(t *testing.T, n *mocknet.Network, c clients, refNo string, sellerID string) []string {
+	t.Helper()
+	page := history.Page{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "instrumentcc", "getInstrumentHistory", refNo, sellerID), &page)
+	statuses := []string{}
+	for _, version := range page.Records {
+		inst := struct{ Status string }{}
+		json.Unmarshal(version.Value, &inst)
+		statuses = append(statuses, inst.Status)
+	}
+	return statuses
+}
+
+func TestLoanTransitions(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+
+	if allowed := allowedTransitions(t, n, c, "1loan"); len(allowed) != 3 || allowed[0] != "part_disbursed" || allowed[1] != "disbursed" || allowed[2] != "cancelled" {
+		t.Errorf("allowed from sanctioned: %v", allowed)
+	}
+	// only a posted disbursement disburses the loan, and it is not due yet
+	failsWith(t, n.Invoke(c.checker, "loancc", "changeLoanStatus", "1loan", "disbursed", "paid out"), errcode.InvalidState, "loancc")
+	failsWith(t, n.Invoke(c.checker, "loancc", "changeLoanStatus", "1loan", "overdue", "late"), errcode.InvalidState, "loancc")
+	failsWith(t, n.Invoke(c.maker, "loancc", "changeLoanStatus", "1loan", "cancelled", "duplicate"), errcode.Unauthorized, "loancc")
+
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "500", "1bank", "2bus", "pragadeesh")
+	if status := loanStatus(t, n, c); status != "part_disbursed" {
+		t.Fatalf("loan status after part disbursement: %s", status)
+	}
+	post(t, n, c, "2txn", "disbursement", "24/04/2018", "1loan", "1ins", "400", "1bank", "2bus", "pragadeesh")
+	if allowed := allowedTransitions(t, n, c, "1loan"); len(allowed) != 3 || allowed[2] != "written_off" {
+		t.Errorf("allowed from disbursed before the due date: %v", allowed)
+	}
+
+	// past the due date the loan can be marked overdue, with a reason
+	n.Time = time.Date(2018, time.November, 1, 10, 0, 0, 0, time.UTC)
+	failsWith(t, n.Invoke(c.checker, "loancc", "changeLoanStatus", "1loan", "overdue", ""), errcode.InvalidArgument, "loancc")
+	mustInvoke(t, n, c.checker, "loancc", "changeLoanStatus", "1loan", "overdue", "not repaid by the due date")
+
+	// an overdue loan is still repaid, a part at a time
+	post(t, n, c, "3txn", "repayment", "01/11/2018", "1loan", "1ins", "400", "1bus", "1bank", "pragadeesh")
+	if status := loanStatus(t, n, c); status != "part_collected" {
+		t.Fatalf("loan status after part repayment: %s", status)
+	}
+	// and becomes overdue again while a part is unpaid
+	mustInvoke(t, n, c.checker, "loancc", "changeLoanStatus", "1loan", "overdue", "still not repaid")
+	post(t, n, c, "4txn", "repayment", "02/11/2018", "1loan", "1ins", "500", "1bus", "1bank", "pragadeesh")
+	if status := loanStatus(t, n, c); status != "collected" {
+		t.Fatalf("loan status after repayment: %s", status)
+	}
+	mustInvoke(t, n, c.checker, "loancc", "changeLoanStatus", "1loan", "closed", "repaid")
+	failsWith(t, n.Invoke(c.checker, "loancc", "changeLoanStatus", "1loan", "written_off", "too late"), errcode.InvalidState, "loancc")
+
+	changes := []struct{ From, To, Reason, TxID string }{}
+	json.Unmarshal(mustInvoke(t, n, c.ops, "loancc", "getLoanTransitions", "1loan"), &changes)
+	want := []struct{ to, reason string }{
+		{"sanctioned", "sanctioned by pragadeesh"},
+		{"part_disbursed", "disbursement"},
+		{"disbursed", "disbursement"},
+		{"overdue", "not repaid by the due date"},
+		{"part_collected", "repayment"},
+		{"overdue", "still not repaid"},
+		{"collected", "repayment"},
+		{"closed", "repaid"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("transitions: %+v", changes)
+	}
+	for i, w := range want {
+		if changes[i].To != w.to || changes[i].Reason != w.reason || changes[i].TxID == "" || (i > 0 && changes[i].From != want[i-1].to) {
+			t.Errorf("transition %d: %+v, expected to %s for %q", i, changes[i], w.to, w.reason)
+		}
+	}
+	// the instrument moves along with the loan
+	statuses := instrumentStatuses(t, n, c, "1ins", "2bus")
+	wantStatuses := []string{"open", "sanctioned", "disbursed", "overdue", "disbursed", "overdue", "settled", "closed"}
+	if len(statuses) != len(wantStatuses) {
+		t.Fatalf("instrument statuses: %v", statuses)
+	}
+	for i, w := range wantStatuses {
+		if statuses[i] != w {
+			t.Errorf("instrument status %d: %s, expected %s", i, statuses[i], w)
+		}
+	}
+}
+
+func TestCancelledLoan(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+
+	mustInvoke(t, n, c.checker, "loancc", "changeLoanStatus", "1loan", "cancelled", "sanctioned twice")
+	if allowed := allowedTransitions(t, n, c, "1loan"); len(allowed) != 0 {
+		t.Errorf("allowed from cancelled: %v", allowed)
+	}
+	reqID = mustInvoke(t, n, c.maker, "txncc", "submitTxn", "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+	failsWith(t, n.Invoke(c.checker, "txncc", "approveTxn", string(reqID)), errcode.InvalidState, "txncc")
+
+	// the instrument can be financed again
+	reqID = mustInvoke(t, n, c.maker, "loancc", "submitLoan", "2loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+}
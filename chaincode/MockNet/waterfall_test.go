@@ -0,0 +1,107 @@
+package mocknet_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	errcode "github.com/chaincode/Errcode"
+)
+
+func TestRepaymentWaterfall(t *testing.T) {
+	n, c := newNetwork(t)
+	onboard(t, n, c)
+
+	if waterfall := string(mustInvoke(t, n, c.ops, "programcc", "getWaterfall", "1prg")); waterfall != `["penal","charges","interest","principal"]` {
+		t.Errorf("default waterfall: %s", waterfall)
+	}
+	failsWith(t, n.Invoke(c.maker, "programcc", "putWaterfall", "1prg", "principal"), errcode.Unauthorized, "programcc")
+	failsWith(t, n.Invoke(c.admin, "programcc", "putWaterfall", "1prg", "fees,principal"), errcode.InvalidArgument, "programcc")
+	failsWith(t, n.Invoke(c.admin, "programcc", "putWaterfall", "1prg", "principal,interest,principal"), errcode.InvalidArgument, "programcc")
+	failsWith(t, n.Invoke(c.admin, "programcc", "putWaterfall", "1prg", "penal,charges"), errcode.InvalidArgument, "programcc")
+	failsWith(t, n.Invoke(c.admin, "programcc", "putWaterfall", "1prg", "penal,charges,principal"), errcode.InvalidArgument, "programcc")
+	mustInvoke(t, n, c.admin, "programcc", "putWaterfall", "1prg", "interest, penal, principal, charges")
+
+	// 900 disbursed, 20 of interest accrued, 5 of penal interest and 10 of
+	// other charges
+	reqID := mustInvoke(t, n, c.maker, "loancc", "submitLoan", "1loan", "1ins", "1bus", "1prg", "900", "pragadeesh", "5", "23/10/2018", "25/09/2018:20:45:01", "0", "0", "0", "1bus", "2bus")
+	mustInvoke(t, n, c.checker, "loancc", "approveLoan", string(reqID))
+	n.Time = n.Time.Add(24 * time.Hour)
+	post(t, n, c, "1txn", "disbursement", "24/04/2018", "1loan", "1ins", "900", "1bank", "2bus", "pragadeesh")
+	post(t, n, c, "2txn", "accrual", "24/05/2018", "1loan", "1ins", "20", "1bank", "2bus", "pragadeesh")
+	post(t, n, c, "3txn", "penal_charges", "24/05/2018", "1loan", "1ins", "5", "1bank", "2bus", "pragadeesh")
+	post(t, n, c, "4txn", "charges", "24/05/2018", "1loan", "1ins", "10", "1bank", "2bus", "pragadeesh")
+
+	type amount struct{ Value string }
+	type response struct {
+		TxnID      string
+		Allocation struct {
+			Waterfall                                   []string
+			Penal, Charges, Interest, Principal, Excess amount
+		}
+	}
+
+	// interest, then penal interest, then the principal: the other charges
+	// are left
+	r := response{}
+	payload := post(t, n, c, "5txn", "repayment", "25/05/2018", "1loan", "1ins", "500", "1bus", "1bank", "pragadeesh", "req5")
+	json.Unmarshal([]byte(payload), &r)
+	a := r.Allocation
+	if r.TxnID != "5txn" || len(a.Waterfall) != 4 || a.Interest.Value != "20.00" || a.Penal.Value != "5.00" || a.Principal.Value != "475.00" || a.Charges.Value != "0.00" || a.Excess.Value != "0.00" {
+		t.Fatalf("allocation of 5txn: %s", payload)
+	}
+	for _, w := range []struct{ walletType, want string }{{"accrued", "0.00"}, {"charges", "10.00"}, {"disbursed", "425.00"}} {
+		if bal := walletBalance(t, n, c, "loancc", "1loan", w.walletType); bal != w.want {
+			t.Errorf("loan %s wallet after repayment: %s", w.walletType, bal)
+		}
+	}
+	if status := loanStatus(t, n, c); status != "part_collected" {
+		t.Errorf("loan status after repayment: %s", status)
+	}
+
+	// a retry of the request gets the same response
+	reqID = mustInvoke(t, n, c.maker, "txncc", "submitTxn", "6txn", "repayment", "25/05/2018", "1loan", "1ins", "500", "1bus", "1bank", "pragadeesh", "req5")
+	if retried := string(mustInvoke(t, n, c.checker, "txncc", "approveTxn", string(reqID))); retried != payload {
+		t.Errorf("retried repayment: %s", retried)
+	}
+
+	// a request that is still pending is not submitted twice
+	failsWith(t, n.Invoke(c.maker, "txncc", "submitTxn", "5txn", "charges", "25/05/2018", "1loan", "1ins", "10", "1bank", "2bus", "pragadeesh"), errcode.AlreadyExists, "txncc")
+	reqID = mustInvoke(t, n, c.maker, "txncc", "submitTxn", "8txn", "charges", "25/05/2018", "1loan", "1ins", "10", "1bank", "2bus", "pragadeesh", "req8")
+	for _, args := range [][]string{
+		{"8txn", "charges", "25/05/2018", "1loan", "1ins", "10", "1bank", "2bus", "pragadeesh", "req8"},
+		{"9txn", "charges", "25/05/2018", "1loan", "1ins", "10", "1bank", "2bus", "pragadeesh", "req8"},
+	} {
+		if again := mustInvoke(t, n, c.maker, "txncc", "submitTxn", args...); string(again) != string(reqID) {
+			t.Errorf("%s submitted again as %s, not %s", args[0], again, reqID)
+		}
+	}
+	failsWith(t, n.Invoke(c.maker, "txncc", "submitTxn", "8txn", "charges", "25/05/2018", "1loan", "1ins", "20", "1bank", "2bus", "pragadeesh"), errcode.AlreadyExists, "txncc")
+	mustInvoke(t, n, c.checker, "txncc", "rejectTxn", string(reqID), "not due")
+	if again := mustInvoke(t, n, c.maker, "txncc", "submitTxn", "8txn", "charges", "25/05/2018", "1loan", "1ins", "10", "1bank", "2bus", "pragadeesh", "req8"); string(again) == string(reqID) {
+		t.Errorf("8txn resubmitted after the rejection as the rejected request")
+	}
+
+	// the reversal puts the penal interest back, which the default order
+	// pays first
+	mustInvoke(t, n, c.checker, "txncc", "reverseTxn", "5txn", "wrong amount", "checker")
+	mustInvoke(t, n, c.admin, "programcc", "putWaterfall", "1prg", "penal,charges,interest,principal")
+	r = response{}
+	payload = post(t, n, c, "7txn", "repayment", "26/05/2018", "1loan", "1ins", "1000", "1bus", "1bank", "pragadeesh")
+	json.Unmarshal([]byte(payload), &r)
+	a = r.Allocation
+	if a.Penal.Value != "5.00" || a.Charges.Value != "10.00" || a.Principal.Value != "900.00" || a.Interest.Value != "20.00" || a.Excess.Value != "65.00" {
+		t.Fatalf("allocation of 7txn: %s", payload)
+	}
+	if status := loanStatus(t, n, c); status != "collected" {
+		t.Errorf("loan status after the second repayment: %s", status)
+	}
+	// the excess is held as unapplied credit of the buyer
+	if credit := string(mustInvoke(t, n, c.ops, "txncc", "getUnappliedCredit", "1bus")); credit != "65.00" {
+		t.Errorf("unapplied credit after the second repayment: %s", credit)
+	}
+	mustInvoke(t, n, c.checker, "txncc", "reverseTxn", "7txn", "wrong amount", "checker")
+	if credit := string(mustInvoke(t, n, c.ops, "txncc", "getUnappliedCredit", "1bus")); credit != "0.00" {
+		t.Errorf("unapplied credit after reversing the second repayment: %s", credit)
+	}
+}
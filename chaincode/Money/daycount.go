@@ -0,0 +1,50 @@
+package money
+
+import (
+	"errors"
+	"time"
+)
+
+// DayCount is a day-count convention: how the days of an interest period and
+// of the year are counted for Interest
+type DayCount string
+
+// Day-count conventions
+const (
+	// Act365 counts the actual days over a 365 day year
+	Act365 DayCount = "ACT/365"
+	// Act360 counts the actual days over a 360 day year
+	Act360 DayCount = "ACT/360"
+	// Thirty360 counts every month as 30 days over a 360 day year (US rule)
+	Thirty360 DayCount = "30/360"
+)
+
+// ParseDayCount reads a convention such as "ACT/365"
+func ParseDayCount(s string) (DayCount, error) {
+	dc := DayCount(s)
+	if dc != Act365 && dc != Act360 && dc != Thirty360 {
+		return "", errors.New("money: unknown day-count convention " + s + ", expected ACT/365, ACT/360 or 30/360")
+	}
+	return dc, nil
+}
+
+// Days returns the days of interest from start to end, dates at midnight UTC,
+// and the days in the year
+func (dc DayCount) Days(start time.Time, end time.Time) (int64, int64) {
+	if dc != Thirty360 {
+		days := int64(end.Sub(start).Hours() / 24)
+		if dc == Act360 {
+			return days, 360
+		}
+		return days, 365
+	}
+	d1, d2 := start.Day(), end.Day()
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+	days := 360*(end.Year()-start.Year()) + 30*(int(end.Month())-int(start.Month())) + d2 - d1
+	return int64(days), 360
+}
@@ -0,0 +1,273 @@
+// Package money holds amounts as integer minor units of a currency, so that
+// balances never go through float64 on their way in or out of the ledger.
+//
+// Amounts are parsed from and printed as plain decimal strings ("1250.75"),
+// which is what every chaincode passes around in its args. Percentages and
+// rates of interest are held as Rate, a fixed point number with six decimals,
+// and every calculation that can produce a fraction of a minor unit takes an
+// explicit Rounding.
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used for amounts that do not say which currency they are in
+const DefaultCurrency = "INR"
+
+// exponents is the number of minor unit digits for each supported currency
+var exponents = map[string]int{
+	"INR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"SGD": 2,
+	"AED": 2,
+	"JPY": 0,
+}
+
+// Exponent returns the number of decimal places used by the currency
+func Exponent(currency string) (int, error) {
+	exp, ok := exponents[currency]
+	if !ok {
+		return 0, errors.New("money: unsupported currency " + currency)
+	}
+	return exp, nil
+}
+
+// Amount is a sum of money in the minor units (paise, cents) of its currency
+type Amount struct {
+	Units    int64
+	Currency string
+}
+
+// New returns an amount of units minor units
+func New(units int64, currency string) Amount {
+	return Amount{units, currency}
+}
+
+// Zero returns a zero amount in the currency
+func Zero(currency string) Amount {
+	return Amount{0, currency}
+}
+
+// Parse reads a decimal string such as "1250.75" in the given currency. It
+// refuses more decimal places than the currency has rather than round them.
+func Parse(s string, currency string) (Amount, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	exp, err := Exponent(currency)
+	if err != nil {
+		return Amount{}, err
+	}
+	units, err := parseDecimal(strings.TrimSpace(s), exp)
+	if err != nil {
+		return Amount{}, errors.New("money: cannot parse amount " + strconv.Quote(s) + ": " + err.Error())
+	}
+	return Amount{units, currency}, nil
+}
+
+// String prints the amount as a decimal string without the currency code
+func (a Amount) String() string {
+	exp, err := Exponent(a.currency())
+	if err != nil {
+		return strconv.FormatInt(a.Units, 10)
+	}
+	return formatDecimal(a.Units, exp)
+}
+
+func (a Amount) currency() string {
+	if a.Currency == "" {
+		return DefaultCurrency
+	}
+	return a.Currency
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount
+func (a Amount) Sign() int {
+	if a.Units < 0 {
+		return -1
+	} else if a.Units > 0 {
+		return 1
+	}
+	return 0
+}
+
+// IsZero reports whether the amount is zero
+func (a Amount) IsZero() bool {
+	return a.Units == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (a Amount) IsNegative() bool {
+	return a.Units < 0
+}
+
+// Neg returns the amount with its sign flipped
+func (a Amount) Neg() Amount {
+	return Amount{-a.Units, a.Currency}
+}
+
+// SameCurrency reports whether both amounts are in the same currency
+func (a Amount) SameCurrency(b Amount) bool {
+	return a.currency() == b.currency()
+}
+
+// Add returns a+b. Both amounts have to be in the same currency.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if !a.SameCurrency(b) {
+		return Amount{}, currencyMismatch(a, b)
+	}
+	sum := a.Units + b.Units
+	if (sum > a.Units) != (b.Units > 0) {
+		return Amount{}, errors.New("money: overflow adding " + a.String() + " and " + b.String())
+	}
+	return Amount{sum, a.currency()}, nil
+}
+
+// Sub returns a-b. Both amounts have to be in the same currency.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	return a.Add(b.Neg())
+}
+
+// Cmp compares two amounts in the same currency and returns -1, 0 or +1
+func (a Amount) Cmp(b Amount) (int, error) {
+	if !a.SameCurrency(b) {
+		return 0, currencyMismatch(a, b)
+	}
+	if a.Units < b.Units {
+		return -1, nil
+	} else if a.Units > b.Units {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Min returns the smaller of two amounts in the same currency
+func Min(a Amount, b Amount) (Amount, error) {
+	c, err := a.Cmp(b)
+	if err != nil {
+		return Amount{}, err
+	}
+	if c > 0 {
+		return b, nil
+	}
+	return a, nil
+}
+
+// Percent returns r percent of the amount, e.g. a 2.5 percent discount
+func (a Amount) Percent(r Rate, mode Rounding) Amount {
+	num := new(big.Int).Mul(big.NewInt(a.Units), big.NewInt(r.micro))
+	den := new(big.Int).Mul(big.NewInt(100), big.NewInt(rateScale))
+	return Amount{divRound(num, den, mode), a.currency()}
+}
+
+// Interest returns simple interest on the amount at r percent a year for
+// days days, with basis days in the year (365 or 360)
+func (a Amount) Interest(r Rate, days int64, basis int64, mode Rounding) Amount {
+	num := new(big.Int).Mul(big.NewInt(a.Units), big.NewInt(r.micro))
+	num.Mul(num, big.NewInt(days))
+	den := new(big.Int).Mul(big.NewInt(100*rateScale), big.NewInt(basis))
+	return Amount{divRound(num, den, mode), a.currency()}
+}
+
+// Convert returns the amount in another currency at rate units of the
+// target currency for one unit of the amount's currency
+func (a Amount) Convert(currency string, r Rate, mode Rounding) (Amount, error) {
+	fromExp, err := Exponent(a.currency())
+	if err != nil {
+		return Amount{}, err
+	}
+	toExp, err := Exponent(currency)
+	if err != nil {
+		return Amount{}, err
+	}
+	num := new(big.Int).Mul(big.NewInt(a.Units), big.NewInt(r.micro))
+	num.Mul(num, pow10(toExp))
+	den := new(big.Int).Mul(big.NewInt(rateScale), pow10(fromExp))
+	return Amount{divRound(num, den, mode), currency}, nil
+}
+
+// amountJSON is how an Amount is written to the ledger
+type amountJSON struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON writes the amount as {"value":"1250.75","currency":"INR"}
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(amountJSON{a.String(), a.currency()})
+}
+
+// UnmarshalJSON reads the object written by MarshalJSON, a decimal string, or
+// a plain JSON number as stored by the chaincodes before this package existed.
+// Strings and numbers are taken to be in DefaultCurrency.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	switch data[0] {
+	case '{':
+		aj := amountJSON{}
+		err := json.Unmarshal(data, &aj)
+		if err != nil {
+			return err
+		}
+		parsed, err := Parse(aj.Value, aj.Currency)
+		if err != nil {
+			return err
+		}
+		*a = parsed
+		return nil
+	case '"':
+		s := ""
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return err
+		}
+		parsed, err := Parse(s, DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		*a = parsed
+		return nil
+	}
+	parsed, err := parseLegacyNumber(string(data), DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// parseLegacyNumber reads balances that were stored as float64, which may
+// carry float noise beyond the currency's decimals or use an exponent
+func parseLegacyNumber(s string, currency string) (Amount, error) {
+	amt, err := Parse(s, currency)
+	if err == nil {
+		return amt, nil
+	}
+	exp, _ := Exponent(currency)
+	f, _, ferr := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+	if ferr != nil {
+		return Amount{}, err
+	}
+	f.Mul(f, new(big.Float).SetInt(pow10(exp)))
+	r, _ := new(big.Float).Add(f, big.NewFloat(0.5*float64(f.Sign()))).Int(nil)
+	if !r.IsInt64() {
+		return Amount{}, errors.New("money: amount out of range " + s)
+	}
+	return Amount{r.Int64(), currency}, nil
+}
+
+func currencyMismatch(a Amount, b Amount) error {
+	return fmt.Errorf("money: currency mismatch %s %s and %s %s", a.String(), a.currency(), b.String(), b.currency())
+}
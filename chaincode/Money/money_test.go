@@ -0,0 +1,246 @@
+package money_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	money "github.com/chaincode/Money"
+)
+
+func TestParse(t *testing.T) {
+	for _, p := range []struct {
+		s, currency string
+		units       int64
+		ok          bool
+	}{
+		{"1250.75", "INR", 125075, true},
+		{" 1250.750 ", "INR", 125075, true},
+		{"-3.5", "INR", -350, true},
+		{"+.5", "USD", 50, true},
+		{"7", "", 700, true},
+		{"15", "JPY", 15, true},
+		{"92233720368547758.07", "INR", 9223372036854775807, true},
+		// too many decimals, refused rather than rounded
+		{"1250.755", "INR", 0, false},
+		{"1.5", "JPY", 0, false},
+		// overflow
+		{"92233720368547758.08", "INR", 0, false},
+		{"9223372036854775808", "JPY", 0, false},
+		{"", "INR", 0, false},
+		{".", "INR", 0, false},
+		{"12,50", "INR", 0, false},
+		{"1e3", "INR", 0, false},
+		{"1", "XYZ", 0, false},
+	} {
+		amt, err := money.Parse(p.s, p.currency)
+		if !p.ok {
+			if err == nil {
+				t.Errorf("Parse(%q, %q) = %s, want an error", p.s, p.currency, amt)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q, %q): %s", p.s, p.currency, err)
+		} else if amt.Units != p.units {
+			t.Errorf("Parse(%q, %q) = %d units, want %d", p.s, p.currency, amt.Units, p.units)
+		}
+	}
+	if amt, _ := money.Parse("7", ""); amt.Currency != money.DefaultCurrency {
+		t.Errorf("Parse without a currency is in %s", amt.Currency)
+	}
+}
+
+func TestUnmarshalLegacy(t *testing.T) {
+	for _, u := range []struct {
+		data     string
+		currency string
+		units    int64
+		ok       bool
+	}{
+		{`{"value":"1250.75","currency":"USD"}`, "USD", 125075, true},
+		{`"1250.75"`, "INR", 125075, true},
+		// float64 balances from before the money package
+		{`1250.75`, "INR", 125075, true},
+		{`1250.7500000000002`, "INR", 125075, true},
+		{`0.30000000000000004`, "INR", 30, true},
+		{`-19.999999999999996`, "INR", -2000, true},
+		{`1e3`, "INR", 100000, true},
+		{`1.5e-3`, "INR", 0, true},
+		{`{"value":"1.5","currency":"JPY"}`, "", 0, false},
+		{`"1250.755"`, "", 0, false},
+		{`1e300`, "", 0, false},
+		{`"abc"`, "", 0, false},
+	} {
+		amt := money.Amount{}
+		err := json.Unmarshal([]byte(u.data), &amt)
+		if !u.ok {
+			if err == nil {
+				t.Errorf("unmarshal %s = %s, want an error", u.data, amt)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unmarshal %s: %s", u.data, err)
+		} else if amt.Units != u.units || amt.Currency != u.currency {
+			t.Errorf("unmarshal %s = %d %s, want %d %s", u.data, amt.Units, amt.Currency, u.units, u.currency)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	for _, m := range []struct {
+		amt  money.Amount
+		want string
+	}{
+		{money.New(125075, "INR"), `{"value":"1250.75","currency":"INR"}`},
+		{money.New(-5, "USD"), `{"value":"-0.05","currency":"USD"}`},
+		{money.New(1500, "JPY"), `{"value":"1500","currency":"JPY"}`},
+		{money.Amount{}, `{"value":"0.00","currency":"INR"}`},
+	} {
+		data, err := json.Marshal(m.amt)
+		if err != nil {
+			t.Errorf("marshal %d %s: %s", m.amt.Units, m.amt.Currency, err)
+		} else if string(data) != m.want {
+			t.Errorf("marshal %d %s = %s, want %s", m.amt.Units, m.amt.Currency, data, m.want)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	inr := money.New(100, "INR")
+	if _, err := inr.Add(money.New(100, "USD")); err == nil {
+		t.Errorf("INR and USD added")
+	}
+	if _, err := inr.Cmp(money.New(100, "USD")); err == nil {
+		t.Errorf("INR and USD compared")
+	}
+	if _, err := money.New(9223372036854775807, "INR").Add(money.New(1, "INR")); err == nil {
+		t.Errorf("overflow not caught")
+	}
+	if _, err := money.New(-9223372036854775807, "INR").Sub(money.New(2, "INR")); err == nil {
+		t.Errorf("underflow not caught")
+	}
+	if sum, err := inr.Add(money.New(-250, "")); err != nil || sum.Units != -150 || sum.Currency != "INR" {
+		t.Errorf("100 paise and -250 paise: %d %s %v", sum.Units, sum.Currency, err)
+	}
+	if min, _ := money.Min(inr, money.New(99, "INR")); min.Units != 99 {
+		t.Errorf("Min = %d", min.Units)
+	}
+}
+
+func TestRounding(t *testing.T) {
+	ten, _ := money.ParseRate("10")
+	for _, r := range []struct {
+		units                  int64
+		halfUp, halfEven, down int64
+	}{
+		{25, 3, 2, 2},     // 2.5
+		{35, 4, 4, 3},     // 3.5
+		{26, 3, 3, 2},     // 2.6
+		{24, 2, 2, 2},     // 2.4
+		{-25, -3, -2, -2}, // -2.5
+		{-26, -3, -3, -2}, // -2.6
+		{20, 2, 2, 2},
+	} {
+		amt := money.New(r.units, "INR")
+		for _, m := range []struct {
+			mode money.Rounding
+			want int64
+		}{{money.HalfUp, r.halfUp}, {money.HalfEven, r.halfEven}, {money.Down, r.down}} {
+			if got := amt.Percent(ten, m.mode).Units; got != m.want {
+				t.Errorf("10%% of %d units with rounding %d = %d, want %d", r.units, m.mode, got, m.want)
+			}
+		}
+	}
+}
+
+func TestInterest(t *testing.T) {
+	for _, i := range []struct {
+		principal, rate string
+		days, basis     int64
+		mode            money.Rounding
+		want            string
+	}{
+		{"100000", "12", 30, 365, money.HalfUp, "986.30"},
+		{"100000", "12", 30, 360, money.HalfUp, "1000.00"},
+		{"100000", "12", 365, 365, money.HalfUp, "12000.00"},
+		// 0.205479...
+		{"1000", "7.5", 1, 365, money.HalfUp, "0.21"},
+		{"1000", "7.5", 1, 365, money.Down, "0.20"},
+		// 0.125 exactly
+		{"1000", "4.5", 1, 360, money.HalfUp, "0.13"},
+		{"1000", "4.5", 1, 360, money.HalfEven, "0.12"},
+		{"1000", "0", 30, 365, money.HalfUp, "0.00"},
+		{"1000", "12", 0, 365, money.HalfUp, "0.00"},
+		// no overflow of the intermediate product
+		{"50000000000000000", "18", 365, 365, money.HalfUp, "9000000000000000.00"},
+	} {
+		principal, _ := money.Parse(i.principal, "INR")
+		rate, _ := money.ParseRate(i.rate)
+		if got := principal.Interest(rate, i.days, i.basis, i.mode).String(); got != i.want {
+			t.Errorf("interest on %s at %s%% for %d/%d days = %s, want %s", i.principal, i.rate, i.days, i.basis, got, i.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	for _, c := range []struct {
+		amt, from, to, rate string
+		mode                money.Rounding
+		want                string
+		ok                  bool
+	}{
+		{"100", "USD", "INR", "83.25", money.HalfUp, "8325.00", true},
+		{"1000", "JPY", "USD", "0.0067", money.HalfUp, "6.70", true},
+		{"10", "USD", "JPY", "149.555", money.HalfUp, "1496", true},
+		{"10", "USD", "JPY", "149.555", money.Down, "1495", true},
+		// half a cent
+		{"1", "JPY", "USD", "0.005", money.HalfUp, "0.01", true},
+		{"1", "JPY", "USD", "0.005", money.HalfEven, "0.00", true},
+		{"-1", "JPY", "USD", "0.005", money.HalfUp, "-0.01", true},
+		{"12.34", "INR", "INR", "1", money.HalfUp, "12.34", true},
+		{"1", "INR", "XYZ", "1", money.HalfUp, "", false},
+	} {
+		amt, _ := money.Parse(c.amt, c.from)
+		rate, _ := money.ParseRate(c.rate)
+		got, err := amt.Convert(c.to, rate, c.mode)
+		if !c.ok {
+			if err == nil {
+				t.Errorf("convert %s %s to %s = %s, want an error", c.amt, c.from, c.to, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("convert %s %s to %s: %s", c.amt, c.from, c.to, err)
+		} else if got.String() != c.want || got.Currency != c.to {
+			t.Errorf("convert %s %s to %s at %s = %s %s, want %s", c.amt, c.from, c.to, c.rate, got, got.Currency, c.want)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for _, r := range []struct {
+		s, want string
+		ok      bool
+	}{
+		{"12.4", "12.4", true},
+		{"8.090", "8.09", true},
+		{"0.000001", "0.000001", true},
+		{"5", "5", true},
+		{"0.0000001", "", false},
+		{"5%", "", false},
+	} {
+		rate, err := money.ParseRate(r.s)
+		if !r.ok {
+			if err == nil {
+				t.Errorf("ParseRate(%q) = %s, want an error", r.s, rate)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRate(%q): %s", r.s, err)
+		} else if rate.String() != r.want {
+			t.Errorf("ParseRate(%q) = %s, want %s", r.s, rate, r.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// rateDecimals is the precision a Rate is held at
+const rateDecimals = 6
+
+const rateScale = 1000000
+
+// Rate is a fixed point number with six decimals, used for rates of
+// interest, discount percentages and FX rates
+type Rate struct {
+	micro int64
+}
+
+// ParseRate reads a decimal string such as "12.4" or "8.09"
+func ParseRate(s string) (Rate, error) {
+	micro, err := parseDecimal(strings.TrimSpace(s), rateDecimals)
+	if err != nil {
+		return Rate{}, errors.New("money: cannot parse rate " + strconv.Quote(s) + ": " + err.Error())
+	}
+	return Rate{micro}, nil
+}
+
+// String prints the rate without trailing zeros
+func (r Rate) String() string {
+	s := formatDecimal(r.micro, rateDecimals)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// IsZero reports whether the rate is zero
+func (r Rate) IsZero() bool {
+	return r.micro == 0
+}
+
+// IsNegative reports whether the rate is below zero
+func (r Rate) IsNegative() bool {
+	return r.micro < 0
+}
+
+// Cmp compares two rates and returns -1, 0 or +1
+func (r Rate) Cmp(s Rate) int {
+	if r.micro < s.micro {
+		return -1
+	} else if r.micro > s.micro {
+		return 1
+	}
+	return 0
+}
+
+// MarshalJSON writes the rate as a decimal string
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON reads a decimal string, or a JSON number as the ROI was
+// stored before this package existed
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s := string(data)
+	if data[0] == '"' {
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseRate(s)
+	if err != nil {
+		// float32 ROIs were written with float noise, e.g. 12.399999618530273
+		f, _, ferr := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+		if ferr != nil {
+			return err
+		}
+		f.Mul(f, big.NewFloat(rateScale))
+		i, _ := new(big.Float).Add(f, big.NewFloat(0.5*float64(f.Sign()))).Int(nil)
+		parsed = Rate{i.Int64()}
+	}
+	*r = parsed
+	return nil
+}
+
+// Rounding says what to do with a fraction of a minor unit
+type Rounding int
+
+const (
+	// HalfUp rounds halves away from zero; the usual rule for charges
+	HalfUp Rounding = iota
+	// HalfEven rounds halves to the even neighbour (banker's rounding)
+	HalfEven
+	// Down truncates towards zero; used where the bank must not overcharge
+	Down
+)
+
+// divRound divides num by a positive den and rounds the quotient with mode
+func divRound(num *big.Int, den *big.Int, mode Rounding) int64 {
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if m.Sign() != 0 && mode != Down {
+		twice := new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2))
+		c := twice.Cmp(den)
+		if c > 0 || (c == 0 && (mode == HalfUp || q.Bit(0) == 1)) {
+			if num.Sign() < 0 {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+	return q.Int64()
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// parseDecimal reads a plain decimal string into an integer scaled by 10^exp
+func parseDecimal(s string, exp int) (int64, error) {
+	if s == "" {
+		return 0, errors.New("empty value")
+	}
+	neg := false
+	if s[0] == '-' || s[0] == '+' {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" && frac == "" {
+		return 0, errors.New("no digits")
+	}
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > exp {
+		return 0, errors.New("more than " + strconv.Itoa(exp) + " decimal places")
+	}
+	digits := whole + frac + strings.Repeat("0", exp-len(frac))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, errors.New("not a decimal number")
+		}
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, errors.New("out of range")
+	}
+	if neg {
+		n = -n
+	}
+	return n, nil
+}
+
+// formatDecimal prints an integer scaled by 10^exp as a decimal string
+func formatDecimal(n int64, exp int) string {
+	sign := ""
+	u := new(big.Int).SetInt64(n)
+	if n < 0 {
+		sign = "-"
+		u.Neg(u)
+	}
+	s := u.String()
+	if exp == 0 {
+		return sign + s
+	}
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+
+	pprcc "github.com/chaincode/PPR/pprcc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() {
+	err := shim.Start(new(pprcc.Chaincode))
+	if err != nil {
+		fmt.Printf("pprcc: "+"Error starting PPR chaincode: %s\n", err)
+	}
+}
@@ -0,0 +1,356 @@
+package pprcc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	auth "github.com/chaincode/Auth"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	history "github.com/chaincode/History"
+	schema "github.com/chaincode/Schema"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Chaincode is the pprcc chaincode
+type Chaincode struct {
+}
+
+type pprInfo struct {
+	ProgramID                         string  `json:"ProgramID"`                         //[1]
+	BusinessID                        string  `json:"BusinessID"`                        //[2]
+	Relationship                      string  `json:"Relationship"`                      //[3]
+	ProgramBusinessLimit              int64   `json:"ProgramBusinessLimit"`              //[4]
+	ProgramBusinessROI                float64 `json:"ProgramBusinessROI"`                //[5]
+	ProgramBusinessDiscountPeriod     int     `json:"ProgramBusinessDiscountPeriod"`     //[6]
+	ProgramBusinessDiscountPercentage string  `json:"ProgramBusinessDiscountPercentage"` //[7]//use float64 for parsing
+	StaleDays                         int     `json:"StaleDays"`                         //[8]
+	RepaymentAcNo                     string  `json:"RepaymentAcNo"`                     //[9]
+	RepaymentWalletID                 string  `json:"RepaymentWalletID"`                 //will be taken from business Id
+}
+
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	indexName := "ProgramID~BusinessID~DiscountPercentage"
+	ppr := pprInfo{}
+	prgrmBusPercentageKey, err := stub.CreateCompositeKey(indexName, []string{ppr.ProgramID, ppr.BusinessID, ppr.ProgramBusinessDiscountPercentage})
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, "Unableto create composite key ProgramID~BusinessID~DiscountPercentage :"+err.Error())
+
+	}
+	value := []byte{0x00}
+	stub.PutState(prgrmBusPercentageKey, value)
+	// the channel and the names of the other chaincodes, if instantiated with them
+	err = common.InitConfig(stub)
+	if err != nil {
+		return errcode.Error("pprcc", errcode.InvalidArgument, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// accessPolicy says who may call each function of pprcc
+var accessPolicy = auth.Policy{
+	"createPPR":             auth.Roles(auth.Maker, auth.Ops),
+	"seePPR":                auth.Roles(auth.Anyone),
+	"pprIDexists":           auth.Roles(auth.Anyone),
+	"getDiscountPercentage": auth.Roles(auth.Anyone),
+	"updatePPR":             auth.Roles(auth.BankAdmin),
+	"getPPRHistory":         auth.Roles(auth.Auditor, auth.Ops, auth.BankAdmin),
+	"getConfig":             auth.Roles(auth.Anyone),
+}
+
+// argSchemas names the arguments of the functions of pprcc that take a JSON
+// object
+var argSchemas = schema.Schemas{
+	"createPPR": {
+		{Name: "pprID", Kind: schema.Text},
+		{Name: "programID", Kind: schema.Text},
+		{Name: "businessID", Kind: schema.Text},
+		{Name: "relationship", Kind: schema.Text},
+		{Name: "limit", Kind: schema.Int},
+		{Name: "roi", Kind: schema.Number},
+		{Name: "discountPeriod", Kind: schema.Int},
+		{Name: "discountPercentage", Kind: schema.Number},
+		{Name: "staleDays", Kind: schema.Int},
+		{Name: "repaymentAcNo", Kind: schema.Text},
+	},
+	"updatePPR": {
+		{Name: "pprID", Kind: schema.Text},
+		{Name: "field", Kind: schema.Text},
+		{Name: "value", Kind: schema.Text},
+	},
+}
+
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	err := accessPolicy.Check(stub, function)
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Unauthorized, err.Error())
+	}
+	args, err = argSchemas.Parse(function, args)
+	if err != nil {
+		return errcode.Error("pprcc", errcode.InvalidArgument, err.Error())
+	}
+
+	if function == "createPPR" {
+		//Creates a new PPR Information
+		return createPPR(stub, args)
+	} else if function == "seePPR" {
+		//Retrieves the PPR Information
+		return seePPR(stub, args)
+	} else if function == "pprIDexists" {
+		//Checks the existence of PprID
+		return pprIDexists(stub, args[0])
+	} else if function == "getDiscountPercentage" {
+		//Returns DiscountPercentage
+		return discountPercentage(stub, args)
+	} else if function == "updatePPR" {
+		/*
+			Parameters for Value Calculation
+			    a. Program Business Limit
+			    b. Program Business ROI
+			    c. Program Business Discount Percentage
+				d. Program Business Discount Period
+			are updated
+		*/
+		return updatePPR(stub, args)
+	} else if function == "getPPRHistory" {
+		//Returns every version of the PPR, a page at a time
+		return getPPRHistory(stub, args)
+	} else if function == "getConfig" {
+		//Returns the channel and the chaincode names it was instantiated with
+		cfgBytes, err := common.GetConfig(stub)
+		if err != nil {
+			return errcode.Error("pprcc", errcode.Internal, err.Error())
+		}
+		return shim.Success(cfgBytes)
+	}
+	return errcode.Error("pprcc", errcode.InvalidArgument, "No function named "+function+" in PPRsssssss")
+}
+
+func createPPR(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 10 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("pprcc", errcode.InvalidArgument, "Invalid number of arguments in createPPR (required:10) given:"+xLenStr)
+	}
+
+	//Checking existence of PprID
+	response := pprIDexists(stub, args[0])
+	if response.Status != shim.OK {
+		return errcode.Error("pprcc", errcode.AlreadyExists, response.Message)
+	}
+
+	//Checking existence of businessID
+	business := common.NewBusinessClient(stub)
+	exists, err := business.Exists(args[2])
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("pprcc", errcode.NotFound, "BusinessId "+args[2]+" does not exits")
+	}
+
+	//Checking existence of ProgramID
+	exists, err = common.NewProgramClient(stub).Exists(args[1])
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("pprcc", errcode.NotFound, "ProgramId "+args[1]+" does not exits")
+	}
+
+	relationship := map[string]bool{
+		"seller": true,
+		"vendor": true,
+		"buyer":  true,
+		"dealer": true,
+	}
+
+	relationshipLower := strings.ToLower(args[3])
+
+	if !relationship[relationshipLower] {
+		return errcode.Error("pprcc", errcode.InvalidArgument, "Invalid relationship "+relationshipLower)
+	}
+
+	// ProgramBusinessLimit -> PBLimit
+	PBLimit, err := strconv.ParseInt(args[4], 10, 64)
+	if err != nil {
+		return errcode.Error("pprcc", errcode.InvalidArgument, err.Error())
+	}
+
+	//ProgramBusinessROI -> PBroi
+	PBroi, err := strconv.ParseFloat(args[5], 64)
+	if err != nil {
+		return errcode.Error("pprcc", errcode.InvalidArgument, err.Error())
+	}
+
+	//ProgramBusinessDiscountPeriod -> PBDperiod
+	PBDperiod, err := strconv.Atoi(args[6])
+	if err != nil {
+		return errcode.Error("pprcc", errcode.InvalidArgument, err.Error())
+	}
+
+	//ProgramBusinessDiscountPercentage -> PBDpercentange
+	_, err = strconv.ParseFloat(args[7], 64)
+	if err != nil {
+		return errcode.Error("pprcc", errcode.InvalidArgument, err.Error())
+	}
+
+	//StaleDays -> sDays
+	sDays, err := strconv.Atoi(args[8])
+	if err != nil {
+		return errcode.Error("pprcc", errcode.InvalidArgument, err.Error())
+	}
+
+	//Wallet ID for repayment
+	repayWalletID, err := business.WalletID(args[2], "main")
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, err.Error())
+	}
+
+	ppr := pprInfo{args[1], args[2], relationshipLower, PBLimit, PBroi, PBDperiod, args[7], sDays, args[9], repayWalletID}
+	pprBytes, err := json.Marshal(ppr)
+	err = history.Put(stub, args[0], pprBytes)
+
+	// the discount percentage is looked up by program and business when a
+	// loan is sanctioned
+	prgrmBusPercentageKey, err := stub.CreateCompositeKey("ProgramID~BusinessID~DiscountPercentage", []string{args[1], args[2], args[7]})
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, "Unableto create composite key ProgramID~BusinessID~DiscountPercentage :"+err.Error())
+	}
+	err = stub.PutState(prgrmBusPercentageKey, []byte{0x00})
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, err.Error())
+	}
+
+	return shim.Success([]byte("Successfully added PPR to the ledger"))
+}
+
+func pprIDexists(stub shim.ChaincodeStubInterface, pprID string) pb.Response {
+	ifExists, _ := stub.GetState(pprID)
+	if ifExists != nil {
+		fmt.Println(ifExists)
+		return errcode.Error("pprcc", errcode.AlreadyExists, "PprId "+pprID+" exits. Cannot create new ID")
+	}
+	return shim.Success(nil)
+}
+
+func updatePPR(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("pprcc", errcode.InvalidArgument, "Invalid number of arguments in updatePPR(PPR) (required:3) given:"+xLenStr)
+	}
+	/*
+		args[0] -> pprID
+		args[1] -> Program Business Limit, Program Business ROI,
+				   Program Business Discount Percentage, Program Business Discount Period
+		args[2] -> values
+	*/
+	pprObject := pprInfo{}
+	pprBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, "updatePPR(PPR)"+err.Error())
+	}
+
+	err = json.Unmarshal(pprBytes, &pprObject)
+	lowerStr := strings.ToLower(args[1])
+
+	if lowerStr == "program business limit" {
+		//Changing Program Business Limit
+		PBLimit, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return errcode.Error("pprcc", errcode.InvalidArgument, "updatePPR(PPR) Program Business Limit"+err.Error())
+		}
+		pprObject.ProgramBusinessLimit = PBLimit
+	} else if lowerStr == "program business roi" {
+		//Changing Program Business ROI
+		PBroi, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			return errcode.Error("pprcc", errcode.InvalidArgument, "updatePPR(PPR) Program Business ROI"+err.Error())
+		}
+		pprObject.ProgramBusinessROI = PBroi
+	} else if lowerStr == "program business discount percentage" {
+		//Changing Program Business Discount Percentage
+		_, err = strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			return errcode.Error("pprcc", errcode.InvalidArgument, "updatePPR(PPR) Program Business Discount Percentage"+err.Error())
+		}
+		pprObject.ProgramBusinessDiscountPercentage = args[2]
+	} else if lowerStr == "program business discount period" {
+		//Chainging Program Business Discount Period
+		PBDperiod, err := strconv.Atoi(args[2])
+		if err != nil {
+			return errcode.Error("pprcc", errcode.InvalidArgument, "updatePPR(PPR) Program Business Discount Period"+err.Error())
+		}
+		pprObject.ProgramBusinessDiscountPeriod = PBDperiod
+	}
+	return shim.Success(nil)
+
+}
+
+func discountPercentage(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> ProgramID
+	 *args[1] -> BusinessID
+	 */
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("pprcc", errcode.InvalidArgument, "Invalid number of arguments in getDiscountPercentage (required:2) given: "+xLenStr)
+	}
+
+	prgrmBusPercentageIte, err := stub.GetStateByPartialCompositeKey("ProgramID~BusinessID~DiscountPercentage", []string{args[0], args[1]})
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, err.Error())
+	}
+	defer prgrmBusPercentageIte.Close()
+	if !prgrmBusPercentageIte.HasNext() {
+		return errcode.Error("pprcc", errcode.NotFound, "No PPR for business "+args[1]+" in program "+args[0])
+	}
+	prgrmBusPercentageData, err := prgrmBusPercentageIte.Next()
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, err.Error())
+	}
+	_, data, err := stub.SplitCompositeKey(prgrmBusPercentageData.Key)
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, "Error spliting composite key ProgramID~BusinessID~DiscountPercentage (ppr):"+err.Error())
+	}
+	return shim.Success([]byte(data[2]))
+}
+
+func seePPR(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("pprcc", errcode.InvalidArgument, "Invalid number of arguments in seePPR (required:1) given:"+xLenStr)
+	}
+
+	pprObject := pprInfo{}
+	pprArray, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, err.Error())
+	}
+
+	err = json.Unmarshal(pprArray, &pprObject)
+	pprString := fmt.Sprintf("%+v", pprObject)
+
+	return shim.Success([]byte(pprString))
+
+}
+
+func getPPRHistory(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> PprID
+	 *args[1] -> page size, 20 when not given (at most 100)
+	 *args[2] -> bookmark of the previous page, for the next one
+	 */
+	if len(args) < 1 || len(args) > 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("pprcc", errcode.InvalidArgument, "Invalid number of arguments in getPPRHistory (required:1 to 3) given: "+xLenStr)
+	}
+	page, err := history.Get(stub, args[0], args[1:])
+	if err != nil {
+		return errcode.Error("pprcc", errcode.Internal, err.Error())
+	}
+	pageBytes, _ := json.Marshal(page)
+	return shim.Success(pageBytes)
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+
+	programcc "github.com/chaincode/Program/programcc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() {
+	err := shim.Start(new(programcc.Chaincode))
+	if err != nil {
+		fmt.Printf("programcc: "+"Error starting Program chaincode: %s\n", err)
+	}
+}
@@ -0,0 +1,476 @@
+package programcc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	auth "github.com/chaincode/Auth"
+	clock "github.com/chaincode/Clock"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	history "github.com/chaincode/History"
+	schema "github.com/chaincode/Schema"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Chaincode is the programcc chaincode
+type Chaincode struct {
+}
+
+type programInfo struct {
+	ProgramName        string    `json:"ProgramName"`        //[1]
+	ProgramAnchor      string    `json:"ProgramAnchor"`      //[2]BusinessID
+	ProgramType        string    `json:"ProgramType"`        //[3]
+	ProgramStartDate   time.Time `json:"ProgramStartDate"`   //auto generated as created, from the txn timestamp
+	ProgramEndDate     time.Time `json:"ProgramEndDate"`     //[4]
+	ProgramLimit       int64     `json:"ProgramLimit"`       //[5]
+	ProgramROI         int64     `json:"ProgramROI"`         //[6]
+	ProgramExposure    string    `json:"ProgramExposure"`    //[7]
+	DiscountPercentage int64     `json:"DiscountPercentage"` //[8]
+	DiscountPeriod     int64     `json:"DiscountPeriod"`     //[9]
+	SanctionAuthority  string    `json:"SanctionAuthority"`  //[10]
+	SanctionDate       time.Time `json:"SanctionDate"`       //auto generated as created, from the txn timestamp
+	RepaymentAcNum     string    `json:"RepaymentAcNo"`      //[11]
+	RepaymentWalletID  string    `json:"RepaymentWallet"`    //taken from program anchors business id
+	Calendar           string    `json:"Calendar"`           //[12] calendarcc ID the due dates are adjusted on, none if empty
+	DueDateRule        string    `json:"DueDateRule"`        //[13] business-day rule of the due dates, following by default
+	Waterfall          []string  `json:"Waterfall"`          //order repayments are appropriated in, defaultWaterfall if empty
+}
+
+// repaymentComponents are what a repayment can pay on a loan
+var repaymentComponents = map[string]bool{
+	"penal":     true, // penal interest charged on the loan once overdue
+	"charges":   true, // the other charges on the loan
+	"interest":  true, // interest accrued on the loan
+	"principal": true, // what is disbursed
+}
+
+// defaultWaterfall is the order repayments are appropriated in unless the
+// program has its own. A waterfall pays every one of repaymentComponents, or
+// a loan could be collected with something still owed on it.
+var defaultWaterfall = []string{"penal", "charges", "interest", "principal"}
+
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	// the channel and the names of the other chaincodes, if instantiated with them
+	err := common.InitConfig(stub)
+	if err != nil {
+		return errcode.Error("programcc", errcode.InvalidArgument, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// accessPolicy says who may call each function of programcc
+var accessPolicy = auth.Policy{
+	"writeProgram":      auth.Roles(auth.Maker, auth.Ops),
+	"getProgram":        auth.Roles(auth.Anyone),
+	"programIDexists":   auth.Roles(auth.Anyone),
+	"updateProgramInfo": auth.Roles(auth.BankAdmin),
+	"getProgramHistory": auth.Roles(auth.Auditor, auth.Ops, auth.BankAdmin),
+	"getDueDateRule":    auth.Roles(auth.Anyone),
+	"putWaterfall":      auth.Roles(auth.BankAdmin),
+	"getWaterfall":      auth.Roles(auth.Anyone),
+	"getConfig":         auth.Roles(auth.Anyone),
+}
+
+// argSchemas names the arguments of the functions of programcc that take a JSON
+// object
+var argSchemas = schema.Schemas{
+	"writeProgram": {
+		{Name: "programID", Kind: schema.Text},
+		{Name: "name", Kind: schema.Text},
+		{Name: "anchorID", Kind: schema.Text},
+		{Name: "type", Kind: schema.Text},
+		{Name: "endDate", Kind: schema.Date},
+		{Name: "limit", Kind: schema.Int},
+		{Name: "roi", Kind: schema.Int},
+		{Name: "exposure", Kind: schema.Text},
+		{Name: "discountPercentage", Kind: schema.Int},
+		{Name: "discountPeriod", Kind: schema.Int},
+		{Name: "sanctionAuthority", Kind: schema.Text},
+		{Name: "repaymentAcNo", Kind: schema.Text},
+		{Name: "calendarID", Kind: schema.Text, Optional: true},
+		{Name: "dueDateRule", Kind: schema.Text, Optional: true},
+	},
+	"updateProgramInfo": {
+		{Name: "programID", Kind: schema.Text},
+		{Name: "field", Kind: schema.Text},
+		{Name: "value", Kind: schema.Text},
+	},
+}
+
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	err := accessPolicy.Check(stub, function)
+	if err != nil {
+		return errcode.Error("programcc", errcode.Unauthorized, err.Error())
+	}
+	args, err = argSchemas.Parse(function, args)
+	if err != nil {
+		return errcode.Error("programcc", errcode.InvalidArgument, err.Error())
+	}
+
+	if function == "writeProgram" {
+		//Creates a new Program Information
+		return writeProgram(stub, args)
+	} else if function == "getProgram" {
+		//Retrieves the Program Information
+		return getProgram(stub, args)
+	} else if function == "programIDexists" {
+		//Checks the existence of ProgramID
+		return programIDexists(stub, args[0])
+	} else if function == "updateProgramInfo" {
+		/*
+			Updates Program Limit, Program ROI,
+			Discount Percentage,Discount Period and Program end date if required
+		*/
+		return updateProgramInfo(stub, args)
+	} else if function == "getProgramHistory" {
+		//Returns every version of the program, a page at a time
+		return getProgramHistory(stub, args)
+	} else if function == "getDueDateRule" {
+		//Returns the calendar and the business-day rule of the due dates of the program
+		return getDueDateRule(stub, args)
+	} else if function == "putWaterfall" {
+		//Sets the order repayments on the loans of the program are appropriated in
+		return putWaterfall(stub, args)
+	} else if function == "getWaterfall" {
+		//Returns the order repayments on the loans of the program are appropriated in
+		return getWaterfall(stub, args)
+	} else if function == "getConfig" {
+		//Returns the channel and the chaincode names it was instantiated with
+		cfgBytes, err := common.GetConfig(stub)
+		if err != nil {
+			return errcode.Error("programcc", errcode.Internal, err.Error())
+		}
+		return shim.Success(cfgBytes)
+	}
+	return errcode.Error("programcc", errcode.InvalidArgument, "No function named "+function+" in Programsssssss")
+}
+
+func writeProgram(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) < 12 || len(args) > 14 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid number of arguments in writeProgram (required:12 to 14) given:"+xLenStr)
+	}
+
+	//Checking existence of programID
+	response := programIDexists(stub, args[0])
+	if response.Status != shim.OK {
+		return errcode.Error("programcc", errcode.AlreadyExists, response.Message)
+	}
+
+	//Checking existence of businessID
+	business := common.NewBusinessClient(stub)
+	exists, err := business.Exists(args[2])
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	} else if !exists {
+		return errcode.Error("programcc", errcode.NotFound, "BusinessId "+args[2]+" does not exits")
+	}
+
+	pTypes := map[string]bool{
+		"ar":                  true,
+		"ap":                  true,
+		"df":                  true,
+		"accounts_payable":    true,
+		"accounts_receivable": true,
+		"dealer finance":      true,
+	}
+
+	//Checking whether the given argument is a valid type
+	pTypeLower := strings.ToLower(args[3])
+	if !pTypes[pTypeLower] {
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid program type"+pTypeLower)
+	}
+
+	//ProgramStartDate -> pSDate	//new way or old way?
+	pSDate, err := clock.Now(stub)
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	}
+
+	//ProgramEndDate -> pEDate
+	pEDate, err := time.Parse("02/01/2006", args[4])
+	if err != nil {
+		return errcode.Error("programcc", errcode.InvalidArgument, err.Error())
+	}
+
+	pLimit, err := strconv.ParseInt(args[5], 10, 64)
+	if err != nil {
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid Program limit "+args[6])
+	}
+
+	pROI, err := strconv.ParseInt(args[6], 10, 64)
+	if err != nil {
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid Rate of Interest in writeProgram")
+	}
+
+	pExposure := map[string]bool{
+		"buyer":  true,
+		"seller": true,
+	}
+
+	pExposureLower := strings.ToLower(args[7])
+
+	if !pExposure[pExposureLower] {
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid Program Exposure "+pExposureLower)
+	}
+
+	dPercentage, err := strconv.ParseInt(args[8], 10, 64)
+	if err != nil {
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid discount percentage")
+	}
+
+	dPeriod, err := strconv.ParseInt(args[9], 10, 64)
+	if err != nil {
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid discount period")
+	}
+
+	//SanctionDate -> sDate
+	sDate := pSDate
+
+	//args[12] -> calendar the due dates are adjusted on, args[13] -> by which rule
+	calendarID, dueDateRule := "", clock.Unadjusted
+	if len(args) > 12 && args[12] != "" {
+		calendarID = args[12]
+		exists, err := common.NewCalendarClient(stub).Exists(calendarID)
+		if err != nil {
+			return errcode.Error("programcc", errcode.Internal, err.Error())
+		} else if !exists {
+			return errcode.Error("programcc", errcode.NotFound, "CalendarId "+calendarID+" does not exits")
+		}
+		dueDateRule = clock.Following
+	}
+	if len(args) > 13 && args[13] != "" {
+		dueDateRule, err = clock.ParseBusinessDayRule(args[13])
+		if err != nil {
+			return errcode.Error("programcc", errcode.InvalidArgument, err.Error())
+		}
+	}
+
+	//Wallet ID for repayment
+	repayWalletID, err := business.WalletID(args[2], "main")
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	}
+	pInfo := programInfo{args[1], args[2], pTypeLower, pSDate, pEDate, pLimit, pROI, pExposureLower, dPercentage, dPeriod, args[10], sDate, args[11], repayWalletID, calendarID, string(dueDateRule), nil}
+	programInfoBytes, _ := json.Marshal(pInfo)
+	err = history.Put(stub, args[0], programInfoBytes)
+	return shim.Success([]byte("successfully added the program to the ledger"))
+}
+
+func programIDexists(stub shim.ChaincodeStubInterface, prgrmID string) pb.Response {
+	ifExists, _ := stub.GetState(prgrmID)
+	if ifExists != nil {
+		fmt.Println(ifExists)
+		return errcode.Error("programcc", errcode.AlreadyExists, "ProgramId "+prgrmID+" exits. Cannot create new ID")
+	}
+	return shim.Success(nil)
+}
+
+func updateProgramInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+		args[0] -> ProgramID
+		args[1] -> Program Limit, Program ROI, Discount Percentage,Discount Period and Program End Date
+		args[2] -> values
+	*/
+
+	pInfo := programInfo{}
+	pInfoBytes, err := stub.GetState(args[0])
+
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	} else if pInfoBytes == nil {
+		return errcode.Error("programcc", errcode.NotFound, "No information on this programID(updateProgramInfo): "+args[0])
+	}
+
+	err = json.Unmarshal(pInfoBytes, &pInfo)
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	}
+
+	lowerStr := strings.ToLower(args[1])
+
+	if lowerStr == "program end date" {
+		pEDate, err := time.Parse("02/01/2006", args[2])
+		if err != nil {
+			return errcode.Error("programcc", errcode.InvalidArgument, "updateProgramInfo updating programEndDate"+err.Error())
+		}
+		pInfo.ProgramEndDate = pEDate
+	}
+
+	value, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return errcode.Error("programcc", errcode.InvalidArgument, "value (updateProgramInfo):"+err.Error())
+	}
+
+	if lowerStr == "program limit" {
+		pInfo.ProgramLimit = value
+	} else if lowerStr == "program roi" {
+		pInfo.ProgramROI = value
+	} else if lowerStr == "discount percentage" {
+		pInfo.DiscountPercentage = value
+	} else if lowerStr == "discount period" {
+		pInfo.DiscountPeriod = value
+	}
+
+	return shim.Success([]byte("Program info updation successful"))
+}
+
+func getProgram(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid number of arguments in getProgram (required:1) given:"+xLenStr)
+	}
+
+	pInfo := programInfo{}
+	pInfoBytes, err := stub.GetState(args[0])
+
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	} else if pInfoBytes == nil {
+		return errcode.Error("programcc", errcode.NotFound, "No information on this programID: "+args[0])
+	}
+
+	err = json.Unmarshal(pInfoBytes, &pInfo)
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	}
+
+	printProgramInfo := fmt.Sprintf("%+v", pInfo)
+
+	return shim.Success([]byte(printProgramInfo))
+
+}
+
+func getProgramHistory(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> ProgramID
+	 *args[1] -> page size, 20 when not given (at most 100)
+	 *args[2] -> bookmark of the previous page, for the next one
+	 */
+	if len(args) < 1 || len(args) > 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid number of arguments in getProgramHistory (required:1 to 3) given: "+xLenStr)
+	}
+	page, err := history.Get(stub, args[0], args[1:])
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	}
+	pageBytes, _ := json.Marshal(page)
+	return shim.Success(pageBytes)
+}
+
+func getDueDateRule(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> ProgramID
+	 */
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid number of arguments in getDueDateRule (required:1) given: "+xLenStr)
+	}
+	pInfo := programInfo{}
+	pInfoBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	} else if pInfoBytes == nil {
+		return errcode.Error("programcc", errcode.NotFound, "No information on this programID: "+args[0])
+	}
+	err = json.Unmarshal(pInfoBytes, &pInfo)
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	}
+
+	// programs written before calendars keep their due dates
+	rule := struct {
+		Calendar    string `json:"calendar"`
+		DueDateRule string `json:"dueDateRule"`
+	}{pInfo.Calendar, pInfo.DueDateRule}
+	if rule.DueDateRule == "" {
+		rule.DueDateRule = string(clock.Unadjusted)
+	}
+	ruleBytes, _ := json.Marshal(rule)
+	return shim.Success(ruleBytes)
+}
+
+func putWaterfall(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> ProgramID
+	 *args[1] -> Components in the order they are paid, separated by commas: penal, charges, interest and principal
+	 */
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid number of arguments in putWaterfall (required:2) given: "+xLenStr)
+	}
+	pInfo := programInfo{}
+	pInfoBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	} else if pInfoBytes == nil {
+		return errcode.Error("programcc", errcode.NotFound, "No information on this programID: "+args[0])
+	}
+	err = json.Unmarshal(pInfoBytes, &pInfo)
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	}
+
+	waterfall := []string{}
+	seen := map[string]bool{}
+	for _, component := range strings.Split(args[1], ",") {
+		component = strings.ToLower(strings.TrimSpace(component))
+		if !repaymentComponents[component] {
+			return errcode.Error("programcc", errcode.InvalidArgument, "Invalid repayment component "+component+", use penal, charges, interest or principal")
+		} else if seen[component] {
+			return errcode.Error("programcc", errcode.InvalidArgument, "Repayment component "+component+" is given twice")
+		}
+		seen[component] = true
+		waterfall = append(waterfall, component)
+	}
+	for _, component := range defaultWaterfall {
+		if !seen[component] {
+			return errcode.Error("programcc", errcode.InvalidArgument, "The waterfall leaves out "+component+", it has to pay penal, charges, interest and principal")
+		}
+	}
+
+	pInfo.Waterfall = waterfall
+	pInfoBytes, _ = json.Marshal(pInfo)
+	err = history.Put(stub, args[0], pInfoBytes)
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, "Error in program updation "+err.Error())
+	}
+	waterfallBytes, _ := json.Marshal(waterfall)
+	return shim.Success(waterfallBytes)
+}
+
+func getWaterfall(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> ProgramID
+	 */
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("programcc", errcode.InvalidArgument, "Invalid number of arguments in getWaterfall (required:1) given: "+xLenStr)
+	}
+	pInfo := programInfo{}
+	pInfoBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	} else if pInfoBytes == nil {
+		return errcode.Error("programcc", errcode.NotFound, "No information on this programID: "+args[0])
+	}
+	err = json.Unmarshal(pInfoBytes, &pInfo)
+	if err != nil {
+		return errcode.Error("programcc", errcode.Internal, err.Error())
+	}
+	waterfall := pInfo.Waterfall
+	if len(waterfall) == 0 {
+		waterfall = defaultWaterfall
+	}
+	waterfallBytes, _ := json.Marshal(waterfall)
+	return shim.Success(waterfallBytes)
+}
@@ -0,0 +1,112 @@
+// Package query runs CouchDB rich queries on the state of a chaincode and
+// returns the records that match, a page at a time with Get or all at once
+// with All. Paginated queries run only in read-only transactions, so the
+// functions that use Get must not write.
+package query
+
+import (
+	"encoding/json"
+	"strconv"
+
+	errcode "github.com/chaincode/Errcode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Page sizes
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Result is a record that matched, with its key
+type Result struct {
+	Key    string          `json:"key"`
+	Record json.RawMessage `json:"record"`
+}
+
+// Page is some of the records that matched. Bookmark is passed to Get for
+// the next page and is empty once a page is not full.
+type Page struct {
+	Records             []Result `json:"records"`
+	FetchedRecordsCount int      `json:"fetchedRecordsCount"`
+	Bookmark            string   `json:"bookmark"`
+}
+
+// Sort is a field to sort by and the direction, "asc" or "desc"
+type Sort map[string]string
+
+// PageSize returns the page size argument, DefaultPageSize when it is empty
+func PageSize(arg string) (int, error) {
+	if arg == "" {
+		return DefaultPageSize, nil
+	}
+	size, err := strconv.Atoi(arg)
+	if err != nil || size < 1 || size > MaxPageSize {
+		return 0, errcode.New(errcode.InvalidArgument, "page size has to be from 1 to "+strconv.Itoa(MaxPageSize)+", given: "+arg)
+	}
+	return size, nil
+}
+
+// Get returns a page of the records that match the Mango selector, in the
+// order of sort. pageArgs are the page size and the bookmark, both optional.
+func Get(stub shim.ChaincodeStubInterface, selector map[string]interface{}, sort []Sort, pageArgs []string) (Page, error) {
+	page := Page{Records: []Result{}}
+	pageSize := DefaultPageSize
+	bookmark := ""
+	var err error
+	if len(pageArgs) > 0 {
+		pageSize, err = PageSize(pageArgs[0])
+		if err != nil {
+			return page, err
+		}
+	}
+	if len(pageArgs) > 1 {
+		bookmark = pageArgs[1]
+	}
+
+	q := map[string]interface{}{"selector": selector}
+	if len(sort) > 0 {
+		q["sort"] = sort
+	}
+	queryBytes, _ := json.Marshal(q)
+	resultsIterator, meta, err := stub.GetQueryResultWithPagination(string(queryBytes), int32(pageSize), bookmark)
+	if err != nil {
+		return page, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		kv, err := resultsIterator.Next()
+		if err != nil {
+			return page, err
+		}
+		page.Records = append(page.Records, Result{kv.Key, kv.Value})
+	}
+	page.FetchedRecordsCount = len(page.Records)
+	if page.FetchedRecordsCount == pageSize {
+		page.Bookmark = meta.Bookmark
+	}
+	return page, nil
+}
+
+// All returns every record that matches the Mango selector, in key order. It
+// can run in a transaction that writes, but Fabric does not check that the
+// records are still the same when the transaction is committed.
+func All(stub shim.ChaincodeStubInterface, selector map[string]interface{}) ([]Result, error) {
+	results := []Result{}
+	queryBytes, _ := json.Marshal(map[string]interface{}{"selector": selector})
+	resultsIterator, err := stub.GetQueryResult(string(queryBytes))
+	if err != nil {
+		return results, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		kv, err := resultsIterator.Next()
+		if err != nil {
+			return results, err
+		}
+		results = append(results, Result{kv.Key, kv.Value})
+	}
+	return results, nil
+}
@@ -0,0 +1,205 @@
+// Package schema lets a chaincode function take its arguments as one JSON
+// object with named fields, e.g.
+//
+//	{"Args":["submitTxn","{\"txnID\":\"1txn\",\"txnType\":\"disbursement\",...}"]}
+//
+// and checks them field by field. Every chaincode keeps Schemas with the
+// Fields of its functions and runs Parse on the arguments at the top of
+// Invoke, which hands the function the positional arguments it has always
+// taken.
+//
+// The positional form is still accepted, and checked the same way when it
+// has the right number of arguments, until the next release.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is what a field holds
+type Kind int
+
+// Kinds
+const (
+	Text     Kind = iota
+	Int           // a whole number, e.g. a limit or a period in days
+	Number        // a decimal number, e.g. an amount or a rate
+	Date          // dd/mm/yyyy
+	DateTime      // dd/mm/yyyy:hh:mm:ss
+)
+
+// Field is one argument of a function
+type Field struct {
+	Name     string
+	Kind     Kind
+	Optional bool
+}
+
+// Fields are the arguments of a function in their positional order.
+// Optional fields come last.
+type Fields []Field
+
+// Schemas holds the Fields of every function of a chaincode that takes named
+// arguments
+type Schemas map[string]Fields
+
+// FieldError is a field that is missing or does not hold its kind
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error lists every field that failed
+type Error struct {
+	Function string
+	Fields   []FieldError
+}
+
+func (e *Error) Error() string {
+	msgs := []string{}
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return "invalid arguments to " + e.Function + ": " + strings.Join(msgs, "; ")
+}
+
+// Parse returns the positional arguments of the function. A single argument
+// holding a JSON object is read by field name; other arguments are
+// positional and checked when there are as many as the function takes.
+// Functions without Fields get their arguments unchanged.
+func (s Schemas) Parse(function string, args []string) ([]string, error) {
+	fields, ok := s[function]
+	if !ok {
+		return args, nil
+	}
+	if len(args) == 1 && strings.HasPrefix(strings.TrimSpace(args[0]), "{") {
+		return fields.fromJSON(function, args[0])
+	}
+
+	if len(args) < fields.required() || len(args) > len(fields) {
+		// left to the function, which says how many it takes
+		return args, nil
+	}
+	e := &Error{Function: function}
+	for i, arg := range args {
+		msg := fields[i].check(arg)
+		if msg != "" {
+			e.Fields = append(e.Fields, FieldError{fields[i].Name, msg})
+		}
+	}
+	if len(e.Fields) > 0 {
+		return nil, e
+	}
+	return args, nil
+}
+
+func (f Fields) fromJSON(function string, payload string) ([]string, error) {
+	values := map[string]json.RawMessage{}
+	dec := json.NewDecoder(strings.NewReader(payload))
+	dec.UseNumber()
+	err := dec.Decode(&values)
+	if err != nil {
+		return nil, errors.New("invalid arguments to " + function + ": " + err.Error())
+	}
+
+	e := &Error{Function: function}
+	known := map[string]bool{}
+	args := []string{}
+	for _, field := range f {
+		known[field.Name] = true
+		raw, ok := values[field.Name]
+		if !ok || bytes.Equal(raw, []byte("null")) {
+			if !field.Optional {
+				e.Fields = append(e.Fields, FieldError{field.Name, "is required"})
+			}
+			args = append(args, "")
+			continue
+		}
+		value, msg := text(raw)
+		if msg == "" {
+			msg = field.check(value)
+		}
+		if msg == "" && value == "" && !field.Optional {
+			msg = "is required"
+		}
+		if msg != "" {
+			e.Fields = append(e.Fields, FieldError{field.Name, msg})
+		}
+		args = append(args, value)
+	}
+	unknown := []string{}
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		e.Fields = append(e.Fields, FieldError{name, "is not a field of " + function})
+	}
+	if len(e.Fields) > 0 {
+		return nil, e
+	}
+
+	// optional fields left out are not passed
+	for len(args) > f.required() && args[len(args)-1] == "" {
+		args = args[:len(args)-1]
+	}
+	return args, nil
+}
+
+func (f Fields) required() int {
+	n := 0
+	for _, field := range f {
+		if !field.Optional {
+			n++
+		}
+	}
+	return n
+}
+
+// text returns a JSON string or number as the string a positional argument
+// would hold
+func text(raw json.RawMessage) (string, string) {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s, ""
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		return n.String(), ""
+	}
+	return "", "must be a string or a number"
+}
+
+// check returns what is wrong with the value of the field, or ""
+func (f Field) check(value string) string {
+	if value == "" {
+		return ""
+	}
+	switch f.Kind {
+	case Int:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "must be a whole number, not " + strconv.Quote(value)
+		}
+	case Number:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "must be a number, not " + strconv.Quote(value)
+		}
+	case Date:
+		if _, err := time.Parse("02/01/2006", value); err != nil {
+			return "must be a date as dd/mm/yyyy, not " + strconv.Quote(value)
+		}
+	case DateTime:
+		if _, err := time.Parse("02/01/2006:15:04:05", value); err != nil {
+			return "must be a date and time as dd/mm/yyyy:hh:mm:ss, not " + strconv.Quote(value)
+		}
+	}
+	return ""
+}
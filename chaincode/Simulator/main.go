@@ -0,0 +1,338 @@
+// Simulator replays a scenario of supply chain finance steps against the
+// chaincodes running in process on MockNet, and prints every wallet balance
+// and the txnbalcc rows written after each step, so that an accounting flow
+// can be checked before it is deployed.
+//
+// Usage:
+//
+//	go run github.com/chaincode/Simulator [-v] scenario.json
+//
+// A scenario is a JSON file:
+//
+//	{
+//	  "clients": {"admin": "bank-admin", "maker": "maker", ...},
+//	  "steps": [
+//	    {"name": "create bank", "date": "23/04/2018", "as": "admin",
+//	     "invoke": ["bankcc", "writeBankInfo", "1bank", ...]},
+//	    {"name": "sanction loan", "as": "maker", "approveAs": "checker",
+//	     "invoke": ["loancc", "submitLoan", "1loan", ...]},
+//	    ...
+//	  ]
+//	}
+//
+// clients maps each client name to its comma separated roles, and defaults
+// to admin, maker, checker and ops with the role of the same name. A step
+// invokes a function as a client on the date (dd/mm/yyyy) of the step or of
+// the step before. With approveAs, the request the step submits is then
+// approved by that client. A step with expectError must fail; any other
+// failing step stops the simulation.
+//
+// The chaincodes' own output is dropped unless -v is given.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	bankcc "github.com/chaincode/Bank/bankcc"
+	businesscc "github.com/chaincode/Business/businesscc"
+	calendarcc "github.com/chaincode/Calendar/calendarcc"
+	instrumentcc "github.com/chaincode/Instrument/instrumentcc"
+	loancc "github.com/chaincode/Loan/loancc"
+	mocknet "github.com/chaincode/MockNet"
+	money "github.com/chaincode/Money"
+	pprcc "github.com/chaincode/PPR/pprcc"
+	programcc "github.com/chaincode/Program/programcc"
+	txncc "github.com/chaincode/Transactions/txncc"
+	txnbalcc "github.com/chaincode/TxnBalance/txnbalcc"
+	walletcc "github.com/chaincode/Wallet/walletcc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+type scenario struct {
+	Clients map[string]string `json:"clients"`
+	Steps   []step            `json:"steps"`
+}
+
+type step struct {
+	Name        string   `json:"name"`
+	Date        string   `json:"date"`
+	As          string   `json:"as"`
+	Invoke      []string `json:"invoke"`
+	ApproveAs   string   `json:"approveAs"`
+	ExpectError bool     `json:"expectError"`
+}
+
+var defaultClients = map[string]string{
+	"admin":   "bank-admin",
+	"maker":   "maker",
+	"checker": "checker",
+	"ops":     "ops",
+}
+
+// ownerChaincodes hold the participants whose records name their wallets
+var ownerChaincodes = []string{"bankcc", "businesscc", "loancc"}
+
+type walletInfo struct {
+	Balance  money.Amount `json:"balance"`
+	Currency string       `json:"currency"`
+}
+
+type txnBalanceRow struct {
+	TxnType    string       `json:"TxnType"`
+	WalletID   string       `json:"WalletID"`
+	OpeningBal money.Amount `json:"OpeningBalance"`
+	CAmt       money.Amount `json:"CreditAmount"`
+	DAmt       money.Amount `json:"DebitAmount"`
+	TxnBal     money.Amount `json:"TxnBalance"`
+}
+
+func main() {
+	verbose := flag.Bool("v", false, "print the output of the chaincodes")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: simulator [-v] scenario.json")
+		os.Exit(2)
+	}
+
+	s, err := readScenario(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "simulator: "+err.Error())
+		os.Exit(1)
+	}
+
+	out := os.Stdout
+	if !*verbose {
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err == nil {
+			os.Stdout = devNull
+		}
+	}
+
+	err = run(s, out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "simulator: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+func readScenario(path string) (scenario, error) {
+	s := scenario{}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	if err != nil {
+		return s, errors.New(path + ": " + err.Error())
+	}
+	if len(s.Clients) == 0 {
+		s.Clients = defaultClients
+	}
+	return s, nil
+}
+
+func newNetwork(s scenario) (*mocknet.Network, map[string]*mocknet.Client, error) {
+	n := mocknet.New()
+	n.Add("bankcc", new(bankcc.Chaincode))
+	n.Add("businesscc", new(businesscc.Chaincode))
+	n.Add("calendarcc", new(calendarcc.Chaincode))
+	n.Add("instrumentcc", new(instrumentcc.Chaincode))
+	n.Add("loancc", new(loancc.Chaincode))
+	n.Add("pprcc", new(pprcc.Chaincode))
+	n.Add("programcc", new(programcc.Chaincode))
+	n.Add("txnbalcc", new(txnbalcc.Chaincode))
+	n.Add("txncc", new(txncc.Chaincode))
+	n.Add("walletcc", new(walletcc.Chaincode))
+
+	clients := map[string]*mocknet.Client{}
+	for name, roles := range s.Clients {
+		client, err := n.NewClient("Org1MSP", name, roles)
+		if err != nil {
+			return nil, nil, err
+		}
+		clients[name] = client
+	}
+	return n, clients, nil
+}
+
+func run(s scenario, out io.Writer) error {
+	n, clients, err := newNetwork(s)
+	if err != nil {
+		return err
+	}
+	seenRows := map[string]bool{}
+
+	for i, st := range s.Steps {
+		fmt.Fprintf(out, "== step %d: %s\n", i+1, st.Name)
+		result, err := runStep(n, clients, st)
+		if st.ExpectError {
+			if err == nil {
+				return fmt.Errorf("step %d (%s) was expected to fail", i+1, st.Name)
+			}
+			fmt.Fprintf(out, "failed as expected: %s\n\n", err.Error())
+			continue
+		} else if err != nil {
+			return fmt.Errorf("step %d (%s): %s", i+1, st.Name, err.Error())
+		}
+		fmt.Fprintf(out, "ok: %s\n", result)
+
+		labels := walletLabels(n)
+		printWallets(out, n, labels)
+		printTxnBalances(out, n, labels, seenRows)
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+func runStep(n *mocknet.Network, clients map[string]*mocknet.Client, st step) (string, error) {
+	if len(st.Invoke) < 2 {
+		return "", errors.New("invoke needs a chaincode and a function")
+	}
+	if st.Date != "" {
+		date, err := time.Parse("02/01/2006", st.Date)
+		if err != nil {
+			return "", err
+		}
+		n.Time = date.Add(10 * time.Hour)
+	}
+	client, ok := clients[st.As]
+	if !ok {
+		return "", errors.New("no client " + st.As)
+	}
+
+	response := n.Invoke(client, st.Invoke[0], st.Invoke[1], st.Invoke[2:]...)
+	if response.Status != shim.OK {
+		return "", errors.New(response.Message)
+	}
+	if st.ApproveAs == "" {
+		return string(response.Payload), nil
+	}
+
+	checker, ok := clients[st.ApproveAs]
+	if !ok {
+		return "", errors.New("no client " + st.ApproveAs)
+	} else if !strings.HasPrefix(st.Invoke[1], "submit") {
+		return "", errors.New("approveAs needs a submit function, not " + st.Invoke[1])
+	}
+	approve := "approve" + strings.TrimPrefix(st.Invoke[1], "submit")
+	response = n.Invoke(checker, st.Invoke[0], approve, string(response.Payload))
+	if response.Status != shim.OK {
+		return "", errors.New(approve + ": " + response.Message)
+	}
+	return string(response.Payload), nil
+}
+
+// walletLabels names each wallet after its owner and the field of the
+// owner's record holding it, e.g. "bankcc 1bank MainWallet"
+func walletLabels(n *mocknet.Network) map[string]string {
+	labels := map[string]string{}
+	for _, ccName := range ownerChaincodes {
+		for key, value := range records(n, ccName) {
+			record := map[string]interface{}{}
+			if json.Unmarshal(value, &record) != nil {
+				continue
+			}
+			for field, v := range record {
+				walletID, ok := v.(string)
+				if ok && strings.HasSuffix(field, "Wallet") {
+					labels[walletID] = ccName + " " + key + " " + field
+				}
+			}
+		}
+	}
+	return labels
+}
+
+func printWallets(out io.Writer, n *mocknet.Network, labels map[string]string) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "wallet\tbalance\tcurrency")
+	for _, walletID := range sortedKeys(records(n, "walletcc"), labels) {
+		wallet := walletInfo{}
+		if json.Unmarshal(n.Stub("walletcc").State[walletID], &wallet) != nil || wallet.Currency == "" {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", label(walletID, labels), wallet.Balance.String(), wallet.Currency)
+	}
+	w.Flush()
+}
+
+// printTxnBalances prints the txnbalcc rows written since the last call, in
+// the order of their legs
+func printTxnBalances(out io.Writer, n *mocknet.Network, labels map[string]string, seen map[string]bool) {
+	legs := []string{}
+	for legID := range records(n, "txnbalcc") {
+		if !seen[legID] {
+			seen[legID] = true
+			legs = append(legs, legID)
+		}
+	}
+	if len(legs) == 0 {
+		return
+	}
+	sort.Slice(legs, func(i, j int) bool {
+		if len(legs[i]) != len(legs[j]) {
+			return len(legs[i]) < len(legs[j])
+		}
+		return legs[i] < legs[j]
+	})
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "leg\ttype\twallet\topening\tcredit\tdebit\tbalance")
+	for _, legID := range legs {
+		row := txnBalanceRow{}
+		if json.Unmarshal(n.Stub("txnbalcc").State[legID], &row) != nil {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", legID, row.TxnType, label(row.WalletID, labels),
+			row.OpeningBal.String(), row.CAmt.String(), row.DAmt.String(), row.TxnBal.String())
+	}
+	w.Flush()
+}
+
+// records returns the state of the chaincode that is not under a composite
+// key
+func records(n *mocknet.Network, ccName string) map[string][]byte {
+	state := map[string][]byte{}
+	for key, value := range n.Stub(ccName).State {
+		if !strings.HasPrefix(key, "\x00") && !bytes.Equal(value, []byte{0x00}) {
+			state[key] = value
+		}
+	}
+	return state
+}
+
+// sortedKeys sorts the wallets by label, unlabelled ones last
+func sortedKeys(state map[string][]byte, labels map[string]string) []string {
+	keys := []string{}
+	for key := range state {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		li, lj := labels[keys[i]], labels[keys[j]]
+		if (li == "") != (lj == "") {
+			return lj == ""
+		} else if li != lj {
+			return li < lj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func label(walletID string, labels map[string]string) string {
+	if l, ok := labels[walletID]; ok {
+		return l
+	}
+	return walletID
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLifecycleScenario(t *testing.T) {
+	s, err := readScenario("scenarios/lifecycle.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	err = run(s, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "3txn_1   repayment  businesscc 1bus MainWallet") {
+		t.Errorf("repayment legs missing from:\n%s", out.String())
+	}
+}
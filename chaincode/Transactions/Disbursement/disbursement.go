@@ -1,352 +0,0 @@
-package main
-
-import (
-	"errors"
-	"fmt"
-	"strconv"
-	"strings"
-
-	"github.com/hyperledger/fabric/core/chaincode/shim"
-	pb "github.com/hyperledger/fabric/protos/peer"
-)
-
-type chainCode struct {
-}
-
-func (c *chainCode) Init(stub shim.ChaincodeStubInterface) pb.Response {
-	return shim.Success(nil)
-}
-
-func (c *chainCode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
-	function, args := stub.GetFunctionAndParameters()
-
-	if function == "newDisbInfo" {
-		//Creates new disbursement info
-		return newDisbInfo(stub, args)
-	}
-	return shim.Error("disbursementcc: " + "no function named " + function + " found in Disbursement")
-}
-
-func newDisbInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-
-	if len(args) == 1 {
-		args = strings.Split(args[0], ",")
-	}
-	if len(args) != 10 {
-		xLenStr := strconv.Itoa(len(args))
-		return shim.Error("disbursementcc: " + "Invalid number of arguments in newDisbInfo(disbursement) (required:10) given:" + xLenStr)
-	}
-
-	/*
-	 *TxnType string    //args[1]
-	 *TxnDate time.Time //args[2]
-	 *LoanID  string    //args[3]
-	 *InsID   string    //args[4]
-	 *Amt     int64     //args[5]
-	 *FromID  string    //args[6]	bank
-	 *ToID    string    //args[7]	seller
-	 *By      string    //args[8]
-	 *PprID   string    //args[9]
-	 */
-
-	//Validations
-	//Getting the sanction amount and the status
-	chaincodeArgs := toChaincodeArgs("getLoanStatus", args[3])
-	response := stub.InvokeChaincode("loancc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("disbursementcc: " + response.Message)
-	}
-	status := string(response.Payload)
-	fmt.Println(status)
-	if (status != "sanctioned") && (status != "part disbursed") {
-		return shim.Error("disbursementcc: " + "loan status for loanID " + args[3] + " is not sanctioned / part disbursed")
-	}
-
-	//sancAmt, _ := strconv.ParseInt(statusNamt[1], 10, 64)
-	chaincodeArgs = toChaincodeArgs("getLoanSancAmt", args[3])
-	response = stub.InvokeChaincode("loancc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("disbursementcc: " + response.Message)
-	}
-	sancAmt, err := strconv.ParseInt(string(response.Payload), 10, 64)
-	if err != nil {
-		return shim.Error("loancc: error parsing sancAmt")
-	}
-	fmt.Println(sancAmt)
-
-	//Getting the disbursed wallet
-	chaincodeArgs = toChaincodeArgs("getWalletID", args[3], "disbursed")
-	response = stub.InvokeChaincode("loancc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("disbursementcc: " + response.Message)
-	}
-	walletid := string(response.Payload)
-	disbAmt, err := getWalletValues(stub, walletid)
-	if err != nil {
-		return shim.Error("disbursementcc: " + err.Error())
-	}
-	//converting dibAmt to integer for testing
-	amtToBeDisburesed := sancAmt - disbAmt
-
-	amt, _ := strconv.ParseInt(args[5], 10, 64)
-
-	if amt > amtToBeDisburesed {
-		return shim.Error("disbursementcc: " + "Amount is greater than Amount to be disbursed")
-	}
-
-	///////////////////////////////////////////////////////////////////////////////////////////////////
-	// 				UPDATING WALLETS																///
-	///////////////////////////////////////////////////////////////////////////////////////////////////
-	// The transaction object has been created and written into the ledger
-	// The JSON file is 'transaction'function
-	// Now to create a TXN_Bal_Update obj for 6 times
-	// Calling TXN_Balance CC based on TXN_Type
-	/*
-	   a. Debiting (Reducing) Bank Wallet
-	   b. Crediting (Increasing) Business Wallet
-	   c. Crediting (Increasing) Bank Asset Wallet
-	   d. Crediting (Increasing) Business Loan Wallet
-	   e. Crediting (Increasing) Business Principal O/s Wallet
-	   f. Crediting (Increasing) Loan Disbursed Wallet
-	*/
-
-	//####################################################################################################################
-	//Calling for updating Bank Main_Wallet
-	//####################################################################################################################
-
-	cAmtString := "0"
-	dAmtString := args[5]
-
-	walletID, openBalString, txnBalString, err := getWalletInfo(stub, args[6], "main", "bankcc", cAmtString, dAmtString)
-	if err != nil {
-		return shim.Error("disbursementcc: " + "Bank Main Wallet(Disbursement):" + err.Error())
-	}
-
-	// STEP-4 generate txn_balance_object and write it to the Txn_Bal_Ledger
-	argsList := []string{"1", args[0], args[2], args[3], args[4], walletID, openBalString, args[1], args[5], cAmtString, dAmtString, txnBalString, args[8]}
-	argsListStr := strings.Join(argsList, ",")
-	chaincodeArgs = toChaincodeArgs("putTxnBalInfo", argsListStr)
-	fmt.Println("calling the other chaincode")
-	response = stub.InvokeChaincode("txnbalcc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("disbursementcc: " + response.Message)
-	}
-	fmt.Println(string(response.GetPayload()))
-
-	//#####################################################################################################################
-	//Calling for updating Business Main_Wallet
-	//####################################################################################################################
-
-	cAmtString = args[5]
-	dAmtString = "0"
-
-	walletID, openBalString, txnBalString, err = getWalletInfo(stub, args[7], "main", "businesscc", cAmtString, dAmtString)
-	if err != nil {
-		return shim.Error("disbursementcc: " + "Business Main Wallet(Disbursement):" + err.Error())
-	}
-
-	// STEP-4 generate txn_balance_object and write it to the Txn_Bal_Ledger
-	argsList = []string{"2", args[0], args[2], args[3], args[4], walletID, openBalString, args[1], args[5], cAmtString, dAmtString, txnBalString, args[8]}
-	argsListStr = strings.Join(argsList, ",")
-	chaincodeArgs = toChaincodeArgs("putTxnBalInfo", argsListStr)
-	fmt.Println("calling the other chaincode")
-	response = stub.InvokeChaincode("txnbalcc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("disbursementcc: " + response.Message)
-	}
-	fmt.Println(string(response.GetPayload()))
-
-	//####################################################################################################################
-	//Calling for updating Business Loan_Wallet
-	//####################################################################################################################
-
-	cAmtString = args[5]
-	dAmtString = "0"
-
-	walletID, openBalString, txnBalString, err = getWalletInfo(stub, args[7], "loan", "businesscc", cAmtString, dAmtString)
-	if err != nil {
-		return shim.Error("disbursementcc: " + "Business Loan Wallet(Disbursement)" + err.Error())
-	}
-
-	// STEP-4 generate txn_balance_object and write it to the Txn_Bal_Ledger
-	argsList = []string{"3", args[0], args[2], args[3], args[4], walletID, openBalString, args[1], args[5], cAmtString, dAmtString, txnBalString, args[8]}
-	argsListStr = strings.Join(argsList, ",")
-	chaincodeArgs = toChaincodeArgs("putTxnBalInfo", argsListStr)
-	fmt.Println("calling the other chaincode")
-	response = stub.InvokeChaincode("txnbalcc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("disbursementcc: " + response.Message)
-	}
-	fmt.Println(string(response.GetPayload()))
-
-	//####################################################################################################################
-	//Calling for updating Bank Asset_Wallet
-	//####################################################################################################################
-
-	cAmtString = args[5]
-	dAmtString = "0"
-
-	walletID, openBalString, txnBalString, err = getWalletInfo(stub, args[6], "asset", "bankcc", cAmtString, dAmtString)
-	if err != nil {
-		return shim.Error("disbursementcc: " + "Bank Asset Wallet(Disbursement)" + err.Error())
-	}
-
-	// STEP-4 generate txn_balance_object and write it to the Txn_Bal_Ledger
-	argsList = []string{"4", args[0], args[2], args[3], args[4], walletID, openBalString, args[1], args[5], cAmtString, dAmtString, txnBalString, args[8]}
-	argsListStr = strings.Join(argsList, ",")
-	chaincodeArgs = toChaincodeArgs("putTxnBalInfo", argsListStr)
-	fmt.Println("calling the other chaincode")
-	response = stub.InvokeChaincode("txnbalcc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("disbursementcc: " + response.Message)
-	}
-	fmt.Println(string(response.GetPayload()))
-
-	//####################################################################################################################
-	//Calling for updating Business principal O/S Wallet
-	//####################################################################################################################
-
-	cAmtString = args[5]
-	dAmtString = "0"
-
-	walletID, openBalString, txnBalString, err = getWalletInfo(stub, args[7], "principalOut", "businesscc", cAmtString, dAmtString)
-	if err != nil {
-		return shim.Error("disbursementcc: " + "Business principal O/S Wallet(Disbursement)" + err.Error())
-	}
-
-	// STEP-4 generate txn_balance_object and write it to the Txn_Bal_Ledger
-	argsList = []string{"5", args[0], args[2], args[3], args[4], walletID, openBalString, args[1], args[5], cAmtString, dAmtString, txnBalString, args[8]}
-	argsListStr = strings.Join(argsList, ",")
-	chaincodeArgs = toChaincodeArgs("putTxnBalInfo", argsListStr)
-	fmt.Println("calling the other chaincode")
-	response = stub.InvokeChaincode("txnbalcc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("disbursementcc: " + response.Message)
-	}
-	fmt.Println(string(response.GetPayload()))
-
-	//####################################################################################################################
-	//Calling for updating Loan Disbursed Wallet
-	//####################################################################################################################
-
-	cAmtString = args[5]
-	dAmtString = "0"
-
-	walletID, openBalString, txnBalString, err = getWalletInfo(stub, args[3], "disbursed", "loancc", cAmtString, dAmtString)
-	if err != nil {
-		return shim.Error("disbursementcc: " + "Loan Disbursed Wallet(Disbursement)" + err.Error())
-	}
-
-	// STEP-4 generate txn_balance_object and write it to the Txn_Bal_Ledger
-	argsList = []string{"5", args[0], args[2], args[3], args[4], walletID, openBalString, args[1], args[5], cAmtString, dAmtString, txnBalString, args[8]}
-	argsListStr = strings.Join(argsList, ",")
-	chaincodeArgs = toChaincodeArgs("putTxnBalInfo", argsListStr)
-	fmt.Println("calling the other chaincode")
-	response = stub.InvokeChaincode("txnbalcc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("disbursementcc: " + response.Message)
-	}
-	fmt.Println(string(response.GetPayload()))
-
-	//####################################################################################################################
-
-	//####################################################################################################################
-	//Calling Loan to change the status
-	//####################################################################################################################
-
-	if amtToBeDisburesed == 0 {
-		status = "disbursed"
-	} else if amtToBeDisburesed > 0 {
-		status = "part disbursed"
-	}
-
-	//calling to change loan status
-	chaincodeArgs = toChaincodeArgs("updateLoanInfo", args[3], status, "disbursement")
-	response = stub.InvokeChaincode("loancc", chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return shim.Error("disbursementcc: " + response.Message)
-	}
-	return shim.Success(nil)
-}
-
-func toChaincodeArgs(args ...string) [][]byte {
-	bargs := make([][]byte, len(args))
-	for i, arg := range args {
-		bargs[i] = []byte(arg)
-	}
-	return bargs
-}
-
-func getWalletInfo(stub shim.ChaincodeStubInterface, participantID string, walletType string, ccName string, cAmtStr string, dAmtStr string) (string, string, string, error) {
-
-	// STEP-1
-	// using FromID, get a walletID from bank structure
-	// bankID = bankID
-
-	chaincodeArgs := toChaincodeArgs("getWalletID", participantID, walletType)
-	response := stub.InvokeChaincode(ccName, chaincodeArgs, "myc")
-	if response.Status != shim.OK {
-		return "", "", "", errors.New(response.Message)
-	}
-	walletID := string(response.GetPayload())
-
-	// STEP-2
-	// getting Balance from walletID
-	// walletFcn := "getWallet"
-	walletArgs := toChaincodeArgs("getWallet", walletID)
-	walletResponse := stub.InvokeChaincode("walletcc", walletArgs, "myc")
-	if walletResponse.Status != shim.OK {
-		return "", "", "", errors.New(walletResponse.Message)
-	}
-	openBalString := string(walletResponse.Payload)
-
-	openBal, err := strconv.ParseInt(openBalString, 10, 64)
-	if err != nil {
-		return "", "", "", errors.New("Error in converting the openBalance")
-	}
-	cAmt, err := strconv.ParseInt(cAmtStr, 10, 64)
-	if err != nil {
-		return "", "", "", errors.New("Error in converting the cAmt")
-	}
-	dAmt, err := strconv.ParseInt(dAmtStr, 10, 64)
-	if err != nil {
-		return "", "", "", errors.New("Error in converting the dAmt")
-	}
-
-	txnBal := openBal - dAmt + cAmt
-	txnBalString := strconv.FormatInt(txnBal, 10)
-
-	// STEP-3
-	// update wallet of ID walletID here, and write it to the wallet_ledger
-	// walletFcn := "updateWallet"
-
-	walletArgs = toChaincodeArgs("updateWallet", walletID, txnBalString)
-	walletResponse = stub.InvokeChaincode("walletcc", walletArgs, "myc")
-	if walletResponse.Status != shim.OK {
-		return "", "", "", errors.New(walletResponse.Message)
-	}
-
-	return walletID, openBalString, txnBalString, nil
-}
-
-func getWalletValues(stub shim.ChaincodeStubInterface, walletID string) (int64, error) {
-
-	walletArgs := toChaincodeArgs("getWallet", walletID)
-	walletResponse := stub.InvokeChaincode("walletcc", walletArgs, "myc")
-	if walletResponse.Status != shim.OK {
-		return 0, errors.New(walletResponse.Message)
-	}
-	openBalString := string(walletResponse.Payload)
-	openBal, err := strconv.ParseInt(openBalString, 10, 64)
-	if err != nil {
-		return 0, errors.New("Error in converting the openBalance in getWalletValues(disbursement)")
-	}
-	return openBal, nil
-}
-
-func main() {
-	err := shim.Start(new(chainCode))
-	if err != nil {
-		fmt.Println("disbursementcc: " + "Unable to start the chaincode")
-	}
-}
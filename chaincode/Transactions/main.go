@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+
+	txncc "github.com/chaincode/Transactions/txncc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() {
+	err := shim.Start(new(txncc.Chaincode))
+	if err != nil {
+		fmt.Printf("transactioncc: "+"Error starting Transaction chaincode: %s\n", err)
+	}
+}
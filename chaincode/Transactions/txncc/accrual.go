@@ -0,0 +1,238 @@
+package txncc
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	clock "github.com/chaincode/Clock"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	events "github.com/chaincode/Events"
+	money "github.com/chaincode/Money"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// The accrual run computes the interest every loan that earns interest owes
+// for a business date, on the balance of its disbursed wallet at its ROI,
+// and posts it as an accrual txn. A loan accrues from its value date, or from
+// the last date it was accrued for, to the business date; a loan already
+// accrued for the date is skipped, so a run can be repeated.
+
+// accrualIndex is the composite key prefix of the last business date each
+// loan was accrued for
+const accrualIndex = "Accrual~LoanID"
+
+// settingIndex is the composite key prefix of the settings of txncc
+const settingIndex = "Setting~Name"
+
+// defaultDayCount is the day-count convention until one is put
+const defaultDayCount = money.Act365
+
+// loanAccrual is what the run did with a loan
+type loanAccrual struct {
+	LoanID    string       `json:"loanID"`
+	TxnID     string       `json:"txnID,omitempty"`
+	From      time.Time    `json:"from"`
+	Days      int64        `json:"days"`
+	Principal money.Amount `json:"principal"`
+	Interest  money.Amount `json:"interest"`
+	Skipped   string       `json:"skipped,omitempty"` // why nothing was posted
+}
+
+// accrualRun is the result of accrueInterest
+type accrualRun struct {
+	BusinessDate time.Time     `json:"businessDate"`
+	DayCount     string        `json:"dayCount"`
+	Loans        []loanAccrual `json:"loans"`
+}
+
+func accrueInterest(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> Business date to accrue to
+	 *args[1] -> BankID, the to of the accrual txns
+	 *args[2] -> By
+	 */
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in accrueInterest (required:3) given: "+xLenStr)
+	}
+	date, err := time.Parse(clock.DateLayout, args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+	}
+	today, err := clock.PostingDate(stub)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if date.After(today) {
+		return errcode.Error("txncc", errcode.InvalidArgument, "Cannot accrue interest for "+args[0]+", a date after today "+today.Format(clock.DateLayout))
+	}
+	dayCount, err := getDayCountSetting(stub)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+
+	loans, err := common.NewLoanClient(stub).Accruing()
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	run := accrualRun{BusinessDate: date, DayCount: string(dayCount), Loans: []loanAccrual{}}
+	batch := newPostingBatch(stub)
+	evs := []events.Event{}
+	for _, loan := range loans {
+		accrual, loanEvs, err := accrueLoan(stub, batch, loan, date, dayCount, args[1], args[2])
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, "Loan "+loan.LoanID+": "+err.Error())
+		}
+		run.Loans = append(run.Loans, accrual)
+		evs = append(evs, loanEvs...)
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = events.Emit(stub, "transactioncc", evs...)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	runBytes, _ := json.Marshal(run)
+	return shim.Success(runBytes)
+}
+
+// accrueLoan posts the interest of the loan up to the date in the batch and
+// returns the events of the txn
+func accrueLoan(stub shim.ChaincodeStubInterface, batch *postingBatch, loan common.AccruingLoan, date time.Time, dayCount money.DayCount, bankID string, by string) (loanAccrual, []events.Event, error) {
+	valueDate := time.Date(loan.ValueDate.Year(), loan.ValueDate.Month(), loan.ValueDate.Day(), 0, 0, 0, 0, time.UTC)
+	return postInterest(stub, batch, loan, interestPosting{"accrual", accrualIndex, valueDate, loan.ROI, loan.SellerID, bankID}, date, dayCount, by)
+}
+
+// interestPosting is interest of one kind a loan owes: the txn type it is
+// posted as, the composite key prefix of the last business date it was
+// posted for, the date it runs from, its rate and the parties of the txn
+type interestPosting struct {
+	txnType string
+	index   string
+	start   time.Time
+	rate    money.Rate
+	from    string
+	to      string
+}
+
+// postInterest posts the interest of the loan from the later of the start
+// and the last date it was posted for up to the date in the batch, and
+// returns the events of the txn
+func postInterest(stub shim.ChaincodeStubInterface, batch *postingBatch, loan common.AccruingLoan, posting interestPosting, date time.Time, dayCount money.DayCount, by string) (loanAccrual, []events.Event, error) {
+	accrual := loanAccrual{LoanID: loan.LoanID}
+	from := posting.start
+	last, err := lastPostedDate(stub, posting.index, loan.LoanID)
+	if err != nil {
+		return accrual, nil, err
+	}
+	if last.After(from) {
+		from = last
+	}
+	accrual.From = from
+	if !date.After(from) {
+		accrual.Skipped = "runs from " + from.Format(clock.DateLayout)
+		if from.Equal(last) {
+			accrual.Skipped = "already posted for " + last.Format(clock.DateLayout)
+		}
+		return accrual, nil, nil
+	}
+
+	accrual.Principal, err = batch.balance(loan.DisbursedWalletID)
+	if err != nil {
+		return accrual, nil, err
+	}
+	var basis int64
+	accrual.Days, basis = dayCount.Days(from, date)
+	accrual.Interest = accrual.Principal.Interest(posting.rate, accrual.Days, basis, money.HalfUp)
+
+	var evs []events.Event
+	if accrual.Interest.Sign() > 0 {
+		accrual.TxnID = posting.txnType + "-" + loan.LoanID + "-" + date.Format("20060102")
+		response, txnEvs := postTxn(stub, batch, []string{accrual.TxnID, posting.txnType, date.Format(clock.DateLayout), loan.LoanID, loan.InstrumentID, accrual.Interest.String(), posting.from, posting.to, by})
+		if response.Status != shim.OK {
+			return accrual, nil, errors.New(response.Message)
+		}
+		evs = txnEvs
+	} else {
+		accrual.Skipped = "no interest on " + accrual.Principal.String()
+	}
+	return accrual, evs, putLastPostedDate(stub, posting.index, loan.LoanID, date)
+}
+
+// lastPostedDate returns the last business date the interest under the index
+// was posted for the loan, or the zero time
+func lastPostedDate(stub shim.ChaincodeStubInterface, index string, loanID string) (time.Time, error) {
+	last := time.Time{}
+	lastKey, err := stub.CreateCompositeKey(index, []string{loanID})
+	if err != nil {
+		return last, err
+	}
+	lastBytes, err := stub.GetState(lastKey)
+	if err != nil || lastBytes == nil {
+		return last, err
+	}
+	err = json.Unmarshal(lastBytes, &last)
+	return last, err
+}
+
+func putLastPostedDate(stub shim.ChaincodeStubInterface, index string, loanID string, date time.Time) error {
+	lastKey, err := stub.CreateCompositeKey(index, []string{loanID})
+	if err != nil {
+		return err
+	}
+	dateBytes, _ := json.Marshal(date)
+	return stub.PutState(lastKey, dateBytes)
+}
+
+func putDayCount(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> Day-count convention of the accrual run: ACT/365, ACT/360 or 30/360
+	 */
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in putDayCount (required:1) given: "+xLenStr)
+	}
+	dayCount, err := money.ParseDayCount(args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+	}
+	settingKey, err := stub.CreateCompositeKey(settingIndex, []string{"dayCount"})
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = stub.PutState(settingKey, []byte(dayCount))
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "cannot write day-count convention: "+err.Error())
+	}
+	return shim.Success([]byte(dayCount))
+}
+
+func getDayCount(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	dayCount, err := getDayCountSetting(stub)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte(dayCount))
+}
+
+// getDayCountSetting returns the day-count convention put, or else the default
+func getDayCountSetting(stub shim.ChaincodeStubInterface) (money.DayCount, error) {
+	settingKey, err := stub.CreateCompositeKey(settingIndex, []string{"dayCount"})
+	if err != nil {
+		return "", err
+	}
+	dayCountBytes, err := stub.GetState(settingKey)
+	if err != nil {
+		return "", err
+	} else if dayCountBytes == nil {
+		return defaultDayCount, nil
+	}
+	return money.ParseDayCount(string(dayCountBytes))
+}
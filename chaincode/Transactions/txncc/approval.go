@@ -0,0 +1,217 @@
+package txncc
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	approval "github.com/chaincode/Approval"
+	errcode "github.com/chaincode/Errcode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// A transaction is posted in two steps: a maker submits the arguments of
+// newTxnInfo as a pending request and a checker approves it, which is when
+// newTxnInfo runs
+
+// pendingTxnIndex and pendingClientRequestIndex are the composite key
+// prefixes mapping the txnID and the client request ID of a pending request
+// to the request ID, so that a submit can be told apart from a retry
+const (
+	pendingTxnIndex           = "PendingTxn~TxnID"
+	pendingClientRequestIndex = "PendingClientRequest~RequestID"
+)
+
+func submitTxn(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) < 9 || len(args) > 11 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in submitTxn (required:9 to 11) given: "+xLenStr)
+	}
+	_, err := postingRuleFor(stub, strings.ToLower(args[1]))
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+
+	ifExists, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if ifExists != nil {
+		return errcode.Error("txncc", errcode.AlreadyExists, "TxnID "+args[0]+" exists. Cannot create new ID")
+	}
+
+	// a client request that is already pending is a retry, and gets the
+	// request it is waiting on
+	if len(args) >= 10 && args[9] != "" {
+		requestID, err := getPendingRequest(stub, pendingClientRequestIndex, args[9])
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		} else if requestID != "" {
+			return shim.Success([]byte(requestID))
+		}
+	}
+	// so is the same transaction submitted again
+	requestID, err := getPendingRequest(stub, pendingTxnIndex, args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if requestID != "" {
+		pending, err := approval.Get(stub, "newTxnInfo", requestID)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		}
+		if strings.Join(pending.Args, "\x00") != strings.Join(args, "\x00") {
+			return errcode.Error("txncc", errcode.AlreadyExists, "TxnID "+args[0]+" is pending approval as request "+requestID)
+		}
+		return shim.Success([]byte(requestID))
+	}
+
+	req, err := approval.Submit(stub, "newTxnInfo", args)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = putPendingRequest(stub, req)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte(req.RequestID))
+}
+
+func approveTxn(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> RequestID
+	 */
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in approveTxn (required:1) given: "+xLenStr)
+	}
+
+	req, err := approval.Approve(stub, "newTxnInfo", args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = delPendingRequest(stub, req)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	response := newTxnInfo(stub, req.Args)
+	if response.Status != shim.OK {
+		return response
+	}
+	// newTxnInfo returns the txnID, which differs from the submitted one
+	// when the client request ID was already posted
+	req.Result = string(response.Payload)
+	err = approval.Put(stub, req)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	return shim.Success(response.Payload)
+}
+
+func rejectTxn(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> RequestID
+	 *args[1] -> Reason
+	 */
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in rejectTxn (required:2) given: "+xLenStr)
+	}
+
+	req, err := approval.Reject(stub, "newTxnInfo", args[0], args[1])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = delPendingRequest(stub, req)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+func getTxnRequest(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in getTxnRequest (required:1) given: "+xLenStr)
+	}
+
+	req, err := approval.Get(stub, "newTxnInfo", args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	reqBytes, _ := json.Marshal(req)
+	return shim.Success(reqBytes)
+}
+
+func listTxnRequests(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> Status (optional: pending, approved or rejected)
+	 */
+	if len(args) > 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in listTxnRequests (required:0 or 1) given: "+xLenStr)
+	}
+	status := ""
+	if len(args) == 1 {
+		status = args[0]
+	}
+
+	reqs, err := approval.List(stub, "newTxnInfo", status)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	reqsBytes, _ := json.Marshal(reqs)
+	return shim.Success(reqsBytes)
+}
+
+// getPendingRequest returns the ID of the pending request with the txnID or
+// client request ID, or "" when there is none
+func getPendingRequest(stub shim.ChaincodeStubInterface, index string, id string) (string, error) {
+	key, err := stub.CreateCompositeKey(index, []string{id})
+	if err != nil {
+		return "", err
+	}
+	requestID, err := stub.GetState(key)
+	return string(requestID), err
+}
+
+// putPendingRequest indexes the pending request by its txnID and client
+// request ID
+func putPendingRequest(stub shim.ChaincodeStubInterface, req approval.Request) error {
+	for index, id := range pendingRequestIDs(req) {
+		key, err := stub.CreateCompositeKey(index, []string{id})
+		if err != nil {
+			return err
+		}
+		err = stub.PutState(key, []byte(req.RequestID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// delPendingRequest drops the index entries of a request once it is decided
+func delPendingRequest(stub shim.ChaincodeStubInterface, req approval.Request) error {
+	for index, id := range pendingRequestIDs(req) {
+		key, err := stub.CreateCompositeKey(index, []string{id})
+		if err != nil {
+			return err
+		}
+		err = stub.DelState(key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pendingRequestIDs(req approval.Request) map[string]string {
+	ids := map[string]string{pendingTxnIndex: req.Args[0]}
+	if len(req.Args) >= 10 && req.Args[9] != "" {
+		ids[pendingClientRequestIndex] = req.Args[9]
+	}
+	return ids
+}
@@ -0,0 +1,160 @@
+package txncc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	money "github.com/chaincode/Money"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Fabric does not show a transaction its own writes: a key read after it is
+// written in the same invocation, by txncc or by a chaincode it calls, reads
+// as it was before, and only the last write of the key is kept. A txn that
+// moves a wallet more than once, or an invocation that posts more than one
+// txn, therefore keeps what it moves in a postingBatch. The batch reads each
+// wallet and amount key once, works out every movement on what it holds and
+// sends the movements of each wallet to walletcc in one call when it is
+// flushed.
+
+// batchWallet is a wallet moved in the batch
+type batchWallet struct {
+	balance money.Amount       // after the movements so far
+	legs    []common.WalletLeg // the movements, in order
+}
+
+// postingBatch is the wallets and amount keys an invocation of txncc moves.
+// walletIDs and putKeys keep the order the wallets were first read and the
+// keys first put in, which is the order they are written in.
+type postingBatch struct {
+	stub      shim.ChaincodeStubInterface
+	wallets   map[string]*batchWallet
+	walletIDs []string
+	amounts   map[string]money.Amount
+	putKeys   []string
+}
+
+func newPostingBatch(stub shim.ChaincodeStubInterface) *postingBatch {
+	return &postingBatch{stub: stub, wallets: map[string]*batchWallet{}, amounts: map[string]money.Amount{}}
+}
+
+func (b *postingBatch) wallet(walletID string) (*batchWallet, error) {
+	w, ok := b.wallets[walletID]
+	if ok {
+		return w, nil
+	}
+	bal, err := common.NewWalletClient(b.stub).Balance(walletID)
+	if err != nil {
+		return nil, err
+	}
+	w = &batchWallet{balance: bal}
+	b.wallets[walletID] = w
+	b.walletIDs = append(b.walletIDs, walletID)
+	return w, nil
+}
+
+// balance returns the balance of the wallet after the movements of the batch
+func (b *postingBatch) balance(walletID string) (money.Amount, error) {
+	w, err := b.wallet(walletID)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	return w.balance, nil
+}
+
+// move credits or debits the wallet under the legID the way walletcc does,
+// refusing an amount in another currency and a balance below zero, and
+// returns the movement. fxRateID is the rate an amount in the wallet's
+// currency was converted by from the txn's, if it was.
+func (b *postingBatch) move(function string, walletID string, amt money.Amount, legID string, fxRateID string) (common.WalletMovement, error) {
+	w, err := b.wallet(walletID)
+	if err != nil {
+		return common.WalletMovement{}, err
+	}
+	if amt.Currency != w.balance.Currency {
+		return common.WalletMovement{}, errcode.New(errcode.InvalidArgument, "Cannot move "+amt.Currency+" in "+w.balance.Currency+" WalletId "+walletID)
+	}
+	mv := common.WalletMovement{TxnID: legID, WalletID: walletID, OpeningBal: w.balance, CAmt: money.Zero(amt.Currency), DAmt: money.Zero(amt.Currency), FxRateID: fxRateID}
+	if function == "debit" {
+		mv.DAmt = amt
+		mv.ClosingBal, err = w.balance.Sub(amt)
+	} else {
+		mv.CAmt = amt
+		mv.ClosingBal, err = w.balance.Add(amt)
+	}
+	if err != nil {
+		return common.WalletMovement{}, err
+	}
+	if mv.ClosingBal.IsNegative() {
+		return common.WalletMovement{}, errcode.New(errcode.InsufficientFunds, fmt.Sprintf("Insufficient balance in WalletId %s: balance %s, debit %s", walletID, w.balance, amt))
+	}
+	w.balance = mv.ClosingBal
+	w.legs = append(w.legs, common.WalletLeg{TxnID: legID, CAmt: mv.CAmt, DAmt: mv.DAmt, FxRateID: fxRateID})
+	return mv, nil
+}
+
+// amount returns the amount txncc keeps under the key, with the changes of
+// the batch, or zero in the currency when there is none
+func (b *postingBatch) amount(key string, currency string) (money.Amount, error) {
+	amt, ok := b.amounts[key]
+	if ok {
+		return amt, nil
+	}
+	amtBytes, err := b.stub.GetState(key)
+	if err != nil {
+		return money.Amount{}, err
+	} else if amtBytes == nil {
+		amt = money.Zero(currency)
+	} else {
+		err = json.Unmarshal(amtBytes, &amt)
+		if err != nil {
+			return money.Amount{}, err
+		}
+	}
+	b.amounts[key] = amt
+	return amt, nil
+}
+
+// putAmount changes the amount under the key
+func (b *postingBatch) putAmount(key string, amt money.Amount) {
+	if !b.put(key) {
+		b.putKeys = append(b.putKeys, key)
+	}
+	b.amounts[key] = amt
+}
+
+// put says whether the key was put in the batch
+func (b *postingBatch) put(key string) bool {
+	for _, putKey := range b.putKeys {
+		if putKey == key {
+			return true
+		}
+	}
+	return false
+}
+
+// flush moves each wallet by its legs, in the order they were moved in the
+// batch, and writes the amount keys
+func (b *postingBatch) flush() error {
+	wallets := common.NewWalletClient(b.stub)
+	for _, walletID := range b.walletIDs {
+		w := b.wallets[walletID]
+		if len(w.legs) == 0 {
+			continue
+		}
+		_, err := wallets.Move(walletID, w.legs)
+		if err != nil {
+			return err
+		}
+	}
+	for _, key := range b.putKeys {
+		amtBytes, _ := json.Marshal(b.amounts[key])
+		err := b.stub.PutState(key, amtBytes)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,505 @@
+package txncc
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	clock "github.com/chaincode/Clock"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	events "github.com/chaincode/Events"
+	money "github.com/chaincode/Money"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// A buyer payment that is more than its loans owe, or that is not applied to
+// any of them, is held by the bank as unapplied credit of the buyer, as is
+// the excess of a repayment of a single loan. It is applied to the loans
+// later with applyUnappliedCredit or paid back with refundUnappliedCredit.
+const (
+	// unappliedIndex is the composite key prefix of the unapplied credit of a buyer
+	unappliedIndex = "Unapplied~BuyerID"
+	// bulkIndex is the composite key prefix of a bulk repayment and of an
+	// application of unapplied credit
+	bulkIndex = "Bulk~BulkID"
+)
+
+// appliedRepayment is the repayment of one loan out of a bulk repayment
+type appliedRepayment struct {
+	TxnID      string       `json:"TxnID"`
+	LoanID     string       `json:"LoanID"`
+	Amount     money.Amount `json:"Amount"`
+	Allocation *allocation  `json:"Allocation"`
+}
+
+// bulkRepayment is a payment of a buyer applied across its loans, each
+// repayment posted as BulkID_1, BulkID_2 ...
+type bulkRepayment struct {
+	BulkID     string             `json:"BulkID"`
+	TxnType    string             `json:"TxnType"` // repayment, or credit_repayment for unapplied credit
+	TxnDate    time.Time          `json:"TxnDate"`
+	BuyerID    string             `json:"BuyerID"`
+	BankID     string             `json:"BankID"`
+	Amount     money.Amount       `json:"Amount"`
+	Repayments []appliedRepayment `json:"Repayments"`
+	// what is not applied to the loans, held as credit of the buyer by the
+	// txn UnappliedTxnID
+	Unapplied      money.Amount `json:"Unapplied"`
+	UnappliedTxnID string       `json:"UnappliedTxnID,omitempty"`
+	By             string       `json:"By"`
+	PostingDate    time.Time    `json:"PostingDate"`
+}
+
+// loanShare is a loan a bulk repayment goes to, and at most how much of it
+type loanShare struct {
+	loan  common.AccruingLoan
+	limit *money.Amount // as much as the loan owes when nil
+}
+
+func repayBulk(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> BulkID
+	 *args[1] -> TxnDate
+	 *args[2] -> BuyerID
+	 *args[3] -> Amount
+	 *args[4] -> BankID
+	 *args[5] -> By
+	 *args[6] -> Loans, separated by commas, each LoanID or LoanID:Amount (optional: the loans of the buyer by due date)
+	 */
+	if len(args) != 6 && len(args) != 7 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in repayBulk (required:6 or 7) given: "+xLenStr)
+	}
+	currency, err := buyerCurrency(stub, args[2])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	amt, err := money.Parse(args[3], currency)
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+	} else if amt.Sign() <= 0 {
+		return errcode.Error("txncc", errcode.InvalidArgument, "Transaction amount has to be above zero: "+args[3])
+	}
+	loans := ""
+	if len(args) == 7 {
+		loans = args[6]
+	}
+
+	batch := newPostingBatch(stub)
+	bulk, evs, resp := applyBulk(stub, batch, "repayment", args[0], args[1], args[2], args[4], args[5], amt, loans)
+	if resp != nil {
+		return *resp
+	}
+
+	// the rest is held for the buyer
+	if !bulk.Unapplied.IsZero() {
+		txnID := args[0] + "_credit"
+		transaction, mvs, err := postBuyerTxn(stub, batch, []string{txnID, "unapplied_credit", args[1], "", "", bulk.Unapplied.String(), args[2], args[4], args[5]}, bulk.Unapplied)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		}
+		bulk.UnappliedTxnID = txnID
+		evs = append(evs, txnEvents(txnID, transaction, mvs)...)
+	}
+	return putBulk(stub, batch, bulk, evs)
+}
+
+func applyUnappliedCredit(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> TxnID
+	 *args[1] -> TxnDate
+	 *args[2] -> BuyerID
+	 *args[3] -> BankID
+	 *args[4] -> By
+	 *args[5] -> Loans, separated by commas, each LoanID or LoanID:Amount (optional: the loans of the buyer by due date)
+	 */
+	if len(args) != 5 && len(args) != 6 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in applyUnappliedCredit (required:5 or 6) given: "+xLenStr)
+	}
+	currency, err := buyerCurrency(stub, args[2])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	credit, err := getUnapplied(stub, args[2], currency)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if credit.IsZero() {
+		return errcode.Error("txncc", errcode.InvalidState, "Buyer "+args[2]+" has no unapplied credit")
+	}
+	loans := ""
+	if len(args) == 6 {
+		loans = args[5]
+	}
+
+	batch := newPostingBatch(stub)
+	bulk, evs, resp := applyBulk(stub, batch, "credit_repayment", args[0], args[1], args[2], args[3], args[4], credit, loans)
+	if resp != nil {
+		return *resp
+	}
+	return putBulk(stub, batch, bulk, evs)
+}
+
+func refundUnappliedCredit(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> TxnID
+	 *args[1] -> TxnDate
+	 *args[2] -> BuyerID
+	 *args[3] -> Amount
+	 *args[4] -> BankID
+	 *args[5] -> By
+	 */
+	if len(args) != 6 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in refundUnappliedCredit (required:6) given: "+xLenStr)
+	}
+	currency, err := buyerCurrency(stub, args[2])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	amt, err := money.Parse(args[3], currency)
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+	} else if amt.Sign() <= 0 {
+		return errcode.Error("txncc", errcode.InvalidArgument, "Transaction amount has to be above zero: "+args[3])
+	}
+
+	batch := newPostingBatch(stub)
+	transaction, mvs, err := postBuyerTxn(stub, batch, []string{args[0], "unapplied_refund", args[1], "", "", amt.String(), args[4], args[2], args[5]}, amt)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = batch.flush()
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = events.Emit(stub, "transactioncc", txnEvents(args[0], transaction, mvs)...)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte(args[0]))
+}
+
+func getUnappliedCredit(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in getUnappliedCredit (required:1) given: "+xLenStr)
+	}
+	currency, err := buyerCurrency(stub, args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	credit, err := getUnapplied(stub, args[0], currency)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte(credit.String()))
+}
+
+func getBulkRepayment(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in getBulkRepayment (required:1) given: "+xLenStr)
+	}
+	bulkKey, err := stub.CreateCompositeKey(bulkIndex, []string{args[0]})
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	bulkBytes, err := stub.GetState(bulkKey)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if bulkBytes == nil {
+		return errcode.Error("txncc", errcode.NotFound, "No bulk repayment "+args[0])
+	}
+	return shim.Success(bulkBytes)
+}
+
+// applyBulk posts a txn of the type on each of the loans of the buyer, in
+// the order of loans or else by due date, for what the loan owes up to what
+// is left of amt, all in the batch. A failure comes back as the response.
+func applyBulk(stub shim.ChaincodeStubInterface, batch *postingBatch, txnType string, bulkID string, date string, buyerID string, bankID string, by string, amt money.Amount, loans string) (bulkRepayment, []events.Event, *pb.Response) {
+	bulk := bulkRepayment{BulkID: bulkID, TxnType: txnType, BuyerID: buyerID, BankID: bankID, Amount: amt, Repayments: []appliedRepayment{}, By: by}
+	fail := func(code errcode.Code, msg string) (bulkRepayment, []events.Event, *pb.Response) {
+		resp := errcode.Error("txncc", code, msg)
+		return bulk, nil, &resp
+	}
+
+	bulkKey, err := stub.CreateCompositeKey(bulkIndex, []string{bulkID})
+	if err != nil {
+		return fail(errcode.Internal, err.Error())
+	}
+	ifExists, err := stub.GetState(bulkKey)
+	if err != nil {
+		return fail(errcode.Internal, err.Error())
+	} else if ifExists != nil {
+		return fail(errcode.AlreadyExists, "Bulk repayment "+bulkID+" exists. Cannot create new ID")
+	}
+	bulk.TxnDate, err = time.Parse(clock.DateLayout, date)
+	if err != nil {
+		return fail(errcode.InvalidArgument, err.Error())
+	}
+	bulk.PostingDate, err = clock.PostingDate(stub)
+	if err != nil {
+		return fail(errcode.Internal, err.Error())
+	}
+
+	shares, err := loanShares(stub, buyerID, amt, loans)
+	if err != nil {
+		return fail(errcode.Internal, err.Error())
+	}
+
+	evs := []events.Event{}
+	left := amt
+	for _, share := range shares {
+		if left.IsZero() {
+			break
+		}
+		limit := left
+		if share.limit != nil {
+			limit, err = money.Min(left, *share.limit)
+			if err != nil {
+				return fail(errcode.Internal, err.Error())
+			}
+		}
+		// what the waterfall of the loan takes of it
+		ctx := &postingContext{stub: stub, batch: batch, loanID: share.loan.LoanID, amt: limit}
+		err = ctx.allocate()
+		if err != nil {
+			return fail(errcode.Internal, err.Error())
+		}
+		settled, err := ctx.alloc.settled()
+		if err != nil {
+			return fail(errcode.Internal, err.Error())
+		} else if settled.IsZero() {
+			continue
+		}
+
+		txnID := bulkID + "_" + strconv.Itoa(len(bulk.Repayments)+1)
+		response, txnEvs := postTxn(stub, batch, []string{txnID, txnType, date, share.loan.LoanID, share.loan.InstrumentID, settled.String(), buyerID, bankID, by})
+		if response.Status != shim.OK {
+			return bulk, nil, &response
+		}
+		posted := txnResponse{}
+		err = json.Unmarshal(response.Payload, &posted)
+		if err != nil {
+			return fail(errcode.Internal, err.Error())
+		}
+		bulk.Repayments = append(bulk.Repayments, appliedRepayment{txnID, share.loan.LoanID, settled, posted.Allocation})
+		evs = append(evs, txnEvs...)
+
+		left, err = left.Sub(settled)
+		if err != nil {
+			return fail(errcode.Internal, err.Error())
+		}
+	}
+	bulk.Unapplied = left
+	return bulk, evs, nil
+}
+
+// loanShares returns the loans a bulk repayment of the buyer goes to: those
+// named in loans, in that order, or else every loan of the buyer still to be
+// repaid, soonest due first
+func loanShares(stub shim.ChaincodeStubInterface, buyerID string, amt money.Amount, loans string) ([]loanShare, error) {
+	repayable, err := common.NewLoanClient(stub).Repayable(buyerID)
+	if err != nil {
+		return nil, err
+	}
+	shares := []loanShare{}
+	if loans == "" {
+		sort.SliceStable(repayable, func(i, j int) bool { return repayable[i].DueDate.Before(repayable[j].DueDate) })
+		for _, loan := range repayable {
+			shares = append(shares, loanShare{loan: loan})
+		}
+		return shares, nil
+	}
+
+	byID := map[string]common.AccruingLoan{}
+	for _, loan := range repayable {
+		byID[loan.LoanID] = loan
+	}
+	named := map[string]bool{}
+	total := money.Zero(amt.Currency)
+	for _, entry := range strings.Split(loans, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		loan, ok := byID[parts[0]]
+		if !ok {
+			return nil, errcode.New(errcode.InvalidArgument, "Loan "+parts[0]+" is not a loan of buyer "+buyerID+" to be repaid")
+		} else if named[parts[0]] {
+			return nil, errcode.New(errcode.InvalidArgument, "Loan "+parts[0]+" is given twice")
+		}
+		named[parts[0]] = true
+		share := loanShare{loan: loan}
+		if len(parts) == 2 {
+			limit, err := money.Parse(strings.TrimSpace(parts[1]), amt.Currency)
+			if err != nil {
+				return nil, errcode.New(errcode.InvalidArgument, "Amount for loan "+parts[0]+": "+err.Error())
+			} else if limit.Sign() <= 0 {
+				return nil, errcode.New(errcode.InvalidArgument, "Amount for loan "+parts[0]+" has to be above zero")
+			}
+			share.limit = &limit
+			total, err = total.Add(limit)
+			if err != nil {
+				return nil, err
+			}
+		}
+		shares = append(shares, share)
+	}
+	if c, err := total.Cmp(amt); err != nil || c > 0 {
+		return nil, errcode.New(errcode.InvalidArgument, "The loan amounts come to "+total.String()+", more than "+amt.String())
+	}
+	return shares, nil
+}
+
+// putBulk flushes the batch of the bulk repayment, writes it, emits the
+// events of its txns and returns it
+func putBulk(stub shim.ChaincodeStubInterface, batch *postingBatch, bulk bulkRepayment, evs []events.Event) pb.Response {
+	err := batch.flush()
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	bulkKey, err := stub.CreateCompositeKey(bulkIndex, []string{bulk.BulkID})
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	bulkBytes, _ := json.Marshal(bulk)
+	err = stub.PutState(bulkKey, bulkBytes)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "Cannot write into ledger the bulk repayment")
+	}
+	err = events.Emit(stub, "transactioncc", evs...)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	return shim.Success(bulkBytes)
+}
+
+// postBuyerTxn posts a txn between the buyer and the bank that is not on a
+// loan in the batch, args as for postTxn with no loan and instrument
+func postBuyerTxn(stub shim.ChaincodeStubInterface, batch *postingBatch, args []string, amt money.Amount) (transactionInfo, []common.WalletMovement, error) {
+	transaction := transactionInfo{TxnType: args[1], Amt: amt, FromID: args[6], ToID: args[7], By: args[8]}
+	ifExists, err := stub.GetState(args[0])
+	if err != nil {
+		return transaction, nil, err
+	} else if ifExists != nil {
+		return transaction, nil, errcode.New(errcode.AlreadyExists, "TxnID "+args[0]+" exists. Cannot create new ID")
+	}
+	transaction.TxnDate, err = time.Parse(clock.DateLayout, args[2])
+	if err != nil {
+		return transaction, nil, errcode.New(errcode.InvalidArgument, err.Error())
+	}
+	transaction.PostingDate, err = clock.PostingDate(stub)
+	if err != nil {
+		return transaction, nil, err
+	}
+	// the credit is checked before any wallet moves
+	err = trackUnapplied(batch, transaction, false)
+	if err != nil {
+		return transaction, nil, err
+	}
+
+	rule, err := postingRuleFor(stub, args[1])
+	if err != nil {
+		return transaction, nil, err
+	}
+	ids := map[string]string{"bank": args[6], "buyer": args[7]}
+	if rule.BankIs == "to" {
+		ids = map[string]string{"bank": args[7], "buyer": args[6]}
+	}
+	postings, err := preparePostings(stub, rule, &postingContext{stub: stub, batch: batch, amt: amt}, ids)
+	if err != nil {
+		return transaction, nil, err
+	}
+	mvs, err := applyPostings(stub, batch, args, postings)
+	if err != nil {
+		return transaction, nil, err
+	}
+
+	transaction.Legs = legIDs(mvs)
+	txnBytes, _ := json.Marshal(transaction)
+	err = stub.PutState(args[0], txnBytes)
+	if err != nil {
+		return transaction, nil, errcode.New(errcode.Internal, "Cannot write into ledger the transaction details")
+	}
+	return transaction, mvs, nil
+}
+
+// unappliedChange returns the buyer whose unapplied credit the transaction
+// moves, and by how much. What a repayment pays over the waterfall of its
+// loan goes to the credit of the buyer that paid it.
+func unappliedChange(transaction transactionInfo) (string, money.Amount, error) {
+	excess := money.Zero(transaction.Amt.Currency)
+	if transaction.Allocation != nil {
+		excess = transaction.Allocation.Excess
+	}
+	if transaction.TxnType == "unapplied_credit" {
+		return transaction.FromID, transaction.Amt, nil
+	} else if transaction.TxnType == "repayment" && !excess.IsZero() {
+		return transaction.FromID, excess, nil
+	} else if transaction.TxnType == "credit_repayment" {
+		applied, err := transaction.Amt.Sub(excess)
+		return transaction.FromID, applied.Neg(), err
+	} else if transaction.TxnType == "unapplied_refund" {
+		return transaction.ToID, transaction.Amt.Neg(), nil
+	}
+	return "", money.Amount{}, nil
+}
+
+// trackUnapplied moves the unapplied credit of the buyer of the transaction
+// in the batch, back when it is reversed. The credit cannot go below zero.
+func trackUnapplied(batch *postingBatch, transaction transactionInfo, reverse bool) error {
+	buyerID, change, err := unappliedChange(transaction)
+	if err != nil || buyerID == "" {
+		return err
+	}
+	if reverse {
+		change = change.Neg()
+	}
+	creditKey, err := batch.stub.CreateCompositeKey(unappliedIndex, []string{buyerID})
+	if err != nil {
+		return err
+	}
+	held, err := batch.amount(creditKey, change.Currency)
+	if err != nil {
+		return err
+	}
+	credit, err := held.Add(change)
+	if err != nil {
+		return err
+	}
+	if credit.IsNegative() {
+		return errcode.New(errcode.InsufficientFunds, "Buyer "+buyerID+" has "+held.String()+" of unapplied credit")
+	}
+	batch.putAmount(creditKey, credit)
+	return nil
+}
+
+// getUnapplied returns the unapplied credit of the buyer
+func getUnapplied(stub shim.ChaincodeStubInterface, buyerID string, currency string) (money.Amount, error) {
+	creditKey, err := stub.CreateCompositeKey(unappliedIndex, []string{buyerID})
+	if err != nil {
+		return money.Amount{}, err
+	}
+	creditBytes, err := stub.GetState(creditKey)
+	if err != nil {
+		return money.Amount{}, err
+	} else if creditBytes == nil {
+		return money.Zero(currency), nil
+	}
+	credit := money.Amount{}
+	err = json.Unmarshal(creditBytes, &credit)
+	return credit, err
+}
+
+// buyerCurrency is the currency of the buyer's main wallet, which its
+// payments are made in
+func buyerCurrency(stub shim.ChaincodeStubInterface, buyerID string) (string, error) {
+	walletID, err := common.NewBusinessClient(stub).WalletID(buyerID, "main")
+	if err != nil {
+		return "", err
+	}
+	return common.NewWalletClient(stub).Currency(walletID)
+}
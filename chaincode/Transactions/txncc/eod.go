@@ -0,0 +1,337 @@
+package txncc
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	clock "github.com/chaincode/Clock"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	events "github.com/chaincode/Events"
+	money "github.com/chaincode/Money"
+	query "github.com/chaincode/Query"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// The EOD run closes a business date for every loan that earns interest. It
+// accrues the interest of the loan, marks the loan and its instrument overdue
+// once its due date has passed, and posts penal interest on the overdue loan
+// from its due date as a penal_charges txn. The run goes through the loans in
+// loan ID order a chunk at a time, one transaction each, so that no
+// transaction goes over its limits. The report of the run is kept under the
+// business date with the last loan done, and calling runEOD again for the
+// date goes on from there. A completed run is returned as it is.
+
+// eodRunIndex is the composite key prefix of the report of the EOD run of
+// each business date
+const eodRunIndex = "EODRun~BusinessDate"
+
+// eodLoanIndex is the composite key prefix of what the EOD run of a business
+// date did with each loan
+const eodLoanIndex = "EODLoan~BusinessDate~LoanID"
+
+// penalIndex is the composite key prefix of the last business date each loan
+// was charged penal interest for
+const penalIndex = "Penal~LoanID"
+
+// EOD run statuses
+const (
+	eodRunning   = "running"
+	eodCompleted = "completed"
+)
+
+// eodRun is the report of the EOD run of a business date. The day-count
+// convention and the penal rate are those when the run started.
+type eodRun struct {
+	BusinessDate  time.Time  `json:"businessDate"`
+	DayCount      string     `json:"dayCount"`
+	PenalRate     money.Rate `json:"penalRate"`
+	Status        string     `json:"status"`
+	Cursor        string     `json:"cursor"` // the last loan done
+	Chunks        int        `json:"chunks"`
+	LoansDone     int        `json:"loansDone"`
+	MarkedOverdue int        `json:"markedOverdue"`
+	Loans         []eodLoan  `json:"loans"` // those of the chunk, or of the whole run for getEODReport
+}
+
+// eodLoan is what the EOD run did with a loan
+type eodLoan struct {
+	LoanID        string       `json:"loanID"`
+	Accrual       loanAccrual  `json:"accrual"`
+	MarkedOverdue bool         `json:"markedOverdue,omitempty"`
+	Penal         *loanAccrual `json:"penal,omitempty"` // nil while the loan is not past due
+}
+
+func runEOD(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> Business date to close
+	 *args[1] -> BankID, of the accrual and penal_charges txns
+	 *args[2] -> By
+	 *args[3] -> Number of loans to go through in this call (optional)
+	 */
+	if len(args) != 3 && len(args) != 4 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in runEOD (required:3 or 4) given: "+xLenStr)
+	}
+	date, err := time.Parse(clock.DateLayout, args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+	}
+	today, err := clock.PostingDate(stub)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if date.After(today) {
+		return errcode.Error("txncc", errcode.InvalidArgument, "Cannot run EOD for "+args[0]+", a date after today "+today.Format(clock.DateLayout))
+	}
+	chunkSize := query.DefaultPageSize
+	if len(args) == 4 {
+		chunkSize, err = query.PageSize(args[3])
+		if err != nil {
+			return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+		}
+	}
+
+	run, err := getEODRun(stub, date)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if run == nil {
+		run = &eodRun{BusinessDate: date, Status: eodRunning}
+		dayCount, err := getDayCountSetting(stub)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		}
+		run.DayCount = string(dayCount)
+		run.PenalRate, err = getPenalRateSetting(stub)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		}
+	}
+	run.Loans = []eodLoan{}
+	if run.Status == eodCompleted {
+		runBytes, _ := json.Marshal(run)
+		return shim.Success(runBytes)
+	}
+
+	loans, err := common.NewLoanClient(stub).Accruing()
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	// the cursor is the last loan ID done, so the loans have to be gone
+	// through in loan ID order whatever order they are listed in
+	sort.Slice(loans, func(i, j int) bool { return loans[i].LoanID < loans[j].LoanID })
+	pending := []common.AccruingLoan{}
+	for _, loan := range loans {
+		if loan.LoanID > run.Cursor {
+			pending = append(pending, loan)
+		}
+	}
+	if len(pending) <= chunkSize {
+		run.Status = eodCompleted
+	} else {
+		pending = pending[:chunkSize]
+	}
+
+	batch := newPostingBatch(stub)
+	evs := []events.Event{}
+	for _, loan := range pending {
+		done, loanEvs, err := closeLoanDay(stub, batch, run, loan, args[1], args[2])
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, "Loan "+loan.LoanID+": "+err.Error())
+		}
+		err = putEODLoan(stub, date, done)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		}
+		run.Loans = append(run.Loans, done)
+		run.Cursor = loan.LoanID
+		run.LoansDone++
+		if done.MarkedOverdue {
+			run.MarkedOverdue++
+		}
+		evs = append(evs, loanEvs...)
+	}
+	run.Chunks++
+
+	err = batch.flush()
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = putEODRun(stub, *run)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "cannot write the EOD report: "+err.Error())
+	}
+	err = events.Emit(stub, "transactioncc", evs...)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	runBytes, _ := json.Marshal(run)
+	return shim.Success(runBytes)
+}
+
+// closeLoanDay accrues the interest of the loan for the business date of the
+// run and, once the loan is past due, marks it overdue and charges it penal
+// interest, posting in the batch. It returns the events of what it did.
+func closeLoanDay(stub shim.ChaincodeStubInterface, batch *postingBatch, run *eodRun, loan common.AccruingLoan, bankID string, by string) (eodLoan, []events.Event, error) {
+	done := eodLoan{LoanID: loan.LoanID}
+	dayCount := money.DayCount(run.DayCount)
+	accrual, evs, err := accrueLoan(stub, batch, loan, run.BusinessDate, dayCount, bankID, by)
+	if err != nil {
+		return done, nil, err
+	}
+	done.Accrual = accrual
+
+	dueDate := time.Date(loan.DueDate.Year(), loan.DueDate.Month(), loan.DueDate.Day(), 0, 0, 0, 0, time.UTC)
+	if !run.BusinessDate.After(dueDate) {
+		return done, evs, nil
+	}
+	if loan.Status != "overdue" {
+		err = common.NewLoanClient(stub).MarkOverdue(loan.LoanID, "due on "+dueDate.Format(clock.DateLayout)+", unpaid at EOD of "+run.BusinessDate.Format(clock.DateLayout))
+		if err != nil {
+			return done, nil, err
+		}
+		done.MarkedOverdue = true
+		evs = append(evs,
+			events.New(events.LoanStatusChanged, events.StatusChange{ID: loan.LoanID, From: loan.Status, To: "overdue"}),
+			events.New(events.InstrumentStatusChanged, events.StatusChange{ID: loan.InstrumentID, SellerID: loan.SellerID, From: "disbursed", To: "overdue"}))
+	}
+
+	if run.PenalRate.IsZero() {
+		done.Penal = &loanAccrual{LoanID: loan.LoanID, Skipped: "no penal rate put"}
+		return done, evs, nil
+	}
+	penal, penalEvs, err := postInterest(stub, batch, loan, interestPosting{"penal_charges", penalIndex, dueDate, run.PenalRate, bankID, loan.SellerID}, run.BusinessDate, dayCount, by)
+	if err != nil {
+		return done, nil, err
+	}
+	done.Penal = &penal
+	return done, append(evs, penalEvs...), nil
+}
+
+func getEODReport(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> Business date
+	 */
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in getEODReport (required:1) given: "+xLenStr)
+	}
+	date, err := time.Parse(clock.DateLayout, args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+	}
+	run, err := getEODRun(stub, date)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if run == nil {
+		return errcode.Error("txncc", errcode.NotFound, "No EOD run exists for "+args[0])
+	}
+
+	loansIterator, err := stub.GetStateByPartialCompositeKey(eodLoanIndex, []string{date.Format("20060102")})
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	defer loansIterator.Close()
+	run.Loans = []eodLoan{}
+	for loansIterator.HasNext() {
+		kv, err := loansIterator.Next()
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		}
+		done := eodLoan{}
+		err = json.Unmarshal(kv.Value, &done)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		}
+		run.Loans = append(run.Loans, done)
+	}
+	runBytes, _ := json.Marshal(run)
+	return shim.Success(runBytes)
+}
+
+// getEODRun returns the report of the EOD run of the business date, or nil
+// if there was none
+func getEODRun(stub shim.ChaincodeStubInterface, date time.Time) (*eodRun, error) {
+	runKey, err := stub.CreateCompositeKey(eodRunIndex, []string{date.Format("20060102")})
+	if err != nil {
+		return nil, err
+	}
+	runBytes, err := stub.GetState(runKey)
+	if err != nil || runBytes == nil {
+		return nil, err
+	}
+	run := &eodRun{}
+	err = json.Unmarshal(runBytes, run)
+	return run, err
+}
+
+// putEODRun writes the report of the run, without the loans of the chunk
+func putEODRun(stub shim.ChaincodeStubInterface, run eodRun) error {
+	runKey, err := stub.CreateCompositeKey(eodRunIndex, []string{run.BusinessDate.Format("20060102")})
+	if err != nil {
+		return err
+	}
+	run.Loans = nil
+	runBytes, _ := json.Marshal(run)
+	return stub.PutState(runKey, runBytes)
+}
+
+func putEODLoan(stub shim.ChaincodeStubInterface, date time.Time, done eodLoan) error {
+	loanKey, err := stub.CreateCompositeKey(eodLoanIndex, []string{date.Format("20060102"), done.LoanID})
+	if err != nil {
+		return err
+	}
+	doneBytes, _ := json.Marshal(done)
+	return stub.PutState(loanKey, doneBytes)
+}
+
+func putPenalRate(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> Penal interest rate, % a year, charged on overdue loans
+	 */
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in putPenalRate (required:1) given: "+xLenStr)
+	}
+	rate, err := money.ParseRate(args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+	} else if rate.IsNegative() {
+		return errcode.Error("txncc", errcode.InvalidArgument, "Penal rate cannot be negative: "+args[0])
+	}
+	settingKey, err := stub.CreateCompositeKey(settingIndex, []string{"penalRate"})
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = stub.PutState(settingKey, []byte(rate.String()))
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "cannot write penal rate: "+err.Error())
+	}
+	return shim.Success([]byte(rate.String()))
+}
+
+func getPenalRate(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	rate, err := getPenalRateSetting(stub)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte(rate.String()))
+}
+
+// getPenalRateSetting returns the penal rate put, or else zero, which charges
+// no penal interest
+func getPenalRateSetting(stub shim.ChaincodeStubInterface) (money.Rate, error) {
+	settingKey, err := stub.CreateCompositeKey(settingIndex, []string{"penalRate"})
+	if err != nil {
+		return money.Rate{}, err
+	}
+	rateBytes, err := stub.GetState(settingKey)
+	if err != nil || rateBytes == nil {
+		return money.Rate{}, err
+	}
+	return money.ParseRate(string(rateBytes))
+}
@@ -0,0 +1,673 @@
+package txncc
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	money "github.com/chaincode/Money"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// postingRuleIndex is the composite key prefix of posting rules stored on the
+// ledger. A stored rule takes the place of the built-in rule for its txn type.
+const postingRuleIndex = "PostingRule~TxnType"
+
+// postingLeg is one wallet movement of a transaction
+type postingLeg struct {
+	Role       string `json:"role"`       // bank, seller, buyer or loan
+	WalletType string `json:"walletType"` // main, asset, loan, disbursed ...
+	Side       string `json:"side"`       // debit or credit
+	Amount     string `json:"amount"`     // one of the names in postingFormulas
+}
+
+// postingRule lists the wallet legs posted for a txn type
+type postingRule struct {
+	TxnType string       `json:"txnType"`
+	BankIs  string       `json:"bankIs"` // from (args[6]) or to (args[7])
+	Legs    []postingLeg `json:"legs"`
+}
+
+// walletClass says which way a wallet moves and which debit = credit check it
+// is part of. Debits increase asset wallets and credits increase the others.
+// Memo wallets mirror what the loans owe: the loan wallets on one side and the
+// seller's principalOut and chargesOut on the other, so their debits have to
+// equal their credits apart from the on-book ones. The remaining wallets keep
+// running totals (income on the loans, what the bank holds for its customers,
+// tax deducted) and are not balanced.
+type walletClass struct {
+	Asset  bool
+	OnBook bool
+	Memo   bool
+}
+
+// walletClasses holds the class of every wallet type of bankcc, businesscc
+// and loancc
+var walletClasses = map[string]map[string]walletClass{
+	"bankcc": {
+		"main":      {Asset: true, OnBook: true},
+		"asset":     {Asset: true, OnBook: true},
+		"charges":   {Asset: false},
+		"liability": {Asset: false},
+		"tds":       {Asset: true},
+	},
+	"businesscc": {
+		"main":         {Asset: true, OnBook: true},
+		"loan":         {Asset: false, OnBook: true},
+		"liability":    {Asset: false},
+		"principalOut": {Asset: false, Memo: true},
+		"chargesOut":   {Asset: false, Memo: true},
+	},
+	"loancc": {
+		"disbursed": {Asset: true, Memo: true},
+		"charges":   {Asset: true, Memo: true},
+		"accrued":   {Asset: true, Memo: true},
+	},
+}
+
+// roleChaincodes maps a participant role to the chaincode holding its wallets
+var roleChaincodes = map[string]string{
+	"bank":   "bankcc",
+	"seller": "businesscc",
+	"buyer":  "businesscc",
+	"loan":   "loancc",
+}
+
+// postingContext is what the amount formulas of a transaction work on. The
+// loan balances are read through the batch the first time a formula needs
+// them.
+type postingContext struct {
+	stub        shim.ChaincodeStubInterface
+	batch       *postingBatch
+	loanID      string
+	amt         money.Amount
+	loaded      bool
+	disbursed   money.Amount
+	charges     money.Amount
+	accrued     money.Amount
+	undisbursed money.Amount
+	// how a repayment is appropriated, and whether it pays every component
+	// of the waterfall in full
+	alloc   *allocation
+	cleared bool
+	// the loan status before and after the transaction, when it changed
+	prevStatus string
+	newStatus  string
+}
+
+// postingFormulas are the amounts a leg can be posted for
+var postingFormulas = map[string]func(ctx *postingContext) (money.Amount, error){
+	// the transaction amount
+	"amount": func(ctx *postingContext) (money.Amount, error) {
+		return ctx.amt, nil
+	},
+	// the part of a repayment that goes to the loan, as the program's waterfall
+	// appropriates it
+	"settled": func(ctx *postingContext) (money.Amount, error) {
+		err := ctx.allocate()
+		if err != nil {
+			return money.Amount{}, err
+		}
+		return ctx.alloc.settled()
+	},
+	// the part of the settled amount that goes to the loan charges, penal
+	// interest included
+	"chargesPaid": func(ctx *postingContext) (money.Amount, error) {
+		err := ctx.allocate()
+		if err != nil {
+			return money.Amount{}, err
+		}
+		return ctx.alloc.chargesPaid()
+	},
+	// the part of the settled amount that goes to the penal interest
+	"penalPaid": func(ctx *postingContext) (money.Amount, error) {
+		err := ctx.allocate()
+		if err != nil {
+			return money.Amount{}, err
+		}
+		return ctx.alloc.Penal, nil
+	},
+	// the part of the settled amount that goes to the accrued interest
+	"interestPaid": func(ctx *postingContext) (money.Amount, error) {
+		err := ctx.allocate()
+		if err != nil {
+			return money.Amount{}, err
+		}
+		return ctx.alloc.Interest, nil
+	},
+	// the part of the settled amount that goes to the principal
+	"principalPaid": func(ctx *postingContext) (money.Amount, error) {
+		err := ctx.allocate()
+		if err != nil {
+			return money.Amount{}, err
+		}
+		return ctx.alloc.Principal, nil
+	},
+	// what is left of a repayment after the waterfall is paid
+	"excess": func(ctx *postingContext) (money.Amount, error) {
+		err := ctx.allocate()
+		if err != nil {
+			return money.Amount{}, err
+		}
+		return ctx.alloc.Excess, nil
+	},
+}
+
+// loadLoan reads the loan disbursed, charges and accrued wallets
+func (ctx *postingContext) loadLoan() error {
+	if ctx.loaded {
+		return nil
+	}
+	var err error
+	ctx.disbursed, err = getLoanWalletValue(ctx.batch, ctx.loanID, "disbursed")
+	if err != nil {
+		return err
+	}
+	ctx.charges, err = getLoanWalletValue(ctx.batch, ctx.loanID, "charges")
+	if err != nil {
+		return err
+	}
+	ctx.accrued, err = getLoanWalletValue(ctx.batch, ctx.loanID, "accrued")
+	if err != nil {
+		return err
+	}
+	ctx.loaded = true
+	return nil
+}
+
+// builtinPostingRules are the rules used when none is stored on the ledger
+var builtinPostingRules = map[string]postingRule{
+	"disbursement": {"disbursement", "from", []postingLeg{
+		{"bank", "main", "credit", "amount"},
+		{"seller", "main", "debit", "amount"},
+		{"bank", "asset", "debit", "amount"},
+		{"seller", "loan", "credit", "amount"},
+		{"loan", "disbursed", "debit", "amount"},
+		{"seller", "principalOut", "credit", "amount"},
+	}},
+	"repayment": {"repayment", "to", []postingLeg{
+		{"buyer", "main", "credit", "amount"},
+		{"bank", "main", "debit", "amount"},
+		{"bank", "asset", "credit", "principalPaid"},
+		{"bank", "liability", "credit", "excess"},
+		{"seller", "loan", "debit", "principalPaid"},
+		{"seller", "chargesOut", "debit", "chargesPaid"},
+		{"seller", "chargesOut", "debit", "interestPaid"},
+		{"seller", "principalOut", "debit", "principalPaid"},
+		{"loan", "charges", "credit", "chargesPaid"},
+		{"loan", "accrued", "credit", "interestPaid"},
+		{"bank", "charges", "credit", "interestPaid"},
+		{"loan", "disbursed", "credit", "principalPaid"},
+		{"buyer", "liability", "debit", "settled"},
+	}},
+	// a repayment out of the unapplied credit of the buyer, which the bank
+	// already holds
+	"credit_repayment": {"credit_repayment", "to", []postingLeg{
+		{"bank", "liability", "debit", "amount"},
+		{"bank", "asset", "credit", "principalPaid"},
+		{"bank", "liability", "credit", "excess"},
+		{"seller", "loan", "debit", "principalPaid"},
+		{"seller", "chargesOut", "debit", "chargesPaid"},
+		{"seller", "chargesOut", "debit", "interestPaid"},
+		{"seller", "principalOut", "debit", "principalPaid"},
+		{"loan", "charges", "credit", "chargesPaid"},
+		{"loan", "accrued", "credit", "interestPaid"},
+		{"bank", "charges", "credit", "interestPaid"},
+		{"loan", "disbursed", "credit", "principalPaid"},
+		{"buyer", "liability", "debit", "settled"},
+	}},
+	// a buyer payment held by the bank as unapplied credit, and paid back
+	"unapplied_credit": {"unapplied_credit", "to", []postingLeg{
+		{"buyer", "main", "credit", "amount"},
+		{"bank", "main", "debit", "amount"},
+		{"bank", "liability", "credit", "amount"},
+	}},
+	"unapplied_refund": {"unapplied_refund", "from", []postingLeg{
+		{"bank", "main", "credit", "amount"},
+		{"buyer", "main", "debit", "amount"},
+		{"bank", "liability", "debit", "amount"},
+	}},
+	"margin_refund": {"margin_refund", "from", []postingLeg{
+		{"bank", "main", "credit", "amount"},
+		{"seller", "main", "debit", "amount"},
+		{"bank", "liability", "debit", "amount"},
+	}},
+	"interest_refund": {"interest_refund", "from", []postingLeg{
+		{"bank", "main", "credit", "amount"},
+		{"seller", "main", "debit", "amount"},
+		{"bank", "liability", "debit", "amount"},
+		{"bank", "charges", "debit", "amount"},
+	}},
+	"penal_interest_collection": {"penal_interest_collection", "from", []postingLeg{
+		{"seller", "main", "credit", "amount"},
+		{"bank", "main", "debit", "amount"},
+		{"seller", "chargesOut", "debit", "amount"},
+		{"loan", "charges", "credit", "amount"},
+	}},
+	"charges": {"charges", "from", []postingLeg{
+		{"bank", "charges", "credit", "amount"},
+		{"seller", "chargesOut", "credit", "amount"},
+		{"loan", "charges", "debit", "amount"},
+	}},
+	"penal_charges": {"penal_charges", "from", []postingLeg{
+		{"bank", "charges", "credit", "amount"},
+		{"seller", "chargesOut", "credit", "amount"},
+		{"loan", "charges", "debit", "amount"},
+	}},
+	"interest_in_advance": {"interest_in_advance", "from", []postingLeg{
+		{"seller", "loan", "credit", "amount"},
+		{"loan", "disbursed", "debit", "amount"},
+		{"seller", "chargesOut", "debit", "amount"},
+		{"loan", "charges", "credit", "amount"},
+		{"seller", "principalOut", "credit", "amount"},
+		{"bank", "asset", "debit", "amount"},
+	}},
+	// accrued interest is owed by the seller along with the charges
+	"accrual": {"accrual", "from", []postingLeg{
+		{"loan", "accrued", "debit", "amount"},
+		{"seller", "chargesOut", "credit", "amount"},
+	}},
+	"interest_accrued_charges": {"interest_accrued_charges", "from", []postingLeg{
+		{"loan", "charges", "debit", "amount"},
+		{"loan", "accrued", "credit", "amount"},
+		{"bank", "charges", "credit", "amount"},
+	}},
+	"tds": {"tds", "from", []postingLeg{
+		{"seller", "loan", "debit", "amount"},
+		{"buyer", "liability", "debit", "amount"},
+		{"bank", "asset", "credit", "amount"},
+		{"bank", "tds", "debit", "amount"},
+	}},
+}
+
+// postingChecks are run before the legs of their txn type are posted
+var postingChecks = map[string]func(ctx *postingContext) error{
+	"disbursement":    checkDisbursement,
+	"margin_refund":   checkLoanSettled,
+	"interest_refund": checkLoanSettled,
+}
+
+// postingUpdates are run after the legs of their txn type are posted
+var postingUpdates = map[string]func(ctx *postingContext) error{
+	"disbursement":              updateDisbursedLoan,
+	"repayment":                 updateRepaidLoan,
+	"credit_repayment":          updateRepaidLoan,
+	"penal_charges":             chargePenal,
+	"penal_interest_collection": collectPenal,
+}
+
+// checkDisbursement allows disbursing a sanctioned or part disbursed loan up
+// to what is left of the sanctioned amount
+func checkDisbursement(ctx *postingContext) error {
+	loan := common.NewLoanClient(ctx.stub)
+	status, err := loan.Status(ctx.loanID)
+	if err != nil {
+		return err
+	}
+	ctx.prevStatus = status
+	if (status != "sanctioned") && (status != "part_disbursed") {
+		return errcode.New(errcode.InvalidState, "loan status for loanID "+ctx.loanID+" is not sanctioned / part disbursed")
+	}
+
+	sancAmtStr, err := loan.SanctionAmt(ctx.loanID)
+	if err != nil {
+		return err
+	}
+	sancAmt, err := money.Parse(sancAmtStr, ctx.amt.Currency)
+	if err != nil {
+		return err
+	}
+	err = ctx.loadLoan()
+	if err != nil {
+		return err
+	}
+	ctx.undisbursed, err = sancAmt.Sub(ctx.disbursed)
+	if err != nil {
+		return err
+	}
+	ctx.undisbursed, err = ctx.undisbursed.Sub(ctx.amt)
+	if err != nil {
+		return err
+	}
+	if ctx.undisbursed.IsNegative() {
+		return errcode.New(errcode.InvalidArgument, "Amount is greater than Amount to be disbursed")
+	}
+	return nil
+}
+
+// checkLoanSettled allows refunds only once nothing is left on the loan
+func checkLoanSettled(ctx *postingContext) error {
+	for _, walletType := range []string{"disbursed", "charges", "accrued"} {
+		bal, err := getLoanWalletValue(ctx.batch, ctx.loanID, walletType)
+		if err != nil {
+			return err
+		}
+		if !bal.IsZero() {
+			return errcode.New(errcode.InvalidState, "Loan "+ctx.loanID+" is not settled, loan "+walletType+" wallet holds "+bal.String())
+		}
+	}
+	return nil
+}
+
+func updateDisbursedLoan(ctx *postingContext) error {
+	status := "part_disbursed"
+	if ctx.undisbursed.IsZero() {
+		status = "disbursed"
+	}
+	err := common.NewLoanClient(ctx.stub).Update(ctx.loanID, status, "disbursement")
+	if err != nil {
+		return err
+	}
+	ctx.newStatus = status
+	return nil
+}
+
+// updateRepaidLoan collects the loan once the repayment pays all of its
+// waterfall, and takes the penal interest paid off what is due
+func updateRepaidLoan(ctx *postingContext) error {
+	err := ctx.allocate()
+	if err != nil {
+		return err
+	}
+	if !ctx.alloc.Penal.IsZero() {
+		err = addPenalDue(ctx.batch, ctx.loanID, ctx.alloc.Penal.Neg())
+		if err != nil {
+			return err
+		}
+	}
+	loan := common.NewLoanClient(ctx.stub)
+	ctx.prevStatus, err = loan.Status(ctx.loanID)
+	if err != nil {
+		return err
+	}
+
+	status := "part_collected"
+	if ctx.cleared {
+		status = "collected"
+	}
+	err = loan.Update(ctx.loanID, status, "repayment")
+	if err != nil {
+		return err
+	}
+	ctx.newStatus = status
+	return nil
+}
+
+// posting is a leg with its wallet and amount worked out
+type posting struct {
+	leg      postingLeg
+	walletID string
+	amt      money.Amount
+	increase bool
+}
+
+func putPostingRule(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> TxnType
+	 *args[1] -> Rule JSON {"bankIs":"from","legs":[{"role":"bank","walletType":"main","side":"credit","amount":"amount"} ...]}
+	 */
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in putPostingRule (required:2) given: "+xLenStr)
+	}
+
+	rule := postingRule{}
+	err := json.Unmarshal([]byte(args[1]), &rule)
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, "cannot read posting rule: "+err.Error())
+	}
+	rule.TxnType = strings.ToLower(args[0])
+	err = validatePostingRule(rule)
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+	}
+
+	ruleKey, err := stub.CreateCompositeKey(postingRuleIndex, []string{rule.TxnType})
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	ruleBytes, _ := json.Marshal(rule)
+	err = stub.PutState(ruleKey, ruleBytes)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "cannot write posting rule: "+err.Error())
+	}
+	return shim.Success(ruleBytes)
+}
+
+func getPostingRule(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in getPostingRule (required:1) given: "+xLenStr)
+	}
+	rule, err := postingRuleFor(stub, strings.ToLower(args[0]))
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	ruleBytes, _ := json.Marshal(rule)
+	return shim.Success(ruleBytes)
+}
+
+// postingRuleFor returns the rule stored for the txn type, or else the
+// built-in one
+func postingRuleFor(stub shim.ChaincodeStubInterface, txnType string) (postingRule, error) {
+	rule := postingRule{}
+	ruleKey, err := stub.CreateCompositeKey(postingRuleIndex, []string{txnType})
+	if err != nil {
+		return rule, err
+	}
+	ruleBytes, err := stub.GetState(ruleKey)
+	if err != nil {
+		return rule, err
+	} else if ruleBytes != nil {
+		err = json.Unmarshal(ruleBytes, &rule)
+		return rule, err
+	}
+	rule, ok := builtinPostingRules[txnType]
+	if !ok {
+		return rule, errcode.New(errcode.InvalidArgument, "Invalid transaction type "+txnType)
+	}
+	return rule, nil
+}
+
+func validatePostingRule(rule postingRule) error {
+	if rule.TxnType == "" {
+		return errcode.New(errcode.InvalidArgument, "posting rule has no txn type")
+	} else if rule.TxnType == reversalTxnType {
+		return errcode.New(errcode.InvalidArgument, reversalTxnType+" is posted by reverseTxn and cannot have a posting rule")
+	}
+	if rule.BankIs != "from" && rule.BankIs != "to" {
+		return errcode.New(errcode.InvalidArgument, "bankIs has to be from or to, given: "+rule.BankIs)
+	}
+	if len(rule.Legs) == 0 {
+		return errcode.New(errcode.InvalidArgument, "posting rule for "+rule.TxnType+" has no legs")
+	}
+	for i, leg := range rule.Legs {
+		legNo := strconv.Itoa(i + 1)
+		ccName, ok := roleChaincodes[leg.Role]
+		if !ok {
+			return errcode.New(errcode.InvalidArgument, "leg "+legNo+": unknown role "+leg.Role)
+		}
+		if _, ok := walletClasses[ccName][leg.WalletType]; !ok {
+			return errcode.New(errcode.InvalidArgument, "leg "+legNo+": "+leg.Role+" has no "+leg.WalletType+" wallet")
+		}
+		if leg.Side != "debit" && leg.Side != "credit" {
+			return errcode.New(errcode.InvalidArgument, "leg "+legNo+": side has to be debit or credit, given: "+leg.Side)
+		}
+		if _, ok := postingFormulas[leg.Amount]; !ok {
+			return errcode.New(errcode.InvalidArgument, "leg "+legNo+": unknown amount "+leg.Amount)
+		}
+	}
+	return nil
+}
+
+// preparePostings works out the wallet and amount of every leg of the rule
+// and checks that the debits equal the credits, on the books and across the
+// memo wallets. Legs that come to zero are dropped.
+func preparePostings(stub shim.ChaincodeStubInterface, rule postingRule, ctx *postingContext, ids map[string]string) ([]posting, error) {
+
+	postings := []posting{}
+	debits := money.Zero(ctx.amt.Currency)
+	credits := money.Zero(ctx.amt.Currency)
+	memoDebits := money.Zero(ctx.amt.Currency)
+	memoCredits := money.Zero(ctx.amt.Currency)
+
+	for i, leg := range rule.Legs {
+		legNo := strconv.Itoa(i + 1)
+		amt, err := postingFormulas[leg.Amount](ctx)
+		if err != nil {
+			return nil, errors.New("leg " + legNo + ": " + err.Error())
+		}
+		if amt.IsZero() {
+			continue
+		} else if amt.IsNegative() {
+			return nil, errors.New("leg " + legNo + ": negative amount " + amt.String())
+		}
+
+		ccName := roleChaincodes[leg.Role]
+		class := walletClasses[ccName][leg.WalletType]
+		walletID, err := getWalletID(stub, ccName, ids[leg.Role], leg.WalletType)
+		if err != nil {
+			return nil, errors.New("leg " + legNo + ": " + leg.Role + " " + leg.WalletType + " wallet: " + err.Error())
+		}
+
+		if class.OnBook {
+			if leg.Side == "debit" {
+				debits, err = debits.Add(amt)
+			} else {
+				credits, err = credits.Add(amt)
+			}
+			if err != nil {
+				return nil, err
+			}
+		} else if class.Memo {
+			if leg.Side == "debit" {
+				memoDebits, err = memoDebits.Add(amt)
+			} else {
+				memoCredits, err = memoCredits.Add(amt)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		postings = append(postings, posting{leg, walletID, amt, class.Asset == (leg.Side == "debit")})
+	}
+
+	if c, err := debits.Cmp(credits); err != nil || c != 0 {
+		return nil, errors.New("debits " + debits.String() + " do not equal credits " + credits.String() + " for " + rule.TxnType)
+	}
+	if c, err := memoDebits.Cmp(memoCredits); err != nil || c != 0 {
+		return nil, errors.New("memo debits " + memoDebits.String() + " do not equal memo credits " + memoCredits.String() + " for " + rule.TxnType)
+	}
+	return postings, nil
+}
+
+// txnFxRate is the FX rate a txn names for the wallets it moves in another
+// currency than its own, read the first time a leg needs it
+type txnFxRate struct {
+	id   string
+	rate *common.FxRate
+	used bool
+}
+
+// convert returns the amount in the currency of the wallet, by the rate when
+// it is another one
+func (r *txnFxRate) convert(stub shim.ChaincodeStubInterface, amt money.Amount, walletID string, currency string) (money.Amount, error) {
+	if amt.Currency == currency {
+		return amt, nil
+	} else if r.id == "" {
+		return money.Amount{}, errcode.New(errcode.InvalidArgument, "Cannot move "+amt.Currency+" in "+currency+" WalletId "+walletID+" without an FX rate")
+	}
+	if r.rate == nil {
+		fx, err := common.NewWalletClient(stub).FxRate(r.id)
+		if err != nil {
+			return money.Amount{}, err
+		}
+		r.rate = &fx
+	}
+	if r.rate.FromCurrency != amt.Currency || r.rate.ToCurrency != currency {
+		return money.Amount{}, errcode.New(errcode.InvalidArgument, "FX rate "+r.id+" is for "+r.rate.FromCurrency+" to "+r.rate.ToCurrency+", not "+amt.Currency+" to "+currency)
+	}
+	r.used = true
+	return amt.Convert(currency, r.rate.Rate, money.HalfUp)
+}
+
+// applyPostings moves every wallet in the batch, writes a
+// txn_balance_object for each leg under txnID_<leg no> and returns the
+// wallet movements, whose TxnIDs are those leg IDs. A leg of a wallet in
+// another currency than the txn is converted by the FX rate of the txn.
+func applyPostings(stub shim.ChaincodeStubInterface, batch *postingBatch, args []string, postings []posting) ([]common.WalletMovement, error) {
+
+	mvs := []common.WalletMovement{}
+	fx := &txnFxRate{}
+	if len(args) == 11 {
+		fx.id = args[10]
+	}
+
+	for i, p := range postings {
+		legID := args[0] + "_" + strconv.Itoa(i+1)
+		function := "debit"
+		if p.increase {
+			function = "credit"
+		}
+		bal, err := batch.balance(p.walletID)
+		if err != nil {
+			return nil, err
+		}
+		amt, err := fx.convert(stub, p.amt, p.walletID, bal.Currency)
+		if err != nil {
+			return nil, errors.New(p.leg.Role + " " + p.leg.WalletType + " wallet: " + err.Error())
+		}
+		// the txnbalcc record has the txn amount in the currency of the wallet
+		txnAmt, fxRateID := args[5], ""
+		if amt.Currency != p.amt.Currency {
+			parsed, err := money.Parse(args[5], p.amt.Currency)
+			if err != nil {
+				return nil, err
+			}
+			converted, err := fx.convert(stub, parsed, p.walletID, bal.Currency)
+			if err != nil {
+				return nil, err
+			}
+			txnAmt, fxRateID = converted.String(), fx.id
+		}
+		mv, err := batch.move(function, p.walletID, amt, legID, fxRateID)
+		if err != nil {
+			return nil, errors.New(p.leg.Role + " " + p.leg.WalletType + " wallet: " + err.Error())
+		}
+		err = putTxnBal(stub, []string{legID, args[0], args[2], args[3], args[4], p.walletID, mv.OpeningBal.String(), args[1], txnAmt, mv.CAmt.String(), mv.DAmt.String(), mv.ClosingBal.String(), args[8]})
+		if err != nil {
+			return nil, err
+		}
+		mvs = append(mvs, mv)
+	}
+	if fx.id != "" && !fx.used {
+		return nil, errcode.New(errcode.InvalidArgument, "FX rate "+fx.id+" given for a txn that moves no wallet in another currency")
+	}
+	return mvs, nil
+}
+
+// putTxnBal writes a txn_balance_object through txnbalcc
+func putTxnBal(stub shim.ChaincodeStubInterface, argsList []string) error {
+	return common.NewTxnBalClient(stub).Put(argsList)
+}
+
+// getWalletID asks the bank, business or loan chaincode for the ID of one of
+// its wallets
+func getWalletID(stub shim.ChaincodeStubInterface, ccName string, id string, walletType string) (string, error) {
+	walletID, err := common.Call(stub, ccName, "getWalletID", id, walletType)
+	return string(walletID), err
+}
+
+// getLoanWalletValue returns the balance of one of the loan's wallets after
+// the movements of the batch
+func getLoanWalletValue(batch *postingBatch, loanID string, walletType string) (money.Amount, error) {
+	walletID, err := common.NewLoanClient(batch.stub).WalletID(loanID, walletType)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	return batch.balance(walletID)
+}
@@ -0,0 +1,158 @@
+package txncc
+
+import (
+	"encoding/json"
+	"strconv"
+
+	clock "github.com/chaincode/Clock"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	events "github.com/chaincode/Events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// reversalTxnType is the TxnType of the contra transaction written by reverseTxn
+const reversalTxnType = "reversal"
+
+func reverseTxn(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	 *args[0] -> Original TxnID
+	 *args[1] -> Reason
+	 *args[2] -> By
+	 */
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in reverseTxn (required:3) given: "+xLenStr)
+	}
+	if args[1] == "" {
+		return errcode.Error("txncc", errcode.InvalidArgument, "A reason is required to reverse "+args[0])
+	}
+
+	txnBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if txnBytes == nil {
+		return errcode.Error("txncc", errcode.NotFound, "No data exists on this txnID: "+args[0])
+	}
+	original := transactionInfo{}
+	err = json.Unmarshal(txnBytes, &original)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "error while unmarshaling:"+err.Error())
+	}
+
+	if original.ReversedBy != "" {
+		return errcode.Error("txncc", errcode.InvalidState, args[0]+" is already reversed by "+original.ReversedBy)
+	} else if original.Reverses != "" {
+		return errcode.Error("txncc", errcode.InvalidState, args[0]+" is a reversal of "+original.Reverses+" and cannot be reversed")
+	} else if len(original.Legs) == 0 {
+		return errcode.Error("txncc", errcode.InvalidState, args[0]+" has no wallet legs recorded, it cannot be reversed")
+	}
+
+	revID := "rev_" + args[0]
+	ifExists, err := stub.GetState(revID)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if ifExists != nil {
+		return errcode.Error("txncc", errcode.AlreadyExists, "TxnID "+revID+" exists. Cannot reverse "+args[0])
+	}
+
+	// A reversal takes effect on the day it is posted
+	revDate, err := clock.PostingDate(stub)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+
+	// Unapplied credit that is already applied or refunded cannot be taken back
+	batch := newPostingBatch(stub)
+	err = trackUnapplied(batch, original, true)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+
+	// The legs are undone last first, each with the opposite wallet movement
+	mvs := []common.WalletMovement{}
+	txnBal := common.NewTxnBalClient(stub)
+	for i := len(original.Legs) - 1; i >= 0; i-- {
+		legID := revID + "_" + strconv.Itoa(len(mvs)+1)
+		record, err := txnBal.Record(original.Legs[i])
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, "leg "+original.Legs[i]+": "+err.Error())
+		}
+
+		function, amt := "debit", record.CAmt
+		if record.CAmt.IsZero() {
+			function, amt = "credit", record.DAmt
+		}
+		// a leg converted from the currency of the txn is undone at the same rate
+		fxRateID := ""
+		if amt.Currency != original.Amt.Currency {
+			fxRateID = original.FxRateID
+		}
+		mv, err := batch.move(function, record.WalletID, amt, legID, fxRateID)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, "reversing leg "+original.Legs[i]+": "+err.Error())
+		}
+		err = putTxnBal(stub, []string{legID, revID, revDate.Format("02/01/2006"), original.LoanID, original.InsID, record.WalletID, mv.OpeningBal.String(), reversalTxnType, original.Amt.String(), mv.CAmt.String(), mv.DAmt.String(), mv.ClosingBal.String(), args[2]})
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		}
+		mvs = append(mvs, mv)
+	}
+
+	if original.NewLoanStatus != "" {
+		err = common.NewLoanClient(stub).RestoreStatus(original.LoanID, original.NewLoanStatus, original.PrevLoanStatus)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		}
+	}
+
+	err = restorePenalDue(batch, original)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = batch.flush()
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+
+	reversal := transactionInfo{TxnType: reversalTxnType, TxnDate: revDate, PostingDate: revDate, LoanID: original.LoanID, InsID: original.InsID, Amt: original.Amt, FromID: original.ToID, ToID: original.FromID, By: args[2]}
+	reversal.Legs = legIDs(mvs)
+	reversal.PrevLoanStatus = original.NewLoanStatus
+	reversal.NewLoanStatus = original.PrevLoanStatus
+	reversal.Reverses = args[0]
+	reversal.Reason = args[1]
+	revBytes, _ := json.Marshal(reversal)
+	err = stub.PutState(revID, revBytes)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "Cannot write into ledger the reversal details")
+	}
+
+	original.ReversedBy = revID
+	txnBytes, _ = json.Marshal(original)
+	err = stub.PutState(args[0], txnBytes)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "Cannot write into ledger the transaction details")
+	}
+
+	err = events.Emit(stub, "transactioncc", txnEvents(revID, reversal, mvs)...)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+
+	return shim.Success([]byte(revID))
+}
+
+// restorePenalDue undoes what the original transaction did to the penal
+// interest due on its loan
+func restorePenalDue(batch *postingBatch, original transactionInfo) error {
+	if original.TxnType == "penal_charges" {
+		return addPenalDue(batch, original.LoanID, original.Amt.Neg())
+	} else if original.TxnType == "penal_interest_collection" {
+		return addPenalDue(batch, original.LoanID, original.Amt)
+	} else if original.Allocation != nil && !original.Allocation.Penal.IsZero() {
+		return addPenalDue(batch, original.LoanID, original.Allocation.Penal)
+	}
+	return nil
+}
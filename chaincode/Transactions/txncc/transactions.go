@@ -0,0 +1,538 @@
+package txncc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	auth "github.com/chaincode/Auth"
+	clock "github.com/chaincode/Clock"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	events "github.com/chaincode/Events"
+	money "github.com/chaincode/Money"
+	schema "github.com/chaincode/Schema"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Chaincode is the txncc chaincode
+type Chaincode struct {
+}
+
+type transactionInfo struct {
+	TxnType string       `json:"TxnType"`      //args[1]
+	TxnDate time.Time    `json:"TxnDate"`      //args[2]//the value date
+	LoanID  string       `json:"LoanID"`       //args[3]
+	InsID   string       `json:"InstrumentID"` //args[4]
+	Amt     money.Amount `json:"TxnAmount"`    //args[5]
+	FromID  string       `json:"From"`         //args[6]
+	ToID    string       `json:"To"`           //args[7]
+	By      string       `json:"By"`           //args[8]
+	//PprID   string    `json:"PPR_ID"`
+	Legs           []string    `json:"Legs,omitempty"`            // txnbalcc IDs of the wallet legs
+	PrevLoanStatus string      `json:"PrevLoanStatus,omitempty"`  // loan status before the txn, when it changed it
+	NewLoanStatus  string      `json:"NewLoanStatus,omitempty"`   // loan status the txn left behind
+	Reverses       string      `json:"Reverses,omitempty"`        // txnID this txn reverses
+	ReversedBy     string      `json:"ReversedBy,omitempty"`      // txnID of the reversal of this txn
+	Reason         string      `json:"Reason,omitempty"`          // why the txn was reversed
+	ReqID          string      `json:"ClientRequestID,omitempty"` // args[9]
+	FxRateID       string      `json:"FxRateID,omitempty"`        // args[10]
+	PostingDate    time.Time   `json:"PostingDate"`               // business date the txn was posted on
+	Allocation     *allocation `json:"Allocation,omitempty"`      // how a repayment was appropriated
+}
+
+// txnResponse is what posting a repayment returns
+type txnResponse struct {
+	TxnID      string      `json:"TxnID"`
+	Allocation *allocation `json:"Allocation"`
+}
+
+// clientRequestIndex is the composite key prefix mapping a client request ID
+// to the txnID it was posted as
+const clientRequestIndex = "ClientRequest~RequestID"
+
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	// the channel and the names of the other chaincodes, if instantiated with them
+	err := common.InitConfig(stub)
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// accessPolicy says who may call each function of transactioncc
+var accessPolicy = auth.Policy{
+	"submitTxn":             auth.Roles(auth.Maker),
+	"approveTxn":            auth.Roles(auth.Checker),
+	"rejectTxn":             auth.Roles(auth.Checker),
+	"getTxnRequest":         auth.Roles(auth.Anyone),
+	"listTxnRequests":       auth.Roles(auth.Anyone),
+	"getTxnInfo":            auth.Roles(auth.Anyone),
+	"reverseTxn":            auth.Roles(auth.Checker, auth.BankAdmin),
+	"putPostingRule":        auth.Roles(auth.BankAdmin),
+	"getPostingRule":        auth.Roles(auth.Anyone),
+	"accrueInterest":        auth.Roles(auth.Ops, auth.BankAdmin),
+	"putDayCount":           auth.Roles(auth.BankAdmin),
+	"getDayCount":           auth.Roles(auth.Anyone),
+	"runEOD":                auth.Roles(auth.Ops, auth.BankAdmin),
+	"getEODReport":          auth.Roles(auth.Anyone),
+	"putPenalRate":          auth.Roles(auth.BankAdmin),
+	"getPenalRate":          auth.Roles(auth.Anyone),
+	"repayBulk":             auth.Roles(auth.Checker, auth.BankAdmin),
+	"applyUnappliedCredit":  auth.Roles(auth.Checker, auth.BankAdmin),
+	"refundUnappliedCredit": auth.Roles(auth.Checker, auth.BankAdmin),
+	"getUnappliedCredit":    auth.Roles(auth.Anyone),
+	"getBulkRepayment":      auth.Roles(auth.Anyone),
+	"getConfig":             auth.Roles(auth.Anyone),
+}
+
+// argSchemas names the arguments of the functions of transactioncc that take a JSON
+// object
+var argSchemas = schema.Schemas{
+	"submitTxn": {
+		{Name: "txnID", Kind: schema.Text},
+		{Name: "txnType", Kind: schema.Text},
+		{Name: "txnDate", Kind: schema.Date},
+		{Name: "loanID", Kind: schema.Text},
+		{Name: "instrumentID", Kind: schema.Text},
+		{Name: "amount", Kind: schema.Number},
+		{Name: "from", Kind: schema.Text},
+		{Name: "to", Kind: schema.Text},
+		{Name: "by", Kind: schema.Text},
+		{Name: "clientRequestID", Kind: schema.Text, Optional: true},
+		{Name: "fxRateID", Kind: schema.Text, Optional: true},
+	},
+	"reverseTxn": {
+		{Name: "txnID", Kind: schema.Text},
+		{Name: "reason", Kind: schema.Text},
+		{Name: "by", Kind: schema.Text},
+	},
+	"accrueInterest": {
+		{Name: "businessDate", Kind: schema.Date},
+		{Name: "bankID", Kind: schema.Text},
+		{Name: "by", Kind: schema.Text},
+	},
+	"runEOD": {
+		{Name: "businessDate", Kind: schema.Date},
+		{Name: "bankID", Kind: schema.Text},
+		{Name: "by", Kind: schema.Text},
+		{Name: "chunkSize", Kind: schema.Number, Optional: true},
+	},
+	"repayBulk": {
+		{Name: "bulkID", Kind: schema.Text},
+		{Name: "txnDate", Kind: schema.Date},
+		{Name: "buyerID", Kind: schema.Text},
+		{Name: "amount", Kind: schema.Number},
+		{Name: "bankID", Kind: schema.Text},
+		{Name: "by", Kind: schema.Text},
+		{Name: "loans", Kind: schema.Text, Optional: true},
+	},
+	"applyUnappliedCredit": {
+		{Name: "txnID", Kind: schema.Text},
+		{Name: "txnDate", Kind: schema.Date},
+		{Name: "buyerID", Kind: schema.Text},
+		{Name: "bankID", Kind: schema.Text},
+		{Name: "by", Kind: schema.Text},
+		{Name: "loans", Kind: schema.Text, Optional: true},
+	},
+	"refundUnappliedCredit": {
+		{Name: "txnID", Kind: schema.Text},
+		{Name: "txnDate", Kind: schema.Date},
+		{Name: "buyerID", Kind: schema.Text},
+		{Name: "amount", Kind: schema.Number},
+		{Name: "bankID", Kind: schema.Text},
+		{Name: "by", Kind: schema.Text},
+	},
+}
+
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	err := accessPolicy.Check(stub, function)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Unauthorized, err.Error())
+	}
+	args, err = argSchemas.Parse(function, args)
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error())
+	}
+
+	if function == "submitTxn" {
+		//Submits new Transaction Information for approval
+		return submitTxn(stub, args)
+	} else if function == "approveTxn" {
+		//Creates the new Transaction Information of an approved request
+		return approveTxn(stub, args)
+	} else if function == "rejectTxn" {
+		//Rejects a new Transaction Information request
+		return rejectTxn(stub, args)
+	} else if function == "getTxnRequest" {
+		//Retrieves a new Transaction Information request
+		return getTxnRequest(stub, args)
+	} else if function == "listTxnRequests" {
+		//Lists the new Transaction Information requests
+		return listTxnRequests(stub, args)
+	} else if function == "getTxnInfo" {
+		//Retrieves an existing transcation information
+		return getTxnInfo(stub, args)
+	} else if function == "reverseTxn" {
+		//Posts the contra entries of an existing transaction
+		return reverseTxn(stub, args)
+	} else if function == "putPostingRule" {
+		//Stores the posting rule of a transaction type
+		return putPostingRule(stub, args)
+	} else if function == "getPostingRule" {
+		//Retrieves the posting rule of a transaction type
+		return getPostingRule(stub, args)
+	} else if function == "accrueInterest" {
+		//Posts the interest of every loan that earns interest up to a business date
+		return accrueInterest(stub, args)
+	} else if function == "putDayCount" {
+		//Stores the day-count convention of the accrual run
+		return putDayCount(stub, args)
+	} else if function == "getDayCount" {
+		//Retrieves the day-count convention of the accrual run
+		return getDayCount(stub, args)
+	} else if function == "runEOD" {
+		//Closes a business date for the next chunk of loans that earn interest
+		return runEOD(stub, args)
+	} else if function == "getEODReport" {
+		//Retrieves the report of the EOD run of a business date
+		return getEODReport(stub, args)
+	} else if function == "putPenalRate" {
+		//Stores the penal interest rate of overdue loans
+		return putPenalRate(stub, args)
+	} else if function == "getPenalRate" {
+		//Retrieves the penal interest rate of overdue loans
+		return getPenalRate(stub, args)
+	} else if function == "repayBulk" {
+		//Applies one payment of a buyer across its loans, the rest held as its unapplied credit
+		return repayBulk(stub, args)
+	} else if function == "applyUnappliedCredit" {
+		//Applies the unapplied credit of a buyer to its loans
+		return applyUnappliedCredit(stub, args)
+	} else if function == "refundUnappliedCredit" {
+		//Pays the unapplied credit of a buyer back to it
+		return refundUnappliedCredit(stub, args)
+	} else if function == "getUnappliedCredit" {
+		//Retrieves the unapplied credit of a buyer
+		return getUnappliedCredit(stub, args)
+	} else if function == "getBulkRepayment" {
+		//Retrieves a bulk repayment and the repayments it was applied as
+		return getBulkRepayment(stub, args)
+	} else if function == "getConfig" {
+		//Returns the channel and the chaincode names it was instantiated with
+		cfgBytes, err := common.GetConfig(stub)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error())
+		}
+		return shim.Success(cfgBytes)
+	}
+	return errcode.Error("txncc", errcode.InvalidArgument, "No function named "+function+" in Transactionsssss")
+}
+
+func newTxnInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	batch := newPostingBatch(stub)
+	response, evs := postTxn(stub, batch, args)
+	if response.Status != shim.OK {
+		return response
+	}
+	err := batch.flush()
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	err = events.Emit(stub, "transactioncc", evs...)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	return response
+}
+
+// postTxn posts the txn in the batch and returns the events of it, for the
+// caller to emit once the batch is flushed
+func postTxn(stub shim.ChaincodeStubInterface, batch *postingBatch, args []string) (pb.Response, []events.Event) {
+	if len(args) < 9 || len(args) > 11 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in newTxnInfo(transactions) (required:9 to 11) given: "+xLenStr), nil
+	}
+
+	/*
+	 *TxnID    string    //args[0]
+	 *TxnType  string    //args[1]
+	 *TxnDate  time.Time //args[2]
+	 *LoanID   string    //args[3]
+	 *InsID    string    //args[4]
+	 *Amt      string    //args[5]
+	 *FromID   string    //args[6]
+	 *ToID     string    //args[7]
+	 *By       string    //args[8]
+	 *ReqID    string    //args[9] client request ID (optional)
+	 *FxRateID string    //args[10] FX rate of the wallets in another currency than the loan (optional)
+	 */
+
+	// A request ID that was already used is a retry of a call that went
+	// through, so the transaction it created is returned instead of posting
+	// it again
+	reqID := ""
+	if len(args) >= 10 && args[9] != "" {
+		reqID = args[9]
+		txnID, err := getRequestTxnID(stub, reqID)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+		} else if txnID != "" {
+			return postedTxn(stub, txnID), nil
+		}
+	}
+
+	ifExists, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+	} else if ifExists != nil {
+		return errcode.Error("txncc", errcode.AlreadyExists, "TxnID "+args[0]+" exists. Cannot create new ID"), nil
+	}
+
+	//Converting into lower case for comparison
+	tTypeLower := strings.ToLower(args[1])
+	rule, err := postingRuleFor(stub, tTypeLower)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+	}
+
+	//TxnDate -> tDate
+	tDate, err := time.Parse("02/01/2006", args[2])
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error()), nil
+	}
+
+	// Every leg is posted in the currency of the loan, and converted into the
+	// currency of its wallet by the FX rate
+	loanWalletID, err := common.NewLoanClient(stub).WalletID(args[3], "disbursed")
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "Loan Disbursed WalletID "+err.Error()), nil
+	}
+	currency, err := common.NewWalletClient(stub).Currency(loanWalletID)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+	}
+	amt, err := money.Parse(args[5], currency)
+	if err != nil {
+		return errcode.Error("txncc", errcode.InvalidArgument, err.Error()), nil
+	}
+	if amt.Sign() <= 0 {
+		return errcode.Error("txncc", errcode.InvalidArgument, "Transaction amount has to be above zero: "+args[5]), nil
+	}
+
+	ctx := &postingContext{stub: stub, batch: batch, loanID: args[3], amt: amt}
+
+	check, ok := postingChecks[tTypeLower]
+	if ok {
+		err = check(ctx)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+		}
+	}
+
+	// ids holds the participant of every role the rule can name
+	ids := map[string]string{"loan": args[3]}
+	ids["bank"] = args[6]
+	if rule.BankIs == "to" {
+		ids["bank"] = args[7]
+	}
+	for _, leg := range rule.Legs {
+		if leg.Role == "seller" && ids["seller"] == "" {
+			ids["seller"] = getSellerID(stub, args[3])
+		} else if leg.Role == "buyer" && ids["buyer"] == "" {
+			ids["buyer"] = getBuyerID(stub, args[3])
+		}
+	}
+
+	postings, err := preparePostings(stub, rule, ctx, ids)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+	}
+	mvs, err := applyPostings(stub, batch, args, postings)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+	}
+
+	after, ok := postingUpdates[tTypeLower]
+	if ok {
+		err = after(ctx)
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+		}
+	}
+
+	transaction := transactionInfo{TxnType: tTypeLower, TxnDate: tDate, LoanID: args[3], InsID: args[4], Amt: amt, FromID: args[6], ToID: args[7], By: args[8]}
+	transaction.Legs = legIDs(mvs)
+	transaction.PrevLoanStatus = ctx.prevStatus
+	transaction.NewLoanStatus = ctx.newStatus
+	transaction.ReqID = reqID
+	if len(args) == 11 {
+		transaction.FxRateID = args[10]
+	}
+	transaction.Allocation = ctx.alloc
+	err = trackUnapplied(batch, transaction, false)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+	}
+	transaction.PostingDate, err = clock.PostingDate(stub)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+	}
+
+	txnBytes, _ := json.Marshal(transaction)
+	err = stub.PutState(args[0], txnBytes)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "Cannot write into ledger the transaction details"), nil
+	}
+
+	if reqID != "" {
+		err = putRequestTxnID(stub, reqID, args[0])
+		if err != nil {
+			return errcode.Error("txncc", errcode.Internal, err.Error()), nil
+		}
+	}
+
+	return shim.Success(txnPayload(args[0], transaction)), txnEvents(args[0], transaction, mvs)
+}
+
+// txnPayload is the response to a posted transaction: its txnID, or for a
+// repayment a txnResponse with the allocation
+func txnPayload(txnID string, transaction transactionInfo) []byte {
+	if transaction.Allocation == nil {
+		return []byte(txnID)
+	}
+	payload, _ := json.Marshal(txnResponse{TxnID: txnID, Allocation: transaction.Allocation})
+	return payload
+}
+
+// postedTxn answers a retried client request with the response of the
+// transaction it was posted as
+func postedTxn(stub shim.ChaincodeStubInterface, txnID string) pb.Response {
+	txnBytes, err := stub.GetState(txnID)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	}
+	transaction := transactionInfo{}
+	err = json.Unmarshal(txnBytes, &transaction)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "error while unmarshaling:"+err.Error())
+	}
+	return shim.Success(txnPayload(txnID, transaction))
+}
+
+func getTxnInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("txncc", errcode.InvalidArgument, "Invalid number of arguments in getTxnInfo (required:1) given: "+xLenStr)
+	}
+
+	txnBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, err.Error())
+	} else if txnBytes == nil {
+		return errcode.Error("txncc", errcode.NotFound, "No data exists on this txnID: "+args[0])
+	}
+
+	transaction := transactionInfo{}
+	err = json.Unmarshal(txnBytes, &transaction)
+	if err != nil {
+		return errcode.Error("txncc", errcode.Internal, "error while unmarshaling:"+err.Error())
+	}
+
+	tString := fmt.Sprintf("%+v", transaction)
+	return shim.Success([]byte(tString))
+
+}
+
+// instrumentStatuses are the statuses of the instrument of a loan in each
+// status a posted txn moves the loan from or to, as loancc keeps them
+var instrumentStatuses = map[string]string{
+	"sanctioned":     "sanctioned",
+	"part_disbursed": "disbursed",
+	"part disbursed": "disbursed",
+	"disbursed":      "disbursed",
+	"overdue":        "overdue",
+	"part_collected": "disbursed",
+	"part collected": "disbursed",
+	"collected":      "settled",
+}
+
+// txnEvents lists the events of a posted or reversed transaction. The loan,
+// instrument and wallet changes are made by loancc and walletcc, whose own
+// events Fabric drops when they are called from here, so they are repeated.
+func txnEvents(txnID string, transaction transactionInfo, mvs []common.WalletMovement) []events.Event {
+	eventType := events.TxnPosted
+	if transaction.Reverses != "" {
+		eventType = events.TxnReversed
+	} else if transaction.TxnType == "disbursement" {
+		eventType = events.DisbursementPosted
+	} else if transaction.TxnType == "repayment" || transaction.TxnType == "credit_repayment" {
+		eventType = events.RepaymentCollected
+	}
+	txn := events.Txn{TxnID: txnID, TxnType: transaction.TxnType, TxnDate: transaction.TxnDate, PostingDate: transaction.PostingDate, LoanID: transaction.LoanID, InstrumentID: transaction.InsID, Amount: transaction.Amt,
+		From: transaction.FromID, To: transaction.ToID, By: transaction.By, Legs: transaction.Legs, Reverses: transaction.Reverses}
+	evs := []events.Event{events.New(eventType, txn)}
+
+	for _, mv := range mvs {
+		evs = append(evs, events.New(events.WalletBalanceChanged, events.WalletBalance{WalletID: mv.WalletID, TxnID: mv.TxnID, OpeningBal: mv.OpeningBal, Credit: mv.CAmt, Debit: mv.DAmt, ClosingBal: mv.ClosingBal, FxRateID: mv.FxRateID}))
+	}
+
+	if transaction.NewLoanStatus != "" {
+		evs = append(evs, events.New(events.LoanStatusChanged, events.StatusChange{ID: transaction.LoanID, From: transaction.PrevLoanStatus, To: transaction.NewLoanStatus, TxnID: txnID}))
+		// loancc moves the instrument along with the loan and back with the reversal
+		from, to := instrumentStatuses[transaction.PrevLoanStatus], instrumentStatuses[transaction.NewLoanStatus]
+		if from != to {
+			evs = append(evs, events.New(events.InstrumentStatusChanged, events.StatusChange{ID: transaction.InsID, From: from, To: to, TxnID: txnID}))
+		}
+	}
+	return evs
+}
+
+func legIDs(mvs []common.WalletMovement) []string {
+	ids := []string{}
+	for _, mv := range mvs {
+		ids = append(ids, mv.TxnID)
+	}
+	return ids
+}
+
+// getRequestTxnID returns the txnID the client request was posted as, or ""
+// when it has not been seen
+func getRequestTxnID(stub shim.ChaincodeStubInterface, reqID string) (string, error) {
+	reqKey, err := stub.CreateCompositeKey(clientRequestIndex, []string{reqID})
+	if err != nil {
+		return "", err
+	}
+	txnID, err := stub.GetState(reqKey)
+	return string(txnID), err
+}
+
+func putRequestTxnID(stub shim.ChaincodeStubInterface, reqID string, txnID string) error {
+	reqKey, err := stub.CreateCompositeKey(clientRequestIndex, []string{reqID})
+	if err != nil {
+		return err
+	}
+	return stub.PutState(reqKey, []byte(txnID))
+}
+
+func getSellerID(stub shim.ChaincodeStubInterface, loanID string) string {
+	fmt.Println("transactioncc: " + "calling the loan chaincode")
+	sellerID, err := common.NewLoanClient(stub).SellerID(loanID)
+	if err != nil {
+		return "not_found"
+	}
+	return sellerID
+}
+
+func getBuyerID(stub shim.ChaincodeStubInterface, loanID string) string {
+	fmt.Println("transactioncc: " + "calling the loan chaincode")
+	buyerID, err := common.NewLoanClient(stub).BuyerID(loanID)
+	if err != nil {
+		return "not_found"
+	}
+	return buyerID
+}
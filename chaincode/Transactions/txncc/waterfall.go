@@ -0,0 +1,145 @@
+package txncc
+
+import (
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	money "github.com/chaincode/Money"
+)
+
+// penalDueIndex is the composite key prefix of the penal interest that is
+// charged on a loan and not yet paid. Penal interest is posted to the loan
+// charges wallet along with the other charges, so this is what tells the two
+// apart when a repayment is appropriated.
+const penalDueIndex = "PenalDue~LoanID"
+
+// allocation is how a repayment was appropriated over what the loan owes, in
+// the order of the waterfall of its program
+type allocation struct {
+	Waterfall []string     `json:"Waterfall"`
+	Penal     money.Amount `json:"Penal"`
+	Charges   money.Amount `json:"Charges"`
+	Interest  money.Amount `json:"Interest"`
+	Principal money.Amount `json:"Principal"`
+	Excess    money.Amount `json:"Excess"` // what is left once the waterfall is paid
+}
+
+// allocate appropriates the transaction amount over the penal interest,
+// charges, accrued interest and principal of the loan, in the order of the
+// program's waterfall. Components left out of the waterfall are not paid.
+func (ctx *postingContext) allocate() error {
+	if ctx.alloc != nil {
+		return nil
+	}
+	err := ctx.loadLoan()
+	if err != nil {
+		return err
+	}
+	programID, err := common.NewLoanClient(ctx.stub).ProgramID(ctx.loanID)
+	if err != nil {
+		return err
+	}
+	waterfall, err := common.NewProgramClient(ctx.stub).Waterfall(programID)
+	if err != nil {
+		return err
+	}
+
+	penalDue, err := getPenalDue(ctx.batch, ctx.loanID, ctx.amt.Currency)
+	if err != nil {
+		return err
+	}
+	penal, err := money.Min(penalDue, ctx.charges)
+	if err != nil {
+		return err
+	}
+	charges, err := ctx.charges.Sub(penal)
+	if err != nil {
+		return err
+	}
+	owed := map[string]money.Amount{"penal": penal, "charges": charges, "interest": ctx.accrued, "principal": ctx.disbursed}
+
+	paid := map[string]money.Amount{}
+	for component := range owed {
+		paid[component] = money.Zero(ctx.amt.Currency)
+	}
+	left := ctx.amt
+	ctx.cleared = true
+	for _, component := range waterfall {
+		due, ok := owed[component]
+		if !ok {
+			return errcode.New(errcode.Internal, "program "+programID+" has an unknown repayment component "+component)
+		}
+		paid[component], err = money.Min(left, due)
+		if err != nil {
+			return err
+		}
+		left, err = left.Sub(paid[component])
+		if err != nil {
+			return err
+		}
+		ctx.cleared = ctx.cleared && paid[component].Units == due.Units
+	}
+
+	ctx.alloc = &allocation{Waterfall: waterfall, Penal: paid["penal"], Charges: paid["charges"], Interest: paid["interest"], Principal: paid["principal"], Excess: left}
+	return nil
+}
+
+// chargesPaid is what the allocation takes off the loan charges wallet
+func (a *allocation) chargesPaid() (money.Amount, error) {
+	return a.Penal.Add(a.Charges)
+}
+
+// settled is what the allocation pays on the loan
+func (a *allocation) settled() (money.Amount, error) {
+	settled, err := a.chargesPaid()
+	if err != nil {
+		return settled, err
+	}
+	settled, err = settled.Add(a.Interest)
+	if err != nil {
+		return settled, err
+	}
+	return settled.Add(a.Principal)
+}
+
+// getPenalDue returns the penal interest charged on the loan that is not yet
+// paid
+func getPenalDue(batch *postingBatch, loanID string, currency string) (money.Amount, error) {
+	dueKey, err := batch.stub.CreateCompositeKey(penalDueIndex, []string{loanID})
+	if err != nil {
+		return money.Amount{}, err
+	}
+	return batch.amount(dueKey, currency)
+}
+
+// addPenalDue moves the unpaid penal interest of the loan by amt, which is
+// negative when penal interest is paid. It does not go below zero.
+func addPenalDue(batch *postingBatch, loanID string, amt money.Amount) error {
+	due, err := getPenalDue(batch, loanID, amt.Currency)
+	if err != nil {
+		return err
+	}
+	due, err = due.Add(amt)
+	if err != nil {
+		return err
+	}
+	if due.IsNegative() {
+		due = money.Zero(amt.Currency)
+	}
+	dueKey, err := batch.stub.CreateCompositeKey(penalDueIndex, []string{loanID})
+	if err != nil {
+		return err
+	}
+	batch.putAmount(dueKey, due)
+	return nil
+}
+
+// chargePenal adds the penal interest charged on the loan to what is due
+func chargePenal(ctx *postingContext) error {
+	return addPenalDue(ctx.batch, ctx.loanID, ctx.amt)
+}
+
+// collectPenal takes penal interest collected outside a repayment off what
+// is due
+func collectPenal(ctx *postingContext) error {
+	return addPenalDue(ctx.batch, ctx.loanID, ctx.amt.Neg())
+}
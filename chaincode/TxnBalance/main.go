@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+
+	txnbalcc "github.com/chaincode/TxnBalance/txnbalcc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() {
+	err := shim.Start(new(txnbalcc.Chaincode))
+	if err != nil {
+		fmt.Printf("txnbalcc: "+"Error starting Simple chaincode: %s\n", err)
+	}
+}
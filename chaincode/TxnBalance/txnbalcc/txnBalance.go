@@ -0,0 +1,209 @@
+package txnbalcc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	auth "github.com/chaincode/Auth"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	money "github.com/chaincode/Money"
+	schema "github.com/chaincode/Schema"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Chaincode is the txnbalcc chaincode
+type Chaincode struct {
+}
+
+type txnBalanceInfo struct {
+	TxnID      string       `json:"TxnID"`
+	TxnDate    time.Time    `json:"TxnDate"`
+	LoanID     string       `json:"LoanID"`
+	InsID      string       `json:"InsID"`
+	WalletID   string       `json:"WalletID"`
+	Currency   string       `json:"Currency"`
+	OpeningBal money.Amount `json:"OpeningBalance"`
+	TxnType    string       `json:"TxnType"`
+	Amt        money.Amount `json:"Amount"`
+	CAmt       money.Amount `json:"CreditAmount"`
+	DAmt       money.Amount `json:"DebitAmount"`
+	TxnBal     money.Amount `json:"TxnBalance"`
+	By         string       `json:"By"`
+}
+
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	// the channel and the names of the other chaincodes, if instantiated with them
+	err := common.InitConfig(stub)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// accessPolicy says who may call each function of txnbalcc
+var accessPolicy = auth.Policy{
+	"putTxnBalInfo":   auth.Callers("txncc"),
+	"getTxnBalInfo":   auth.Roles(auth.Anyone),
+	"getTxnBalRecord": auth.Roles(auth.Anyone),
+	"getConfig":       auth.Roles(auth.Anyone),
+}
+
+// argSchemas names the arguments of the functions of txnbalcc that take a JSON
+// object
+var argSchemas = schema.Schemas{
+	"putTxnBalInfo": {
+		{Name: "txnBalID", Kind: schema.Text},
+		{Name: "txnID", Kind: schema.Text},
+		{Name: "txnDate", Kind: schema.Date},
+		{Name: "loanID", Kind: schema.Text},
+		{Name: "instrumentID", Kind: schema.Text},
+		{Name: "walletID", Kind: schema.Text},
+		{Name: "openingBal", Kind: schema.Number},
+		{Name: "txnType", Kind: schema.Text},
+		{Name: "amount", Kind: schema.Number},
+		{Name: "creditAmt", Kind: schema.Number},
+		{Name: "debitAmt", Kind: schema.Number},
+		{Name: "txnBalance", Kind: schema.Number},
+		{Name: "by", Kind: schema.Text},
+	},
+}
+
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	err := accessPolicy.Check(stub, function)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.Unauthorized, err.Error())
+	}
+	args, err = argSchemas.Parse(function, args)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, err.Error())
+	}
+	if function == "putTxnBalInfo" { //Inserting a New Business information
+		return c.putTxnBalInfo(stub, args)
+	} else if function == "getTxnBalInfo" { // To view a Business information
+		return c.getTxnBalInfo(stub, args)
+	} else if function == "getTxnBalRecord" { // Returns the txn balance object as JSON
+		return c.getTxnBalRecord(stub, args)
+	} else if function == "getConfig" {
+		//Returns the channel and the chaincode names it was instantiated with
+		cfgBytes, err := common.GetConfig(stub)
+		if err != nil {
+			return errcode.Error("txnbalcc", errcode.Internal, err.Error())
+		}
+		return shim.Success(cfgBytes)
+	}
+	return errcode.Error("txnbalcc", errcode.InvalidArgument, "Inside txnBalcc:Invoke(), Function does not exit"+function)
+}
+
+func (c *Chaincode) putTxnBalInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	// the comma joined form goes with the positional one in the next release
+	if len(args) == 1 {
+		args = strings.Split(args[0], ",")
+	}
+	if len(args) != 13 {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, "Invalid number of arguments for txnBal. Needed 13 arguments")
+	}
+
+	ifExists, err := stub.GetState(args[0])
+	if ifExists != nil {
+		return errcode.Error("txnbalcc", errcode.AlreadyExists, "TxnBalanceId "+args[0]+" exits. Cannot create new ID")
+	}
+
+	//TxnDate ->txnDate
+	txnDate, err := time.Parse("02/01/2006", args[2])
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, "txnbal err in txndate "+err.Error())
+	}
+
+	// every leg is recorded in the currency of the wallet it moved
+	currency, err := common.NewWalletClient(stub).Currency(args[5])
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.Internal, "txnbal err in currency "+err.Error())
+	}
+
+	openBal, err := money.Parse(args[6], currency)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, "txnbal err in openbal "+err.Error())
+	}
+
+	// transactioncc checks the txn type against its posting rules, which can
+	// add new types, so only a missing type is refused here
+	txnTypeLower := strings.ToLower(args[7])
+	if txnTypeLower == "" {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, "txnbal Invalid Transaction type")
+	}
+
+	amt, err := money.Parse(args[8], currency)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, "txnbal err in amt"+err.Error())
+	}
+
+	cAmt, err := money.Parse(args[9], currency)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, "txnbal err in camt"+err.Error())
+	}
+
+	dAmt, err := money.Parse(args[10], currency)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, "txnbal err in damt "+err.Error())
+	}
+
+	txnBal, err := money.Parse(args[11], currency)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, "txnbal err in txnbal "+err.Error())
+	}
+
+	txnBalance := txnBalanceInfo{args[1], txnDate, args[3], args[4], args[5], currency, openBal, txnTypeLower, amt, cAmt, dAmt, txnBal, args[12]}
+	txnBalanceBytes, err := json.Marshal(txnBalance)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.Internal, err.Error())
+	}
+	err = stub.PutState(args[0], txnBalanceBytes)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.Internal, "txnbal cannot write to ledger: "+err.Error())
+	}
+	fmt.Println("Succefully wrote txnID " + args[0] + " into the ledger")
+	return shim.Success(nil)
+
+}
+
+func (c *Chaincode) getTxnBalInfo(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, "Required only one argument")
+	}
+
+	txnBalance := txnBalanceInfo{}
+	txnBalanceBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.Internal, "Failed to get the business information: "+err.Error())
+	} else if txnBalanceBytes == nil {
+		return errcode.Error("txnbalcc", errcode.NotFound, "No information is avalilable on this businessID "+args[0])
+	}
+
+	err = json.Unmarshal(txnBalanceBytes, &txnBalance)
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.Internal, "Unable to parse into the structure "+err.Error())
+	}
+	jsonString := fmt.Sprintf("%+v", txnBalance)
+	fmt.Printf("Transaction info %s : %s", args[0], jsonString)
+	return shim.Success([]byte(jsonString))
+}
+
+// getTxnBalRecord returns the stored txn_balance_object as it is, for
+// chaincodes that have to read it back (reversals in transactioncc)
+func (c *Chaincode) getTxnBalRecord(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return errcode.Error("txnbalcc", errcode.InvalidArgument, "Required only one argument")
+	}
+	txnBalanceBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("txnbalcc", errcode.Internal, err.Error())
+	} else if txnBalanceBytes == nil {
+		return errcode.Error("txnbalcc", errcode.NotFound, "No txn balance object exists on this ID "+args[0])
+	}
+	return shim.Success(txnBalanceBytes)
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+
+	walletcc "github.com/chaincode/Wallet/walletcc"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func main() {
+	err := shim.Start(new(walletcc.Chaincode))
+	if err != nil {
+		fmt.Printf("walletcc: "+"Error starting Wallet chaincode: %s\n", err)
+	}
+}
@@ -0,0 +1,722 @@
+package walletcc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	auth "github.com/chaincode/Auth"
+	clock "github.com/chaincode/Clock"
+	common "github.com/chaincode/Common"
+	errcode "github.com/chaincode/Errcode"
+	events "github.com/chaincode/Events"
+	history "github.com/chaincode/History"
+	money "github.com/chaincode/Money"
+	schema "github.com/chaincode/Schema"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Chaincode is the walletcc chaincode
+type Chaincode struct {
+}
+
+type walletsInfo struct {
+	Balance  money.Amount `json:"balance"`
+	Currency string       `json:"currency"`
+}
+
+// fxRateInfo is an FX rate agreed for a cross-currency txn: Rate units of
+// ToCurrency for one unit of FromCurrency
+type fxRateInfo struct {
+	FromCurrency string     `json:"fromCurrency"`
+	ToCurrency   string     `json:"toCurrency"`
+	Rate         money.Rate `json:"rate"`
+	RateDate     time.Time  `json:"rateDate"`
+}
+
+// walletMovement is returned by credit, debit and move so that the caller
+// knows exactly what happened to the wallet
+type walletMovement struct {
+	TxnID      string       `json:"txnID"`
+	WalletID   string       `json:"walletID"`
+	OpeningBal money.Amount `json:"openingBal"`
+	CAmt       money.Amount `json:"cAmt"`
+	DAmt       money.Amount `json:"dAmt"`
+	ClosingBal money.Amount `json:"closingBal"`
+	FxRateID   string       `json:"fxRateID,omitempty"`
+}
+
+// walletLeg is one movement of a wallet in a transaction that moves it more
+// than once, as txncc sends them to move. FxRateID is the rate a leg of a txn
+// in another currency was converted into the wallet's by.
+type walletLeg struct {
+	TxnID    string       `json:"txnID"`
+	CAmt     money.Amount `json:"cAmt"`
+	DAmt     money.Amount `json:"dAmt"`
+	FxRateID string       `json:"fxRateID,omitempty"`
+}
+
+// journalEntry is written under WalletID~TxnTimestamp~TxnID for every change
+// in a wallet's balance
+type journalEntry struct {
+	walletMovement
+	TxnTimestamp time.Time `json:"txnTimestamp"`
+}
+
+type walletStatement struct {
+	WalletID   string         `json:"walletID"`
+	FromDate   string         `json:"fromDate"`
+	ToDate     string         `json:"toDate"`
+	OpeningBal money.Amount   `json:"openingBal"`
+	Entries    []journalEntry `json:"entries"`
+	ClosingBal money.Amount   `json:"closingBal"`
+}
+
+// journalIndex keys sort by timestamp within a wallet, so the timestamp is
+// written in a fixed width UTC layout, and then by the number of the
+// movement within the transaction. Entries written before movements were
+// numbered have no number, under the same index.
+const journalIndex = "WalletID~TxnTimestamp~TxnID"
+const journalTimeLayout = "2006-01-02T15:04:05.000000000Z"
+const journalSeqLayout = "%06d"
+
+// fxRateIndex keeps FX rate records apart from the wallets
+const fxRateIndex = "FxRateID"
+
+func (c *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	// the channel and the names of the other chaincodes, if instantiated with them
+	err := common.InitConfig(stub)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.InvalidArgument, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// accessPolicy says who may call each function of walletcc
+var accessPolicy = auth.Policy{
+	"newWallet":          auth.Callers("bankcc", "businesscc", "loancc"),
+	"getWallet":          auth.Roles(auth.Anyone),
+	"credit":             auth.Callers("txncc"),
+	"debit":              auth.Callers("txncc"),
+	"move":               auth.Callers("txncc"),
+	"getWalletStatement": auth.Roles(auth.Anyone),
+	"getWalletCurrency":  auth.Roles(auth.Anyone),
+	"putFxRate":          auth.Roles(auth.BankAdmin),
+	"getFxRate":          auth.Roles(auth.Anyone),
+	"getWalletHistory":   auth.Roles(auth.Auditor, auth.Ops, auth.BankAdmin),
+	"getConfig":          auth.Roles(auth.Anyone),
+}
+
+// argSchemas names the arguments of the functions of walletcc that take a
+// JSON object
+var argSchemas = schema.Schemas{
+	"newWallet": {
+		{Name: "walletID", Kind: schema.Text},
+		{Name: "balance", Kind: schema.Number},
+		{Name: "currency", Kind: schema.Text, Optional: true},
+	},
+	"credit": {
+		{Name: "walletID", Kind: schema.Text},
+		{Name: "amount", Kind: schema.Number},
+		{Name: "txnID", Kind: schema.Text},
+		{Name: "currency", Kind: schema.Text, Optional: true},
+	},
+	"debit": {
+		{Name: "walletID", Kind: schema.Text},
+		{Name: "amount", Kind: schema.Number},
+		{Name: "txnID", Kind: schema.Text},
+		{Name: "currency", Kind: schema.Text, Optional: true},
+	},
+	"move": {
+		{Name: "walletID", Kind: schema.Text},
+		{Name: "legs", Kind: schema.Text},
+	},
+	"putFxRate": {
+		{Name: "fxRateID", Kind: schema.Text},
+		{Name: "fromCurrency", Kind: schema.Text},
+		{Name: "toCurrency", Kind: schema.Text},
+		{Name: "rate", Kind: schema.Number},
+		{Name: "rateDate", Kind: schema.Date},
+	},
+}
+
+func (c *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	err := accessPolicy.Check(stub, function)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Unauthorized, err.Error())
+	}
+	args, err = argSchemas.Parse(function, args)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.InvalidArgument, err.Error())
+	}
+
+	if function == "newWallet" {
+		return newWallet(stub, args)
+	} else if function == "getWallet" {
+		return getWallet(stub, args)
+	} else if function == "credit" {
+		return credit(stub, args)
+	} else if function == "debit" {
+		return debit(stub, args)
+	} else if function == "move" {
+		//Applies every leg of a transaction that moves the wallet, in order
+		return move(stub, args)
+	} else if function == "getWalletStatement" {
+		return getWalletStatement(stub, args)
+	} else if function == "getWalletCurrency" {
+		return getWalletCurrency(stub, args)
+	} else if function == "putFxRate" {
+		return putFxRate(stub, args)
+	} else if function == "getFxRate" {
+		return getFxRate(stub, args)
+	} else if function == "getWalletHistory" {
+		//Returns every version of the wallet, a page at a time
+		return getWalletHistory(stub, args)
+	} else if function == "getConfig" {
+		//Returns the channel and the chaincode names it was instantiated with
+		cfgBytes, err := common.GetConfig(stub)
+		if err != nil {
+			return errcode.Error("walletcc", errcode.Internal, err.Error())
+		}
+		return shim.Success(cfgBytes)
+	}
+	return errcode.Error("walletcc", errcode.InvalidArgument, "No function named "+function+" in Wallet")
+
+}
+
+//Creating new Wallet
+
+func newWallet(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	*args[0] -> WalletID
+	*args[1] -> Wallet Ballance
+	*args[2] -> Currency (optional, INR when not given)
+	 */
+	if len(args) != 2 && len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Invalid number of arguments in newWallet (required:2 or 3) given:"+xLenStr)
+	}
+
+	currency := money.DefaultCurrency
+	if len(args) == 3 && args[2] != "" {
+		currency = strings.ToUpper(args[2])
+	}
+	bal64, err := money.Parse(args[1], currency)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.InvalidArgument, err.Error())
+	}
+
+	ifExists, err := stub.GetState(args[0])
+	if ifExists != nil {
+		return errcode.Error("walletcc", errcode.AlreadyExists, "WalletId "+args[0]+" exits. Cannot create new ID")
+	}
+
+	bal := walletsInfo{bal64, currency}
+	balBytes, _ := json.Marshal(bal)
+	err = history.Put(stub, args[0], balBytes)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+
+	zero := money.Zero(bal64.Currency)
+	mv := walletMovement{stub.GetTxID(), args[0], zero, bal64, zero, bal64, ""}
+	err = putJournal(stub, mv, 0)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	err = events.Emit(stub, "walletcc", balanceChanged(mv))
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+func getWallet(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Invalid number of arguments in getWallet (required:1) given: "+xLenStr)
+	}
+	balBytes, err := stub.GetState(args[0])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	} else if balBytes == nil {
+		return errcode.Error("walletcc", errcode.NotFound, "No data exists on this WalletId: "+args[0])
+	}
+	bal := walletsInfo{}
+	err = json.Unmarshal(balBytes, &bal)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	//balString := fmt.Sprintf("%+v", bal)
+	//fmt.Printf("Wallet %s : %s\n", args[0], balString)
+	balStr := bal.Balance.String()
+	fmt.Println(balStr)
+	return shim.Success([]byte(balStr))
+}
+
+func getWalletCurrency(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Invalid number of arguments in getWalletCurrency (required:1) given: "+xLenStr)
+	}
+	bal, err := getWalletsInfo(stub, args[0])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	return shim.Success([]byte(bal.Currency))
+}
+
+//Moving money in and out of wallets
+
+func credit(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	*args[0] -> WalletID
+	*args[1] -> Amount
+	*args[2] -> TxnID
+	*args[3] -> Currency (optional, must be the wallet's currency)
+	 */
+	if len(args) != 3 && len(args) != 4 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Invalid number of arguments in credit (required:3 or 4) given: "+xLenStr)
+	}
+	amt, err := parseWalletAmount(stub, args[0], args[1], args[3:])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.InvalidArgument, err.Error())
+	}
+	err = checkTxnID(stub, args[2])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+
+	mv, err := applyMovement(stub, args[0], amt, money.Zero(amt.Currency), args[2])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	err = events.Emit(stub, "walletcc", balanceChanged(mv))
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	mvBytes, _ := json.Marshal(mv)
+	return shim.Success(mvBytes)
+}
+
+func debit(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	*args[0] -> WalletID
+	*args[1] -> Amount
+	*args[2] -> TxnID
+	*args[3] -> Currency (optional, must be the wallet's currency)
+	 */
+	if len(args) != 3 && len(args) != 4 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Invalid number of arguments in debit (required:3 or 4) given: "+xLenStr)
+	}
+	amt, err := parseWalletAmount(stub, args[0], args[1], args[3:])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.InvalidArgument, err.Error())
+	}
+	err = checkTxnID(stub, args[2])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+
+	mv, err := applyMovement(stub, args[0], money.Zero(amt.Currency), amt, args[2])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	err = events.Emit(stub, "walletcc", balanceChanged(mv))
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	mvBytes, _ := json.Marshal(mv)
+	return shim.Success(mvBytes)
+}
+
+func move(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	*args[0] -> WalletID
+	*args[1] -> Legs, a JSON list of {"txnID", "cAmt", "dAmt", "fxRateID"} in the order they are applied
+	 */
+	if len(args) != 2 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Invalid number of arguments in move (required:2) given: "+xLenStr)
+	}
+	bal, err := getWalletsInfo(stub, args[0])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	legs := []walletLeg{}
+	err = json.Unmarshal([]byte(args[1]), &legs)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Error in parsing the legs "+err.Error())
+	} else if len(legs) == 0 {
+		return errcode.Error("walletcc", errcode.InvalidArgument, "No legs to move WalletId "+args[0]+" by")
+	}
+	txnIDs := map[string]bool{}
+	for _, leg := range legs {
+		// a leg converted from another currency names a rate into the wallet's
+		if leg.FxRateID != "" {
+			fx, err := getFxRateInfo(stub, leg.FxRateID)
+			if err != nil {
+				return errcode.Error("walletcc", errcode.Internal, err.Error())
+			}
+			if fx.ToCurrency != bal.Currency {
+				return errcode.Error("walletcc", errcode.InvalidArgument, "FX rate "+leg.FxRateID+" is for "+fx.FromCurrency+" to "+fx.ToCurrency+", not to "+bal.Currency+" WalletId "+args[0])
+			}
+		}
+		for _, amt := range []money.Amount{leg.CAmt, leg.DAmt} {
+			if amt.Currency != bal.Currency {
+				return errcode.Error("walletcc", errcode.InvalidArgument, "Cannot move "+amt.Currency+" in "+bal.Currency+" WalletId "+args[0])
+			} else if amt.IsNegative() {
+				return errcode.Error("walletcc", errcode.InvalidArgument, "Amount cannot be negative: "+amt.String())
+			}
+		}
+		// a leg written earlier in this transaction is not read back
+		if txnIDs[leg.TxnID] {
+			return errcode.Error("walletcc", errcode.AlreadyExists, "TxnID "+leg.TxnID+" is given twice")
+		}
+		txnIDs[leg.TxnID] = true
+		err = checkTxnID(stub, leg.TxnID)
+		if err != nil {
+			return errcode.Error("walletcc", errcode.Internal, err.Error())
+		}
+	}
+
+	mvs, err := applyLegs(stub, args[0], legs)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	evs := []events.Event{}
+	for _, mv := range mvs {
+		evs = append(evs, balanceChanged(mv))
+	}
+	err = events.Emit(stub, "walletcc", evs...)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	mvBytes, _ := json.Marshal(mvs)
+	return shim.Success(mvBytes)
+}
+
+//FX rates for cross-currency txns
+
+func putFxRate(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	*args[0] -> FxRateID
+	*args[1] -> From Currency
+	*args[2] -> To Currency
+	*args[3] -> Rate (units of To Currency for one unit of From Currency)
+	*args[4] -> Rate Date (dd/mm/yyyy)
+	 */
+	if len(args) != 5 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Invalid number of arguments in putFxRate (required:5) given: "+xLenStr)
+	}
+	fxRateKey, err := stub.CreateCompositeKey(fxRateIndex, []string{args[0]})
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	ifExists, err := stub.GetState(fxRateKey)
+	if ifExists != nil {
+		return errcode.Error("walletcc", errcode.AlreadyExists, "FxRateID "+args[0]+" exits. An FX rate cannot be changed once written")
+	}
+
+	fromCurrency := strings.ToUpper(args[1])
+	toCurrency := strings.ToUpper(args[2])
+	if fromCurrency == toCurrency {
+		return errcode.Error("walletcc", errcode.InvalidArgument, "FX rate needs two different currencies, given "+fromCurrency+" twice")
+	}
+	for _, currency := range []string{fromCurrency, toCurrency} {
+		_, err = money.Exponent(currency)
+		if err != nil {
+			return errcode.Error("walletcc", errcode.InvalidArgument, err.Error())
+		}
+	}
+	rate, err := money.ParseRate(args[3])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.InvalidArgument, err.Error())
+	}
+	if rate.IsNegative() || rate.IsZero() {
+		return errcode.Error("walletcc", errcode.InvalidArgument, "FX rate has to be above zero: "+args[3])
+	}
+	rateDate, err := time.Parse("02/01/2006", args[4])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Error in parsing the rate date "+err.Error())
+	}
+
+	fx := fxRateInfo{fromCurrency, toCurrency, rate, rateDate}
+	fxBytes, _ := json.Marshal(fx)
+	err = stub.PutState(fxRateKey, fxBytes)
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	return shim.Success(nil)
+}
+
+func getFxRate(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Invalid number of arguments in getFxRate (required:1) given: "+xLenStr)
+	}
+	fx, err := getFxRateInfo(stub, args[0])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	fxBytes, _ := json.Marshal(fx)
+	return shim.Success(fxBytes)
+}
+
+func getFxRateInfo(stub shim.ChaincodeStubInterface, fxRateID string) (fxRateInfo, error) {
+	fx := fxRateInfo{}
+	fxRateKey, err := stub.CreateCompositeKey(fxRateIndex, []string{fxRateID})
+	if err != nil {
+		return fx, err
+	}
+	fxBytes, err := stub.GetState(fxRateKey)
+	if err != nil {
+		return fx, err
+	} else if fxBytes == nil {
+		return fx, errcode.New(errcode.NotFound, "No FX rate exists on this FxRateID: "+fxRateID)
+	}
+	err = json.Unmarshal(fxBytes, &fx)
+	return fx, err
+}
+
+// applyMovement credits cAmt and debits dAmt on a wallet, refusing to take the
+// balance below zero, and records the txnID against the wallet
+func applyMovement(stub shim.ChaincodeStubInterface, walletID string, cAmt money.Amount, dAmt money.Amount, txnID string) (walletMovement, error) {
+	mvs, err := applyLegs(stub, walletID, []walletLeg{{TxnID: txnID, CAmt: cAmt, DAmt: dAmt}})
+	if err != nil {
+		return walletMovement{}, err
+	}
+	return mvs[0], nil
+}
+
+// applyLegs moves the wallet by each leg in turn the way applyMovement does,
+// journalling every leg, and writes the balance the last one leaves
+func applyLegs(stub shim.ChaincodeStubInterface, walletID string, legs []walletLeg) ([]walletMovement, error) {
+	bal, err := getWalletsInfo(stub, walletID)
+	if err != nil {
+		return nil, err
+	}
+	mvs := []walletMovement{}
+	for seq, leg := range legs {
+		closingBal, err := bal.Balance.Add(leg.CAmt)
+		if err != nil {
+			return nil, err
+		}
+		closingBal, err = closingBal.Sub(leg.DAmt)
+		if err != nil {
+			return nil, err
+		}
+		if closingBal.IsNegative() {
+			return nil, errcode.New(errcode.InsufficientFunds, fmt.Sprintf("Insufficient balance in WalletId %s: balance %s, debit %s", walletID, bal.Balance, leg.DAmt))
+		}
+		mv := walletMovement{leg.TxnID, walletID, bal.Balance, leg.CAmt, leg.DAmt, closingBal, leg.FxRateID}
+
+		txnWalletKey, err := stub.CreateCompositeKey("TxnID~WalletID", []string{leg.TxnID, walletID})
+		if err != nil {
+			return nil, err
+		}
+		err = stub.PutState(txnWalletKey, []byte{0x00})
+		if err != nil {
+			return nil, err
+		}
+		err = putJournal(stub, mv, seq)
+		if err != nil {
+			return nil, err
+		}
+		bal.Balance = mv.ClosingBal
+		mvs = append(mvs, mv)
+	}
+
+	balBytes, _ := json.Marshal(bal)
+	err = history.Put(stub, walletID, balBytes)
+	if err != nil {
+		return nil, errors.New("Error in Wallet updation " + err.Error())
+	}
+	return mvs, nil
+}
+
+func getWalletsInfo(stub shim.ChaincodeStubInterface, walletID string) (walletsInfo, error) {
+	bal := walletsInfo{}
+	balBytes, err := stub.GetState(walletID)
+	if err != nil {
+		return bal, err
+	} else if balBytes == nil {
+		return bal, errcode.New(errcode.NotFound, "No data exists on this WalletId: "+walletID)
+	}
+	err = json.Unmarshal(balBytes, &bal)
+	if err != nil {
+		return bal, err
+	}
+	// Wallets created before wallets had a currency are all INR
+	if bal.Currency == "" {
+		bal.Currency = money.DefaultCurrency
+	}
+	bal.Balance.Currency = bal.Currency
+	return bal, nil
+}
+
+// checkTxnID refuses a txnID which has already moved money in any wallet
+func checkTxnID(stub shim.ChaincodeStubInterface, txnID string) error {
+	if txnID == "" {
+		return errcode.New(errcode.InvalidArgument, "TxnID cannot be empty")
+	}
+	txnIterator, err := stub.GetStateByPartialCompositeKey("TxnID~WalletID", []string{txnID})
+	if err != nil {
+		return err
+	}
+	defer txnIterator.Close()
+	if txnIterator.HasNext() {
+		return errcode.New(errcode.AlreadyExists, "TxnID "+txnID+" has already been applied")
+	}
+	return nil
+}
+
+// parseWalletAmount reads an amount in the wallet's currency. When the caller
+// says which currency the amount is in, it has to be the wallet's.
+func parseWalletAmount(stub shim.ChaincodeStubInterface, walletID string, amtStr string, currency []string) (money.Amount, error) {
+	bal, err := getWalletsInfo(stub, walletID)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	if len(currency) > 0 && currency[0] != "" && strings.ToUpper(currency[0]) != bal.Currency {
+		return money.Amount{}, errcode.New(errcode.InvalidArgument, "Cannot move "+strings.ToUpper(currency[0])+" in "+bal.Currency+" WalletId "+walletID)
+	}
+	amt, err := money.Parse(amtStr, bal.Currency)
+	if err != nil {
+		return amt, err
+	}
+	if amt.IsNegative() {
+		return amt, errcode.New(errcode.InvalidArgument, "Amount cannot be negative: "+amtStr)
+	}
+	return amt, nil
+}
+
+// balanceChanged is the wallet.balanceChanged event of a movement
+func balanceChanged(mv walletMovement) events.Event {
+	return events.New(events.WalletBalanceChanged, events.WalletBalance{WalletID: mv.WalletID, TxnID: mv.TxnID, OpeningBal: mv.OpeningBal, Credit: mv.CAmt, Debit: mv.DAmt, ClosingBal: mv.ClosingBal, FxRateID: mv.FxRateID})
+}
+
+// putJournal records a balance change against the wallet, stamped with the
+// transaction's timestamp so that every endorser writes the same key, and
+// numbered by seq among the changes the transaction makes to the wallet
+func putJournal(stub shim.ChaincodeStubInterface, mv walletMovement, seq int) error {
+	txnTime, err := clock.Now(stub)
+	if err != nil {
+		return err
+	}
+	entry := journalEntry{mv, txnTime}
+
+	journalKey, err := stub.CreateCompositeKey(journalIndex, []string{mv.WalletID, entry.TxnTimestamp.Format(journalTimeLayout), fmt.Sprintf(journalSeqLayout, seq), mv.TxnID})
+	if err != nil {
+		return err
+	}
+	entryBytes, _ := json.Marshal(entry)
+	return stub.PutState(journalKey, entryBytes)
+}
+
+func getWalletStatement(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+
+	/*
+	*args[0] -> WalletID
+	*args[1] -> From Date (dd/mm/yyyy)
+	*args[2] -> To Date (dd/mm/yyyy)
+	 */
+	if len(args) != 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Invalid number of arguments in getWalletStatement (required:3) given: "+xLenStr)
+	}
+	bal, err := getWalletsInfo(stub, args[0])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	fromDate, err := time.Parse("02/01/2006", args[1])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Error in parsing the from date "+err.Error())
+	}
+	toDate, err := time.Parse("02/01/2006", args[2])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Error in parsing the to date "+err.Error())
+	}
+	if toDate.Before(fromDate) {
+		return errcode.Error("walletcc", errcode.InvalidArgument, "To date "+args[2]+" is before from date "+args[1])
+	}
+	// The statement covers the whole of the to date
+	toDate = toDate.AddDate(0, 0, 1)
+
+	journalIterator, err := stub.GetStateByPartialCompositeKey(journalIndex, []string{args[0]})
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	defer journalIterator.Close()
+
+	statement := walletStatement{args[0], args[1], args[2], money.Zero(bal.Balance.Currency), []journalEntry{}, money.Zero(bal.Balance.Currency)}
+	found := false
+	for journalIterator.HasNext() {
+		kv, err := journalIterator.Next()
+		if err != nil {
+			return errcode.Error("walletcc", errcode.Internal, err.Error())
+		}
+		entry := journalEntry{}
+		err = json.Unmarshal(kv.Value, &entry)
+		if err != nil {
+			return errcode.Error("walletcc", errcode.Internal, err.Error())
+		}
+
+		if entry.TxnTimestamp.Before(fromDate) {
+			statement.OpeningBal = entry.ClosingBal
+			found = true
+			continue
+		}
+		if !found {
+			statement.OpeningBal = entry.OpeningBal
+			found = true
+		}
+		if !entry.TxnTimestamp.Before(toDate) {
+			break
+		}
+		statement.Entries = append(statement.Entries, entry)
+	}
+
+	// Wallets created before the journal existed have no entries at all
+	if !found {
+		statement.OpeningBal = bal.Balance
+	}
+	statement.ClosingBal = statement.OpeningBal
+	if len(statement.Entries) > 0 {
+		statement.ClosingBal = statement.Entries[len(statement.Entries)-1].ClosingBal
+	}
+
+	statementBytes, _ := json.Marshal(statement)
+	return shim.Success(statementBytes)
+}
+
+func getWalletHistory(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	/*
+	 *args[0] -> WalletID
+	 *args[1] -> page size, 20 when not given (at most 100)
+	 *args[2] -> bookmark of the previous page, for the next one
+	 */
+	if len(args) < 1 || len(args) > 3 {
+		xLenStr := strconv.Itoa(len(args))
+		return errcode.Error("walletcc", errcode.InvalidArgument, "Invalid number of arguments in getWalletHistory (required:1 to 3) given: "+xLenStr)
+	}
+	page, err := history.Get(stub, args[0], args[1:])
+	if err != nil {
+		return errcode.Error("walletcc", errcode.Internal, err.Error())
+	}
+	pageBytes, _ := json.Marshal(page)
+	return shim.Success(pageBytes)
+}